@@ -17,7 +17,13 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/projectriff/riff-cli/pkg/kubectl"
@@ -26,28 +32,57 @@ import (
 	"github.com/projectriff/riff-cli/pkg/options"
 	"github.com/projectriff/riff-cli/cmd/utils"
 	"github.com/projectriff/riff-cli/cmd/opts"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/projectriff/riff-cli/pkg/initializers/core"
+	"gopkg.in/yaml.v2"
 )
 
 // applyCmd represents the apply command
 var applyCmd = &cobra.Command{
 	Use:   "apply",
 	Short: "Apply function resource definitions",
-	Long: `Apply the resource definition[s] included in the path. A resource will be created if it doesn't exist yet.`,
+	Long: `Apply the resource definition[s] included in the path. A resource will be created if it doesn't exist yet.
+Topics are applied before the functions that reference them.`,
   Example: `
 riff apply -f some/function/path
 riff apply -f some/function/path/some.yaml
+riff apply --diff -f some/function/path
+riff apply --replace-topics -f some/function/path
+riff apply --wait -f some/function/path
+riff apply --server-dry-run -f some/function/path
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 
 		if opts.CreateOptions.DryRun {
 			fmt.Printf("\nApply Command: kubectl apply -f %s\n\n", opts.CreateOptions.FunctionPath)
+		} else if opts.CreateOptions.Diff {
+			err := diff(opts.CreateOptions.FunctionPath)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+		} else if opts.CreateOptions.ServerDryRun {
+			err := serverDryRun(opts.CreateOptions.FunctionPath)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
 		} else {
-			output, err := kubectl.ExecForString([]string{"apply", "-f", opts.CreateOptions.FunctionPath})
+			files, cleanup, err := resourcesToApply(opts.CreateOptions.FunctionPath, opts.CreateOptions.ReplaceTopics)
+			defer cleanup()
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			if len(files.all()) == 0 {
+				fmt.Printf("nothing to apply in %s\n", opts.CreateOptions.FunctionPath)
+				return nil
+			}
+			err = applyResources(files, opts.CreateOptions.Wait)
 			if err != nil {
 				cmd.SilenceUsage = true
 				return err
 			}
-			fmt.Println(output)
 		}
 		return nil
 	},
@@ -55,6 +90,12 @@ riff apply -f some/function/path/some.yaml
 
 		if !opts.CreateOptions.Initialized {
 			utils.MergeApplyOptions(*cmd.Flags(), &opts.CreateOptions)
+
+			if err := validateFlagCombinations(opts.CreateOptions); err != nil {
+				ioutils.Error(err)
+				os.Exit(1)
+			}
+
 			if len(args) > 0 {
 				if len(args) == 1 && opts.CreateOptions.FunctionPath == "" {
 					opts.InitOptions.FunctionPath = args[0]
@@ -75,6 +116,312 @@ riff apply -f some/function/path/some.yaml
 	},
 }
 
+// Runs kubectl streaming its output, replaced in tests by a fake kubectl.
+var kubectlStream = kubectl.Stream
+
+// Runs kubectl for its output, replaced in tests by a fake kubectl.
+var kubectlExec = kubectl.ExecForString
+
+// How long --wait waits for the topics to be ready before giving up.
+const topicReadyTimeout = "2m"
+
+type topicResource struct {
+	Kind     string
+	Metadata struct {
+		Name string
+	}
+}
+
+/*
+ * The files to apply, those declaring topics being applied before the functions that reference them. The
+ * replaced topics exist already and are deleted right before applying, so that applying creates them again.
+ */
+type resourceFiles struct {
+	topics     []string
+	topicNames []string
+	replaced   []string
+	others     []string
+}
+
+func (files resourceFiles) all() []string {
+	return append(append([]string{}, files.topics...), files.others...)
+}
+
+// A kustomization generated with --kustomize lists the resources for kustomize, it is not a resource itself.
+func isKustomization(file string) bool {
+	return filepath.Base(file) == core.KustomizationFile
+}
+
+// The resource files of a function directory, in a stable order, or the function path itself when it is a file.
+func resourceFilesIn(functionPath string) ([]string, error) {
+	if !osutils.IsDirectory(functionPath) {
+		return []string{functionPath}, nil
+	}
+	var files []string
+	for _, pattern := range []string{"*.json", "*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(functionPath, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if !isKustomization(match) {
+				files = append(files, match)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// The -f arguments passing the resource files of the function to kubectl.
+func resourceFileArgs(functionPath string) ([]string, error) {
+	files, err := resourceFilesIn(functionPath)
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	for _, file := range files {
+		args = append(args, "-f", file)
+	}
+	return args, nil
+}
+
+/*
+ * The files to apply for the function resources, topics first. A topic that already exists is left as it
+ * is, as its partitions cannot change, so the files declaring existing topics, or declaring both topics and
+ * other resources, are replaced by temporary copies, removed by the returned cleanup. With replaceTopics,
+ * existing topics are instead listed to be replaced, nothing being deleted yet.
+ */
+func resourcesToApply(functionPath string, replaceTopics bool) (resourceFiles, func(), error) {
+	var temps []string
+	cleanup := func() {
+		for _, temp := range temps {
+			os.Remove(temp)
+		}
+	}
+	writeTemp := func(documents []string) (string, error) {
+		temp, err := ioutil.TempFile("", "riff-apply")
+		if err != nil {
+			return "", err
+		}
+		temps = append(temps, temp.Name())
+		_, err = temp.WriteString(strings.Join(documents, "\n---\n"))
+		temp.Close()
+		return temp.Name(), err
+	}
+
+	var toApply resourceFiles
+	files, err := resourceFilesIn(functionPath)
+	if err != nil {
+		return toApply, cleanup, err
+	}
+
+	for _, file := range files {
+		if filepath.Ext(file) == ".json" {
+			toApply.others = append(toApply.others, file)
+			continue
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return toApply, cleanup, err
+		}
+		var topics, others []string
+		skipped := false
+		for _, document := range splitYamlDocuments(string(content)) {
+			if strings.TrimSpace(document) == "" {
+				continue
+			}
+			var resource topicResource
+			if yaml.Unmarshal([]byte(document), &resource) != nil || resource.Kind != "Topic" {
+				others = append(others, document)
+				continue
+			}
+			exists, err := topicExists(resource.Metadata.Name)
+			if err != nil {
+				return toApply, cleanup, err
+			}
+			if exists && !replaceTopics {
+				fmt.Printf("topic %s already exists, leaving it as it is - set --replace-topics to recreate it.\n", resource.Metadata.Name)
+				skipped = true
+				continue
+			}
+			if exists {
+				toApply.replaced = append(toApply.replaced, resource.Metadata.Name)
+			}
+			topics = append(topics, document)
+			toApply.topicNames = append(toApply.topicNames, resource.Metadata.Name)
+		}
+
+		if len(topics) > 0 {
+			if skipped || len(others) > 0 {
+				temp, err := writeTemp(topics)
+				if err != nil {
+					return toApply, cleanup, err
+				}
+				toApply.topics = append(toApply.topics, temp)
+			} else {
+				toApply.topics = append(toApply.topics, file)
+			}
+		}
+		if len(others) > 0 {
+			if skipped || len(topics) > 0 {
+				temp, err := writeTemp(others)
+				if err != nil {
+					return toApply, cleanup, err
+				}
+				toApply.others = append(toApply.others, temp)
+			} else {
+				toApply.others = append(toApply.others, file)
+			}
+		}
+	}
+	return toApply, cleanup, nil
+}
+
+// Applies the files in order with a single kubectl command.
+func applyFiles(files []string) error {
+	args := []string{"apply"}
+	for _, file := range files {
+		args = append(args, "-f", file)
+	}
+	output, err := withRetries(func() (string, error) {
+		return kubectlExec(args)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(output)
+	return nil
+}
+
+/*
+ * Deletes the replaced topics, then applies the topics and the other resources. When the apply fails, the
+ * error names the topics deleted, which may not have been created again.
+ */
+func applyResources(files resourceFiles, wait bool) error {
+	var deleted []string
+	var err error
+	for _, name := range files.replaced {
+		_, err = withRetries(func() (string, error) {
+			return kubectlExec([]string{"delete", "topics.projectriff.io", name})
+		})
+		if err != nil {
+			break
+		}
+		deleted = append(deleted, name)
+	}
+	if err == nil {
+		err = applyInOrder(files, wait)
+	}
+	if err != nil && len(deleted) > 0 {
+		return errors.New(fmt.Sprintf("%v; topics %s were deleted to be replaced, apply again to create them", err, strings.Join(deleted, ", ")))
+	}
+	return err
+}
+
+/*
+ * Applies the topics, then the other resources. With wait, the other resources are only applied once
+ * kubectl reports every topic ready, so that functions never start without their topics.
+ */
+func applyInOrder(files resourceFiles, wait bool) error {
+	if !wait || len(files.topics) == 0 {
+		return applyFiles(files.all())
+	}
+	err := applyFiles(files.topics)
+	if err != nil {
+		return err
+	}
+	err = waitForTopics(files.topicNames)
+	if err != nil {
+		return err
+	}
+	if len(files.others) == 0 {
+		return nil
+	}
+	return applyFiles(files.others)
+}
+
+func waitForTopics(names []string) error {
+	args := []string{"wait", "--for=condition=Ready", "--timeout=" + topicReadyTimeout}
+	for _, name := range names {
+		args = append(args, "topics.projectriff.io/"+name)
+	}
+	exitCode, err := kubectlStream(args, os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return errors.New(fmt.Sprintf("topics %s did not become ready within %s", strings.Join(names, ", "), topicReadyTimeout))
+	}
+	return nil
+}
+
+func topicExists(name string) (bool, error) {
+	output, err := withRetries(func() (string, error) {
+		return kubectlExec([]string{"get", "topics.projectriff.io", name, "--ignore-not-found", "-o", "name"})
+	})
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(output) != "", nil
+}
+
+/*
+ * Streams the differences between the function resources and the cluster. As with kubectl diff, an error
+ * is returned, and the command exits non-zero, when there are differences.
+ */
+func diff(functionPath string) error {
+	files, err := resourceFileArgs(functionPath)
+	if err != nil {
+		return err
+	}
+	exitCode, err := kubectlStream(append([]string{"diff"}, files...), os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
+	switch exitCode {
+	case 0:
+		return nil
+	case 1:
+		return errors.New(fmt.Sprintf("function resources in %s differ from the cluster", functionPath))
+	default:
+		return errors.New(fmt.Sprintf("kubectl diff failed with exit code %d", exitCode))
+	}
+}
+
+// The hint kubectl appends to client-side validation errors, which do not apply to a server dry run.
+const kubectlValidateHint = "; if you choose to ignore these errors, turn validation off with --validate=false"
+
+/*
+ * Validates the function resources against the schemas installed in the cluster, such as those of the riff
+ * CRDs, with a server-side dry run of kubectl apply that persists nothing. Each validation error reported by
+ * kubectl is listed in the returned error.
+ */
+func serverDryRun(functionPath string) error {
+	files, err := resourceFileArgs(functionPath)
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	exitCode, err := kubectlStream(append([]string{"apply", "--dry-run=server"}, files...), os.Stdout, &stderr)
+	if err != nil {
+		return err
+	}
+	if exitCode == 0 {
+		return nil
+	}
+	var errs []string
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		if line = strings.TrimSpace(strings.TrimSuffix(line, kubectlValidateHint)); line != "" {
+			errs = append(errs, "  "+line)
+		}
+	}
+	if len(errs) == 0 {
+		return errors.New(fmt.Sprintf("kubectl apply --dry-run=server failed with exit code %d", exitCode))
+	}
+	return errors.New(fmt.Sprintf("function resources in %s are not valid for the cluster:\n%s", functionPath, strings.Join(errs, "\n")))
+}
+
 func init() {
 	rootCmd.AddCommand(applyCmd)
 	utils.CreateApplyFlags(applyCmd.Flags())
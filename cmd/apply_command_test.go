@@ -0,0 +1,290 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/kubectl"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func fakeKubectl(exitCode int, output string, args *[]string) func([]string, io.Writer, io.Writer) (int, error) {
+	return func(cmdArgs []string, stdout io.Writer, stderr io.Writer) (int, error) {
+		*args = cmdArgs
+		io.WriteString(stdout, output)
+		return exitCode, nil
+	}
+}
+
+func TestDiffWithoutDifferences(t *testing.T) {
+	as := assert.New(t)
+	defer func() { kubectlStream = kubectl.Stream }()
+
+	var args []string
+	kubectlStream = fakeKubectl(0, "", &args)
+	as.NoError(diff("some/function/path"))
+	as.Equal([]string{"diff", "-f", "some/function/path"}, args)
+}
+
+func TestDiffWithDifferences(t *testing.T) {
+	as := assert.New(t)
+	defer func() { kubectlStream = kubectl.Stream }()
+
+	var args []string
+	kubectlStream = fakeKubectl(1, "-  image: me/square:0.0.1\n+  image: me/square:0.0.2\n", &args)
+	err := diff("some/function/path")
+	as.EqualError(err, "function resources in some/function/path differ from the cluster")
+}
+
+func TestDiffFailure(t *testing.T) {
+	as := assert.New(t)
+	defer func() { kubectlStream = kubectl.Stream }()
+
+	var args []string
+	kubectlStream = fakeKubectl(2, "", &args)
+	as.EqualError(diff("some/function/path"), "kubectl diff failed with exit code 2")
+}
+
+func TestServerDryRun(t *testing.T) {
+	as := assert.New(t)
+	defer func() { kubectlStream = kubectl.Stream }()
+
+	var args []string
+	kubectlStream = fakeKubectl(0, "function.projectriff.io/square configured (server dry run)\n", &args)
+	as.NoError(serverDryRun("some/function/path"))
+	as.Equal([]string{"apply", "--dry-run=server", "-f", "some/function/path"}, args)
+}
+
+func TestServerDryRunValidationError(t *testing.T) {
+	as := assert.New(t)
+	defer func() { kubectlStream = kubectl.Stream }()
+
+	kubectlStream = func(cmdArgs []string, stdout io.Writer, stderr io.Writer) (int, error) {
+		io.WriteString(stderr, `The Function "square" is invalid: spec.protocol: Unsupported value: "smtp": supported values: "grpc", "http"
+error: error validating "some/function/path/square-topics.yaml": error validating data: ValidationError(Topic.spec): unknown field "partition" in io.projectriff.v1.Topic.spec; if you choose to ignore these errors, turn validation off with --validate=false
+`)
+		return 1, nil
+	}
+	as.EqualError(serverDryRun("some/function/path"), `function resources in some/function/path are not valid for the cluster:
+  The Function "square" is invalid: spec.protocol: Unsupported value: "smtp": supported values: "grpc", "http"
+  error: error validating "some/function/path/square-topics.yaml": error validating data: ValidationError(Topic.spec): unknown field "partition" in io.projectriff.v1.Topic.spec`)
+
+	kubectlStream = fakeKubectl(1, "", new([]string))
+	as.EqualError(serverDryRun("some/function/path"), "kubectl apply --dry-run=server failed with exit code 1")
+}
+
+const applyTestResources = `apiVersion: projectriff.io/v1
+kind: Topic
+metadata:
+  name: numbers
+spec:
+  partitions: 3
+---
+apiVersion: projectriff.io/v1
+kind: Topic
+metadata:
+  name: squares
+spec:
+  partitions: 1
+`
+
+// A kubectl knowing the topics given as existing, recording the commands it runs.
+func fakeKubectlWithTopics(existing []string, commands *[]string) func([]string) (string, error) {
+	return func(cmdArgs []string) (string, error) {
+		*commands = append(*commands, strings.Join(cmdArgs, " "))
+		if cmdArgs[0] == "get" {
+			for _, topic := range existing {
+				if cmdArgs[2] == topic {
+					return "topic.projectriff.io/" + topic + "\n", nil
+				}
+			}
+		}
+		return "", nil
+	}
+}
+
+func TestApplyLeavesExistingTopics(t *testing.T) {
+	as := assert.New(t)
+	defer func() { kubectlExec = kubectl.ExecForString }()
+
+	dir, err := ioutil.TempDir("", "riff-apply")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square-topics.yaml"), []byte(applyTestResources), 0644))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square-function.yaml"), []byte("kind: Function\n"), 0644))
+
+	var commands []string
+	kubectlExec = fakeKubectlWithTopics([]string{"numbers"}, &commands)
+	files, cleanup, err := resourcesToApply(dir, false)
+	defer cleanup()
+	as.NoError(err)
+	as.Equal([]string{
+		"get topics.projectriff.io numbers --ignore-not-found -o name",
+		"get topics.projectriff.io squares --ignore-not-found -o name",
+	}, commands)
+
+	as.Len(files.all(), 2)
+	as.Equal([]string{filepath.Join(dir, "square-function.yaml")}, files.others)
+	as.Equal([]string{"squares"}, files.topicNames)
+	topics, err := ioutil.ReadFile(files.all()[0])
+	as.NoError(err)
+	as.NotContains(string(topics), "numbers")
+	as.Contains(string(topics), "name: squares")
+}
+
+func TestApplyReplacesExistingTopics(t *testing.T) {
+	as := assert.New(t)
+	defer func() { kubectlExec = kubectl.ExecForString }()
+
+	dir, err := ioutil.TempDir("", "riff-apply")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "square-topics.yaml")
+	as.NoError(ioutil.WriteFile(file, []byte(applyTestResources), 0644))
+
+	var commands []string
+	kubectlExec = fakeKubectlWithTopics([]string{"numbers"}, &commands)
+	files, cleanup, err := resourcesToApply(file, true)
+	defer cleanup()
+	as.NoError(err)
+	as.NotContains(commands, "delete topics.projectriff.io numbers", "nothing is deleted before applying")
+	as.Equal([]string{"numbers"}, files.replaced)
+	as.Equal([]string{file}, files.all())
+
+	commands = nil
+	as.NoError(applyResources(files, false))
+	as.Equal([]string{
+		"delete topics.projectriff.io numbers",
+		"apply -f " + file,
+	}, commands)
+}
+
+func TestFailedApplyReportsReplacedTopics(t *testing.T) {
+	as := assert.New(t)
+	defer func() { kubectlExec = kubectl.ExecForString }()
+
+	kubectlExec = func(cmdArgs []string) (string, error) {
+		if cmdArgs[0] == "apply" {
+			return "", errors.New("admission webhook denied the request")
+		}
+		return "", nil
+	}
+	files := resourceFiles{topics: []string{"topics.yaml"}, topicNames: []string{"numbers", "squares"}, replaced: []string{"numbers", "squares"}}
+	as.EqualError(applyResources(files, false), "admission webhook denied the request; topics numbers, squares were deleted to be replaced, apply again to create them")
+}
+
+const kustomizationTestResource = `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- square-function.yaml
+`
+
+func TestApplySkipsKustomization(t *testing.T) {
+	as := assert.New(t)
+	defer func() {
+		kubectlExec = kubectl.ExecForString
+		kubectlStream = kubectl.Stream
+	}()
+
+	dir, err := ioutil.TempDir("", "riff-apply")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(kustomizationTestResource), 0644))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square-function.yaml"), []byte("kind: Function\n"), 0644))
+
+	var commands []string
+	kubectlExec = fakeKubectlWithTopics(nil, &commands)
+	files, cleanup, err := resourcesToApply(dir, false)
+	defer cleanup()
+	as.NoError(err)
+	as.Equal([]string{filepath.Join(dir, "square-function.yaml")}, files.all())
+
+	var args []string
+	kubectlStream = fakeKubectl(0, "", &args)
+	as.NoError(diff(dir))
+	as.Equal([]string{"diff", "-f", filepath.Join(dir, "square-function.yaml")}, args)
+	as.NoError(serverDryRun(dir))
+	as.Equal([]string{"apply", "--dry-run=server", "-f", filepath.Join(dir, "square-function.yaml")}, args)
+}
+
+func TestApplyOrdersTopicsBeforeFunctions(t *testing.T) {
+	as := assert.New(t)
+	defer func() { kubectlExec = kubectl.ExecForString }()
+
+	dir, err := ioutil.TempDir("", "riff-apply")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	// Sorted by name, the function and mixed files come before the topics.
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "a-mixed.yaml"), []byte("kind: Function\n---\nkind: Topic\nmetadata:\n  name: cubes\n"), 0644))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square-function.yaml"), []byte("kind: Function\n"), 0644))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square-topics.yaml"), []byte(applyTestResources), 0644))
+
+	var commands []string
+	kubectlExec = fakeKubectlWithTopics(nil, &commands)
+	files, cleanup, err := resourcesToApply(dir, false)
+	defer cleanup()
+	as.NoError(err)
+	as.Equal([]string{"cubes", "numbers", "squares"}, files.topicNames)
+
+	var kinds []string
+	for _, file := range files.all() {
+		content, err := ioutil.ReadFile(file)
+		as.NoError(err)
+		for _, document := range splitYamlDocuments(string(content)) {
+			var resource topicResource
+			as.NoError(yaml.Unmarshal([]byte(document), &resource))
+			kinds = append(kinds, resource.Kind)
+		}
+	}
+	as.Equal([]string{"Topic", "Topic", "Topic", "Function", "Function"}, kinds)
+	as.Equal(filepath.Join(dir, "square-topics.yaml"), files.topics[1])
+	as.Equal(filepath.Join(dir, "square-function.yaml"), files.others[1])
+}
+
+func TestApplyWaitsForTopics(t *testing.T) {
+	as := assert.New(t)
+	defer func() {
+		kubectlExec = kubectl.ExecForString
+		kubectlStream = kubectl.Stream
+	}()
+
+	var commands []string
+	kubectlExec = fakeKubectlWithTopics(nil, &commands)
+	kubectlStream = func(cmdArgs []string, stdout io.Writer, stderr io.Writer) (int, error) {
+		commands = append(commands, strings.Join(cmdArgs, " "))
+		return 0, nil
+	}
+	files := resourceFiles{topics: []string{"topics.yaml"}, topicNames: []string{"numbers"}, others: []string{"function.yaml"}}
+	as.NoError(applyResources(files, true))
+	as.Equal([]string{
+		"apply -f topics.yaml",
+		"wait --for=condition=Ready --timeout=2m topics.projectriff.io/numbers",
+		"apply -f function.yaml",
+	}, commands)
+
+	commands = nil
+	as.NoError(applyResources(files, false))
+	as.Equal([]string{"apply -f topics.yaml -f function.yaml"}, commands)
+}
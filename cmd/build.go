@@ -17,34 +17,64 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"sync"
 
 	"github.com/spf13/cobra"
 	"github.com/projectriff/riff-cli/pkg/options"
 	"strings"
-	"github.com/projectriff/riff-cli/pkg/docker"
+	"github.com/projectriff/riff-cli/pkg/functions"
 	"github.com/projectriff/riff-cli/pkg/ioutils"
 	"os"
 	"github.com/projectriff/riff-cli/pkg/osutils"
 	"path/filepath"
+	"regexp"
 	"github.com/projectriff/riff-cli/cmd/utils"
 	"github.com/projectriff/riff-cli/cmd/opts"
 )
 
+type BuildAllOptions struct {
+	all      bool
+	root     string
+	parallel int
+}
+
+var buildAllOptions BuildAllOptions
+
+// The characters an argument can have without being quoted in a shell command.
+var plainShellWordRegexp = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Build a function container",
 	Long: `Build the function based on the code available in the path directory, using the name
   and version specified for the image that is built.`,
-	Example: `riff build -n <name> -v <version> -f <path> [--push]`,
+	Example: `riff build -n <name> -v <version> -f <path> [--push]
+riff build --all --root ./functions --parallel 4 [--push]`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return build(options.GetBuildOptions(opts.CreateOptions))
+		if buildAllOptions.all {
+			cmd.SilenceUsage = true
+			return buildAll(buildAllOptions.root, buildAllOptions.parallel, opts.CreateOptions, os.Stdout)
+		}
+		return build(options.GetBuildOptions(opts.CreateOptions), os.Stdout)
 	},
 	//TODO: DRY
 	PreRun: func(cmd *cobra.Command, args []string) {
 		if !opts.CreateOptions.Initialized {
+			if err := applyConfig(cmd.Flags()); err != nil {
+				ioutils.Error(err)
+				os.Exit(1)
+			}
 			utils.MergeBuildOptions(*cmd.Flags(), &opts.CreateOptions)
 
+			if err := validateFlagCombinations(opts.CreateOptions); err != nil {
+				ioutils.Error(err)
+				os.Exit(1)
+			}
+
 			if len(args) > 0 {
 				if len(args) == 1 && opts.CreateOptions.FunctionPath == "" {
 					opts.CreateOptions.FunctionPath = args[0]
@@ -65,45 +95,179 @@ var buildCmd = &cobra.Command{
 	},
 }
 
-func build(opts options.BuildOptions) error {
+// Builds the image of the function, and pushes it with --push, writing the docker output to out.
+func build(opts options.BuildOptions, out io.Writer) error {
+	if opts.PreviewBuild {
+		return previewBuild(opts, out)
+	}
+	if opts.Platform != "" {
+		return buildx(opts, out)
+	}
 	buildArgs := buildArgs(opts)
 	pushArgs := pushArgs(opts)
 	if opts.DryRun {
-		fmt.Printf("\nBuild command: docker %s\n", strings.Join(buildArgs, " "))
+		fmt.Fprintf(out, "\nBuild command: docker %s\n", strings.Join(buildArgs, " "))
 		if (opts.Push) {
-			fmt.Printf("\nPush command: docker %s\n", strings.Join(pushArgs, " "))
+			fmt.Fprintf(out, "\nPush command: docker %s\n", strings.Join(pushArgs, " "))
 		}
 		return nil
 	}
 
-	fmt.Println("building image...")
-	out, err := docker.Exec(buildArgs)
+	fmt.Fprintln(out, "building image...")
+	output, err := withRetries(func() (string, error) { return dockerExec(buildArgs) })
 	if err != nil {
 		ioutils.Errorf("Error %v\n", err)
 		return err
 	}
-	fmt.Println(out)
+	fmt.Fprintln(out, output)
 
 	if opts.Push {
-		fmt.Println("pushing image...")
-		out, err = docker.Exec(pushArgs)
+		fmt.Fprintln(out, "pushing image...")
+		output, err = withRetries(func() (string, error) { return dockerExec(pushArgs) })
 		if err != nil {
 			ioutils.Errorf("Error %v\n", err)
 			return err
 		}
-		fmt.Println(out)
+		fmt.Fprintln(out, output)
 	}
 
 	return nil
 }
 
+/*
+ * Builds every function directory found under root, at most parallel at a time, each with the name of its
+ * resource definition and the other options, validated for its directory so that a content hash tag is that
+ * of the function. The output of each build is written at once when it is done, so that concurrent builds
+ * do not interleave. All functions are built even when some fail; a summary is written at the end and an
+ * error returned if any failed.
+ */
+func buildAll(root string, parallel int, createOptions options.CreateOptions, out io.Writer) error {
+	found, err := functions.FindFunctionDirs(root)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return errors.New(fmt.Sprintf("no function found under %s, run 'riff init' to initialize one", root))
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	errs := make([]error, len(found))
+	slots := make(chan bool, parallel)
+	var wg sync.WaitGroup
+	var outLock sync.Mutex
+	for i, function := range found {
+		functionOptions := createOptions
+		functionOptions.FunctionPath = function.Dir
+		functionOptions.FunctionName = function.Name
+		wg.Add(1)
+		slots <- true
+		go func(i int, function functions.FunctionDir) {
+			defer wg.Done()
+			var output bytes.Buffer
+			errs[i] = options.ValidateAndCleanInitOptions(&functionOptions.InitOptions)
+			if errs[i] == nil {
+				errs[i] = build(options.GetBuildOptions(functionOptions), &output)
+			}
+			outLock.Lock()
+			fmt.Fprintf(out, "\n==> %s (%s)\n%s", function.Name, function.Dir, output.String())
+			outLock.Unlock()
+			<-slots
+		}(i, function)
+	}
+	wg.Wait()
+
+	failed := 0
+	fmt.Fprintf(out, "\nBuild summary:\n")
+	for i, function := range found {
+		if errs[i] != nil {
+			failed++
+			fmt.Fprintf(out, "  FAILED  %s (%s): %v\n", function.Name, function.Dir, errs[i])
+		} else {
+			fmt.Fprintf(out, "  OK      %s (%s)\n", function.Name, function.Dir)
+		}
+	}
+	if failed > 0 {
+		return errors.New(fmt.Sprintf("%d of %d functions failed to build", failed, len(found)))
+	}
+	return nil
+}
+
+/*
+ * Builds the image for the platforms with docker buildx. An image for several platforms cannot be loaded
+ * into the local docker, so it must be pushed, by buildx itself.
+ */
+func buildx(opts options.BuildOptions, out io.Writer) error {
+	if !opts.Push && strings.Contains(opts.Platform, ",") {
+		return errors.New(fmt.Sprintf("an image for several platforms cannot be loaded locally, set --push to build for %s", opts.Platform))
+	}
+	args := buildxArgs(opts)
+	if opts.DryRun {
+		fmt.Fprintf(out, "\nBuild command: docker %s\n", strings.Join(args, " "))
+		return nil
+	}
+
+	if _, err := dockerExec([]string{"buildx", "version"}); err != nil {
+		return errors.New("--platform needs docker buildx, which is not available: install the buildx plugin or use a docker release that bundles it")
+	}
+	fmt.Fprintf(out, "building image for %s...\n", opts.Platform)
+	output, err := withRetries(func() (string, error) { return dockerExec(args) })
+	if err != nil {
+		ioutils.Errorf("Error %v\n", err)
+		return err
+	}
+	fmt.Fprintln(out, output)
+	return nil
+}
+
+// Prints the docker command building the image, with buildx for --platform, without running it.
+func previewBuild(opts options.BuildOptions, out io.Writer) error {
+	args := buildArgs(opts)
+	if opts.Platform != "" {
+		args = buildxArgs(opts)
+	}
+	words := []string{"docker"}
+	for _, arg := range args {
+		words = append(words, shellWord(arg))
+	}
+	_, err := fmt.Fprintln(out, strings.Join(words, " "))
+	return err
+}
+
+// The argument quoted for a POSIX shell, unless it has no character the shell would interpret.
+func shellWord(arg string) string {
+	if plainShellWordRegexp.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.Replace(arg, "'", `'"'"'`, -1) + "'"
+}
+
+func buildxArgs(opts options.BuildOptions) []string {
+	output := "--load"
+	if opts.Push {
+		output = "--push"
+	}
+	return append([]string{"buildx", "build", "--platform", opts.Platform, output}, buildArgs(opts)[1:]...)
+}
+
 func buildArgs(opts options.BuildOptions) []string {
 	image := options.ImageName(opts)
 	path := opts.FunctionPath
 	if !osutils.IsDirectory(opts.FunctionPath) {
 		path = filepath.Dir(path)
 	}
-	return []string{"build", "-t", image, path}
+	args := []string{"build"}
+	if opts.NoCache {
+		args = append(args, "--no-cache")
+	}
+	if opts.CacheBust != "" {
+		args = append(args, "--build-arg", "CACHE_BUST="+opts.CacheBust)
+	}
+	if opts.Context != "" {
+		return append(args, "-t", image, "-f", filepath.Join(path, "Dockerfile"), opts.Context)
+	}
+	return append(args, "-t", image, path)
 }
 
 func pushArgs(opts options.BuildOptions) []string {
@@ -114,4 +278,8 @@ func pushArgs(opts options.BuildOptions) []string {
 func init() {
 	rootCmd.AddCommand(buildCmd)
 	utils.CreateBuildFlags(buildCmd.Flags())
+
+	buildCmd.Flags().BoolVarP(&buildAllOptions.all, "all", "", false, "build every function found under --root, named after their resource definitions")
+	buildCmd.Flags().StringVarP(&buildAllOptions.root, "root", "", ".", "the directory to find functions under with --all")
+	buildCmd.Flags().IntVarP(&buildAllOptions.parallel, "parallel", "", 1, "how many functions to build at a time with --all")
 }
@@ -17,10 +17,20 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"github.com/stretchr/testify/assert"
 	"github.com/projectriff/riff-cli/pkg/osutils"
 	"github.com/projectriff/riff-cli/cmd/opts"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/docker"
+	"github.com/projectriff/riff-cli/pkg/functions"
 )
 
 func TestBuildCommandImplicitPath(t *testing.T) {
@@ -59,3 +69,183 @@ func TestBuildCommandWithUserAccountAndVersion(t *testing.T) {
 	as.True(opts.CreateOptions.Push)
 }
 
+func TestBuildArgsWithNoCache(t *testing.T) {
+	as := assert.New(t)
+	buildOptions := options.BuildOptions{
+		FunctionPath: osutils.Path("../test_data/shell/echo"),
+		FunctionName: "echo",
+		Version:      "0.0.1",
+		UserAccount:  "me",
+	}
+
+	as.NotContains(buildArgs(buildOptions), "--no-cache")
+
+	buildOptions.NoCache = true
+	args := buildArgs(buildOptions)
+	as.Equal([]string{"build", "--no-cache", "-t", "me/echo:0.0.1", buildOptions.FunctionPath}, args)
+}
+
+func TestBuildArgsWithCacheBust(t *testing.T) {
+	as := assert.New(t)
+	buildOptions := options.BuildOptions{
+		FunctionPath: osutils.Path("../test_data/shell/echo"),
+		FunctionName: "echo",
+		Version:      "0.0.1",
+		UserAccount:  "me",
+	}
+
+	as.NotContains(buildArgs(buildOptions), "--build-arg")
+
+	buildOptions.CacheBust = "2018-06-01"
+	args := buildArgs(buildOptions)
+	as.Equal([]string{"build", "--build-arg", "CACHE_BUST=2018-06-01", "-t", "me/echo:0.0.1", buildOptions.FunctionPath}, args)
+}
+
+func TestBuildArgsWithContext(t *testing.T) {
+	as := assert.New(t)
+	buildOptions := options.BuildOptions{
+		FunctionPath: osutils.Path("../test_data/shell/echo"),
+		FunctionName: "echo",
+		Version:      "0.0.1",
+		UserAccount:  "me",
+		Context:      osutils.Path("../test_data"),
+	}
+
+	args := buildArgs(buildOptions)
+	as.Equal([]string{"build", "-t", "me/echo:0.0.1", "-f", filepath.Join(buildOptions.FunctionPath, "Dockerfile"), buildOptions.Context}, args)
+}
+
+func TestBuildAll(t *testing.T) {
+	as := assert.New(t)
+	root, err := ioutil.TempDir("", "riff-build-all")
+	as.NoError(err)
+	defer os.RemoveAll(root)
+	for _, name := range []string{"square", "cube", "broken"} {
+		as.NoError(os.Mkdir(filepath.Join(root, name), 0755))
+		as.NoError(ioutil.WriteFile(filepath.Join(root, name, "Dockerfile"), []byte{}, 0644))
+		as.NoError(ioutil.WriteFile(filepath.Join(root, name, name+"-function.yaml"), []byte{}, 0644))
+	}
+	as.NoError(os.Mkdir(filepath.Join(root, "docs"), 0755))
+
+	var lock sync.Mutex
+	var built []string
+	dockerExec = func(args []string) (string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		built = append(built, args[2])
+		if args[2] == "me/broken:0.0.1" {
+			return "", errors.New("build failed")
+		}
+		return "", nil
+	}
+	defer func() { dockerExec = docker.Exec }()
+
+	var out bytes.Buffer
+	err = buildAll(root, 2, options.CreateOptions{InitOptions: options.InitOptions{UserAccount: "me", Version: "0.0.1"}}, &out)
+	as.EqualError(err, "1 of 3 functions failed to build")
+	as.Len(built, 3)
+	as.Contains(built, "me/square:0.0.1")
+	as.Contains(built, "me/cube:0.0.1")
+	as.Contains(out.String(), fmt.Sprintf("  FAILED  broken (%s): build failed\n", filepath.Join(root, "broken")))
+	as.Contains(out.String(), fmt.Sprintf("  OK      square (%s)\n", filepath.Join(root, "square")))
+}
+
+func TestBuildAllTagsEachFunctionWithItsContentHash(t *testing.T) {
+	as := assert.New(t)
+	root, err := ioutil.TempDir("", "riff-build-all")
+	as.NoError(err)
+	defer os.RemoveAll(root)
+	for _, name := range []string{"square", "cube"} {
+		as.NoError(os.Mkdir(filepath.Join(root, name), 0755))
+		as.NoError(ioutil.WriteFile(filepath.Join(root, name, "Dockerfile"), []byte{}, 0644))
+		as.NoError(ioutil.WriteFile(filepath.Join(root, name, name+"-function.yaml"), []byte{}, 0644))
+		as.NoError(ioutil.WriteFile(filepath.Join(root, name, name+".js"), []byte("module.exports = x => "+name+"(x)\n"), 0644))
+	}
+
+	var lock sync.Mutex
+	built := map[string]string{}
+	dockerExec = func(args []string) (string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		built[filepath.Base(args[3])] = args[2]
+		return "built " + args[2], nil
+	}
+	defer func() { dockerExec = docker.Exec }()
+
+	var out bytes.Buffer
+	err = buildAll(root, 2, options.CreateOptions{InitOptions: options.InitOptions{UserAccount: "me", TagStrategy: options.TagStrategyContentHash}}, &out)
+	as.NoError(err)
+	for _, name := range []string{"square", "cube"} {
+		tag, err := functions.ContentHashTag(filepath.Join(root, name))
+		as.NoError(err)
+		as.Equal("me/"+name+":"+tag, built[name])
+		// The output of each build follows its heading.
+		as.Contains(out.String(), fmt.Sprintf("\n==> %s (%s)\nbuilding image...\nbuilt me/%s:%s\n", name, filepath.Join(root, name), name, tag))
+	}
+	as.NotEqual(built["square"][len("me/square:"):], built["cube"][len("me/cube:"):])
+}
+
+func TestBuildForSeveralPlatforms(t *testing.T) {
+	as := assert.New(t)
+
+	var commands [][]string
+	dockerExec = func(args []string) (string, error) {
+		commands = append(commands, args)
+		return "", nil
+	}
+	defer func() { dockerExec = docker.Exec }()
+
+	dir, err := ioutil.TempDir("", "riff-buildx")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	buildOptions := options.BuildOptions{FunctionPath: dir, FunctionName: "square", UserAccount: "me", Version: "0.0.1", Push: true, Platform: "linux/amd64,linux/arm64"}
+	as.NoError(build(buildOptions, ioutil.Discard))
+	as.Equal([][]string{
+		{"buildx", "version"},
+		{"buildx", "build", "--platform", "linux/amd64,linux/arm64", "--push", "-t", "me/square:0.0.1", dir},
+	}, commands)
+
+	buildOptions.Push = false
+	as.EqualError(build(buildOptions, ioutil.Discard), "an image for several platforms cannot be loaded locally, set --push to build for linux/amd64,linux/arm64")
+}
+
+func TestBuildForPlatformWithoutBuildx(t *testing.T) {
+	as := assert.New(t)
+
+	dockerExec = func(args []string) (string, error) {
+		return "", errors.New("docker: 'buildx' is not a docker command.")
+	}
+	defer func() { dockerExec = docker.Exec }()
+
+	err := build(options.BuildOptions{FunctionName: "square", UserAccount: "me", Version: "0.0.1", Platform: "linux/arm64"}, ioutil.Discard)
+	as.EqualError(err, "--platform needs docker buildx, which is not available: install the buildx plugin or use a docker release that bundles it")
+}
+
+func TestPreviewBuild(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff build")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	functionDir := filepath.Join(dir, "square")
+	as.NoError(os.MkdirAll(functionDir, 0755))
+
+	buildOptions := options.BuildOptions{
+		FunctionPath: functionDir,
+		FunctionName: "square",
+		Version:      "0.0.1",
+		UserAccount:  "me",
+		Context:      dir,
+		NoCache:      true,
+		PreviewBuild: true,
+	}
+
+	var out bytes.Buffer
+	as.NoError(previewBuild(buildOptions, &out))
+	as.Equal(fmt.Sprintf("docker build --no-cache -t me/square:0.0.1 -f '%s' '%s'\n", filepath.Join(functionDir, "Dockerfile"), dir), out.String())
+
+	out.Reset()
+	buildOptions.Platform = "linux/amd64"
+	as.NoError(previewBuild(buildOptions, &out))
+	as.Contains(out.String(), "docker buildx build --platform linux/amd64 --load --no-cache -t me/square:0.0.1 -f ")
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/projectriff/riff-cli/pkg/functions"
+	"github.com/projectriff/riff-cli/pkg/initializers/core"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/spf13/cobra"
+)
+
+type CleanOptions struct {
+	path string
+	yes  bool
+}
+
+var cleanOptions CleanOptions
+
+// Where the confirmation of the files to delete is read from.
+var confirmInput io.Reader = os.Stdin
+
+// A FROM line of a riff invoker image, which only generated Dockerfiles build from.
+var invokerFromRegexp = regexp.MustCompile(`(?m)^FROM\s+projectriff/[\w-]+-function-invoker:`)
+
+// cleanCmd represents the clean command
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove the artifacts generated by 'riff init' from a function directory",
+	Long: `Remove the files 'riff init' generated in the function directory: the Dockerfile building from a riff
+invoker image, the function and topics resource definitions, and the manifest of the generated files. Other
+files, such as the function source or a hand-written Dockerfile, are left untouched. The files are listed, and
+only deleted once confirmed or with --yes.`,
+	Example: `  riff clean -f function/square --yes`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := functions.AbsPath(cleanOptions.path)
+		if err != nil {
+			return err
+		}
+		if !osutils.IsDirectory(dir) {
+			dir = filepath.Dir(dir)
+		}
+
+		cmd.SilenceUsage = true
+		return cleanFunction(dir, cleanOptions.yes, confirmInput, os.Stdout)
+	},
+}
+
+/*
+ * Deletes the generated files of the function in dir, after listing them. Unless yes is set, they are only
+ * deleted when the answer read from in confirms it.
+ */
+func cleanFunction(dir string, yes bool, in io.Reader, out io.Writer) error {
+	files, err := generatedFiles(dir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(out, "No generated files found in %s\n", dir)
+		return nil
+	}
+	fmt.Fprintf(out, "The generated files to delete are:\n")
+	for _, file := range files {
+		fmt.Fprintf(out, "  %s\n", displayPath(dir, file))
+	}
+	if !yes && !confirm(in, out, "Delete these files?") {
+		fmt.Fprintf(out, "Nothing deleted\n")
+		return nil
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Whether the answer to the question is yes, anything else, including no answer, declining.
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// The files of dir recognized as generated by 'riff init', sorted.
+func generatedFiles(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		if isGenerated(file) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func isGenerated(file string) bool {
+	name := filepath.Base(file)
+	switch {
+	case name == "Dockerfile":
+		return isGeneratedDockerfile(file)
+	case strings.HasSuffix(name, "-function.yaml"), strings.HasSuffix(name, "-topics.yaml"):
+		return true
+	case filepath.Ext(name) == ".json":
+		return isManifest(file)
+	}
+	return false
+}
+
+// Whether the Dockerfile builds from a riff invoker image, as a hand-written one is unlikely to.
+func isGeneratedDockerfile(file string) bool {
+	content, err := ioutil.ReadFile(file)
+	return err == nil && invokerFromRegexp.Match(content)
+}
+
+// Whether the JSON file is a manifest written with --manifest-out, listing the path and role of each file.
+func isManifest(file string) bool {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	manifest := core.Manifest{}
+	if json.Unmarshal(content, &manifest) != nil || len(manifest.Files) == 0 {
+		return false
+	}
+	for _, entry := range manifest.Files {
+		if entry.Path == "" || entry.Role == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	rootCmd.AddCommand(cleanCmd)
+
+	cleanCmd.Flags().StringVarP(&cleanOptions.path, "filepath", "f", "", "path or directory of the function, if a file is specified then the file's directory will be used (defaults to the current directory)")
+	cleanCmd.Flags().BoolVarP(&cleanOptions.yes, "yes", "y", false, "delete the generated files without asking for confirmation")
+}
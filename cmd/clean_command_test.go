@@ -0,0 +1,95 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/stretchr/testify/assert"
+)
+
+// A function directory with generated files, its source and a hand-written Dockerfile in a subdirectory.
+func functionToClean(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "riff-clean")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"Dockerfile":           "FROM projectriff/node-function-invoker:0.0.6\nADD square.js /functions/\n",
+		"square-function.yaml": "kind: Function\n",
+		"square-topics.yaml":   "kind: Topic\n",
+		"manifest.json":        `{"files": [{"path": "Dockerfile", "role": "dockerfile", "updated": false}]}`,
+		"package.json":         `{"name": "square"}`,
+		"square.js":            "module.exports = x => x ** 2;\n",
+		"other/Dockerfile":     "FROM node:8\nADD square.js /\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCleanRemovesOnlyGeneratedFiles(t *testing.T) {
+	as := assert.New(t)
+	dir := functionToClean(t)
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	as.NoError(cleanFunction(dir, true, strings.NewReader(""), &out))
+	as.Equal("The generated files to delete are:\n  Dockerfile\n  manifest.json\n  square-function.yaml\n  square-topics.yaml\n", out.String())
+	as.False(osutils.FileExists(filepath.Join(dir, "Dockerfile")))
+	as.False(osutils.FileExists(filepath.Join(dir, "square-function.yaml")))
+	as.False(osutils.FileExists(filepath.Join(dir, "square-topics.yaml")))
+	as.False(osutils.FileExists(filepath.Join(dir, "manifest.json")))
+	as.True(osutils.FileExists(filepath.Join(dir, "square.js")))
+	as.True(osutils.FileExists(filepath.Join(dir, "package.json")))
+	as.True(osutils.FileExists(filepath.Join(dir, "other", "Dockerfile")))
+}
+
+func TestCleanKeepsHandWrittenDockerfile(t *testing.T) {
+	as := assert.New(t)
+	dir := functionToClean(t)
+	defer os.RemoveAll(dir)
+	as.NoError(cleanFunction(filepath.Join(dir, "other"), true, strings.NewReader(""), ioutil.Discard))
+	as.True(osutils.FileExists(filepath.Join(dir, "other", "Dockerfile")))
+}
+
+func TestCleanAsksForConfirmation(t *testing.T) {
+	as := assert.New(t)
+	dir := functionToClean(t)
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	as.NoError(cleanFunction(dir, false, strings.NewReader("n\n"), &out))
+	as.Contains(out.String(), "Delete these files? [y/N] Nothing deleted\n")
+	as.True(osutils.FileExists(filepath.Join(dir, "Dockerfile")))
+
+	as.NoError(cleanFunction(dir, false, strings.NewReader("y\n"), ioutil.Discard))
+	as.False(osutils.FileExists(filepath.Join(dir, "Dockerfile")))
+}
@@ -0,0 +1,312 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+/*
+ * The config files found, from the lowest to the highest precedence: the global config, in
+ * $XDG_CONFIG_HOME/riff/config.yaml or else $HOME/.config/riff/config.yaml, then the project config, given with
+ * --config or else .riff.yaml, or .riff with another config extension, in dir or else in home.
+ */
+func configFiles(home string, dir string, projectFile string) []string {
+	var files []string
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	if global := filepath.Join(configHome, "riff", "config.yaml"); osutils.FileExists(global) {
+		files = append(files, global)
+	}
+	if projectFile != "" {
+		return append(files, projectFile)
+	}
+	for _, searched := range []string{dir, home} {
+		for _, ext := range viper.SupportedExts {
+			if project := filepath.Join(searched, ".riff."+ext); osutils.FileExists(project) {
+				return append(files, project)
+			}
+		}
+	}
+	return files
+}
+
+// The config files read, only logged once applied, when the flags tell whether stdout carries data.
+var configFilesRead []string
+
+/*
+ * Reads the config files in order, the values of each file replacing those of the previous ones. Each file is
+ * validated once merged, so that a problem is reported against the file introducing it.
+ */
+func loadConfig(files []string) error {
+	for i, file := range files {
+		viper.SetConfigFile(file)
+		read := viper.ReadInConfig
+		if i > 0 {
+			read = viper.MergeInConfig
+		}
+		if err := read(); err != nil {
+			return errors.New(fmt.Sprintf("cannot read config file %s: %v", file, err))
+		}
+		configFilesRead = append(configFilesRead, file)
+		if err := validateConfig(viper.AllSettings(), configSchema(rootCmd), file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+ * Gives each flag not set on the command line the value from the config files, if any. Flags therefore take
+ * precedence over the project config, which takes precedence over the global config, then the flag defaults.
+ * The informational logs then go to stderr when stdout carries data, before the config files read are logged.
+ */
+func applyConfig(flagset *pflag.FlagSet) error {
+	var err error
+	flagset.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed {
+			return
+		}
+		value, found := configValue(flag.Name)
+		if !found {
+			return
+		}
+		if strings.HasSuffix(flag.Value.Type(), "Array") || strings.HasSuffix(flag.Value.Type(), "Slice") {
+			values := viper.GetStringSlice(flag.Name)
+			if env, ok := value.(string); ok {
+				values = strings.Fields(env)
+			}
+			for _, value := range values {
+				if err = flagset.Set(flag.Name, value); err != nil {
+					return
+				}
+			}
+			return
+		}
+		// The raw value, as the string of a YAML number would not be written as in the file, 1.0 becoming 1.
+		switch value := value.(type) {
+		case string:
+			err = flagset.Set(flag.Name, value)
+		default:
+			if flag.Value.Type() == "string" {
+				err = errors.New(fmt.Sprintf("config value %v of %s must be a string, quote it", value, flag.Name))
+				return
+			}
+			err = flagset.Set(flag.Name, fmt.Sprint(value))
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if stdoutCarriesData(flagset) {
+		ioutils.Stdout = os.Stderr
+	}
+	for _, file := range configFilesRead {
+		ioutils.Infof("Using config file: %s\n", file)
+	}
+	configFilesRead = nil
+	return nil
+}
+
+// Variables the shell sets on its own, which are never read as riff options even unprefixed.
+var shellVariables = map[string]bool{
+	"HOME":     true,
+	"HOSTNAME": true,
+	"PATH":     true,
+	"PWD":      true,
+	"SHELL":    true,
+	"USER":     true,
+}
+
+// configValue returns the value of a flag from the environment or the config files. On top of the
+// RIFF_ prefixed variables, the unprefixed ones read by earlier versions are still honored as a fallback.
+func configValue(name string) (interface{}, bool) {
+	if viper.IsSet(name) {
+		return viper.Get(name), true
+	}
+	env := strings.ToUpper(name)
+	if value := os.Getenv(env); value != "" && !shellVariables[env] {
+		ioutils.Warnf("Environment variable %s is deprecated, use RIFF_%s instead\n", env, env)
+		return value, true
+	}
+	return nil, false
+}
+
+// Whether the flags make the command write data to stdout, such as a tar stream with --tar-out -.
+func stdoutCarriesData(flagset *pflag.FlagSet) bool {
+	tarOut, _ := flagset.GetString("tar-out")
+	showConfig, _ := flagset.GetBool("show-config")
+	outputFormat, _ := flagset.GetString("output-format")
+	return tarOut == "-" || showConfig || outputFormat == options.OutputFormatJson
+}
+
+// A JSON schema of the config file: an object whose only properties are the flags of the riff commands.
+type ConfigSchema struct {
+	Type                 string                    `json:"type"`
+	Properties           map[string]ConfigProperty `json:"properties"`
+	AdditionalProperties bool                      `json:"additionalProperties"`
+}
+
+type ConfigProperty struct {
+	Type string `json:"type"`
+}
+
+// The JSON schema types of the flag value types, string being the default.
+var configPropertyTypes = map[string]string{
+	"bool":        "boolean",
+	"int":         "integer",
+	"stringArray": "array",
+	"stringSlice": "array",
+}
+
+// The schema of the config file, with a property for each flag of the command and its subcommands.
+func configSchema(command *cobra.Command) ConfigSchema {
+	schema := ConfigSchema{Type: "object", Properties: map[string]ConfigProperty{}}
+	addProperty := func(flag *pflag.Flag) {
+		propertyType, ok := configPropertyTypes[flag.Value.Type()]
+		if !ok {
+			propertyType = "string"
+		}
+		schema.Properties[flag.Name] = ConfigProperty{Type: propertyType}
+	}
+	var visit func(*cobra.Command)
+	visit = func(command *cobra.Command) {
+		command.PersistentFlags().VisitAll(addProperty)
+		command.Flags().VisitAll(addProperty)
+		for _, child := range command.Commands() {
+			visit(child)
+		}
+	}
+	visit(command)
+	return schema
+}
+
+/*
+ * Validates the settings read from the config file against the schema. An unknown key is reported with the
+ * closest known one, as it is most likely a typo. Strings must be quoted when YAML would read them as another
+ * scalar, as the value read, such as the number 1.0, is not written as in the file.
+ */
+func validateConfig(settings map[string]interface{}, schema ConfigSchema, file string) error {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property, ok := schema.Properties[key]
+		if !ok {
+			if suggestion := closestProperty(key, schema); suggestion != "" {
+				return errors.New(fmt.Sprintf("unknown key '%s' in config file %s, did you mean '%s'?", key, file, suggestion))
+			}
+			return errors.New(fmt.Sprintf("unknown key '%s' in config file %s", key, file))
+		}
+		valid := true
+		switch value := settings[key].(type) {
+		case bool:
+			if property.Type == "string" {
+				return errors.New(fmt.Sprintf("key '%s' in config file %s must be a string, quote its value so that YAML does not read it as a boolean", key, file))
+			}
+			valid = property.Type == "boolean"
+		case int, int64, float64:
+			if property.Type == "string" {
+				return errors.New(fmt.Sprintf("key '%s' in config file %s must be a string, quote its value so that YAML does not read it as a number", key, file))
+			}
+			valid = property.Type == "integer"
+		case string:
+			valid = property.Type == "string" || property.Type == "array"
+		case []interface{}:
+			valid = property.Type == "array"
+		default:
+			valid = value == nil
+		}
+		if !valid {
+			return errors.New(fmt.Sprintf("key '%s' in config file %s must be of type %s", key, file, property.Type))
+		}
+	}
+	return nil
+}
+
+// The known property closest to the key, if it is within two edits of it.
+func closestProperty(key string, schema ConfigSchema) string {
+	closest, closestDistance := "", 3
+	for name := range schema.Properties {
+		if distance := editDistance(key, name); distance < closestDistance || distance == closestDistance && name < closest {
+			closest, closestDistance = name, distance
+		}
+	}
+	return closest
+}
+
+// The Levenshtein distance between both strings.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minOf(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func minOf(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
+
+/*
+ * Prints the effective options, after defaults, config file and flags have been merged, as YAML.
+ */
+func showConfig(w io.Writer, initOptions options.InitOptions) error {
+	out, err := yaml.Marshal(initOptions)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
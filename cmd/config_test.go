@@ -0,0 +1,213 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/projectriff/riff-cli/cmd/utils"
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShowConfigIncludesConfigFileValues(t *testing.T) {
+	as := assert.New(t)
+	config, err := ioutil.TempFile("", "riff-config")
+	as.NoError(err)
+	defer os.Remove(config.Name())
+	config.WriteString("useraccount: acme\nversion: 1.2.3\n")
+	config.Close()
+
+	viper.SetConfigFile(config.Name())
+	viper.SetConfigType("yaml")
+	defer viper.Reset()
+	as.NoError(viper.ReadInConfig())
+
+	flagset := pflag.NewFlagSet("init", pflag.ContinueOnError)
+	utils.CreateInitFlags(flagset)
+	as.NoError(flagset.Set("version", "2.0.0"))
+	as.NoError(applyConfig(flagset))
+
+	initOptions := options.InitOptions{}
+	utils.MergeInitOptions(*flagset, &initOptions)
+	as.Equal("acme", initOptions.UserAccount)
+	as.Equal("2.0.0", initOptions.Version)
+
+	var out bytes.Buffer
+	as.NoError(showConfig(&out, initOptions))
+	as.Contains(strings.ToLower(out.String()), "useraccount: acme")
+}
+
+func testConfigSchema() ConfigSchema {
+	command := &cobra.Command{Use: "riff"}
+	command.PersistentFlags().Bool("offline", false, "")
+	initCommand := &cobra.Command{Use: "init"}
+	utils.CreateInitFlags(initCommand.Flags())
+	command.AddCommand(initCommand)
+	return configSchema(command)
+}
+
+func TestValidConfig(t *testing.T) {
+	as := assert.New(t)
+	schema := testConfigSchema()
+	as.Equal("boolean", schema.Properties["offline"].Type)
+	as.Equal("integer", schema.Properties["port"].Type)
+	as.Equal("array", schema.Properties["secret"].Type)
+
+	settings := map[string]interface{}{
+		"useraccount": "acme",
+		"version":     "1.0",
+		"offline":     true,
+		"port":        8080,
+		"secret":      []interface{}{"db-credentials"},
+	}
+	as.NoError(validateConfig(settings, schema, ".riff.yaml"))
+}
+
+func TestConfigWithMisspelledKey(t *testing.T) {
+	as := assert.New(t)
+	settings := map[string]interface{}{"useraccount": "acme", "usraccount": "acme"}
+	err := validateConfig(settings, testConfigSchema(), ".riff.yaml")
+	as.EqualError(err, "unknown key 'usraccount' in config file .riff.yaml, did you mean 'useraccount'?")
+
+	settings = map[string]interface{}{"colour": "blue"}
+	err = validateConfig(settings, testConfigSchema(), ".riff.yaml")
+	as.EqualError(err, "unknown key 'colour' in config file .riff.yaml")
+}
+
+func TestConfigWithWrongType(t *testing.T) {
+	as := assert.New(t)
+	settings := map[string]interface{}{"offline": "sometimes"}
+	err := validateConfig(settings, testConfigSchema(), ".riff.yaml")
+	as.EqualError(err, "key 'offline' in config file .riff.yaml must be of type boolean")
+}
+
+func TestConfigWithUnquotedVersion(t *testing.T) {
+	as := assert.New(t)
+	config, err := ioutil.TempFile("", "riff-config")
+	as.NoError(err)
+	defer os.Remove(config.Name())
+	config.WriteString("useraccount: acme\nversion: 1.0\n")
+	config.Close()
+
+	viper.SetConfigType("yaml")
+	defer viper.Reset()
+	err = loadConfig([]string{config.Name()})
+	as.EqualError(err, fmt.Sprintf("key 'version' in config file %s must be a string, quote its value so that YAML does not read it as a number", config.Name()))
+
+	// A value the validation did not see, such as one set in code, is not coerced either.
+	flagset := pflag.NewFlagSet("init", pflag.ContinueOnError)
+	utils.CreateInitFlags(flagset)
+	viper.Set("version", 1.0)
+	as.EqualError(applyConfig(flagset), "config value 1 of version must be a string, quote it")
+
+	viper.Set("version", "1.0")
+	as.NoError(applyConfig(flagset))
+	version, _ := flagset.GetString("version")
+	as.Equal("1.0", version)
+}
+
+func TestUnprefixedEnvironmentVariablesStillRead(t *testing.T) {
+	as := assert.New(t)
+	viper.SetEnvPrefix("riff")
+	viper.AutomaticEnv()
+	defer viper.Reset()
+	os.Setenv("USERACCOUNT", "acme")
+	defer os.Unsetenv("USERACCOUNT")
+	os.Setenv("REGISTRY", "registry.acme.com")
+	defer os.Unsetenv("REGISTRY")
+	os.Setenv("RIFF_REGISTRY", "registry.riff.io")
+	defer os.Unsetenv("RIFF_REGISTRY")
+
+	flagset := pflag.NewFlagSet("init", pflag.ContinueOnError)
+	utils.CreateInitFlags(flagset)
+	as.NoError(applyConfig(flagset))
+
+	initOptions := options.InitOptions{}
+	utils.MergeInitOptions(*flagset, &initOptions)
+	as.Equal("acme", initOptions.UserAccount)
+	as.Equal("registry.riff.io", initOptions.Registry)
+	path, _ := flagset.GetString("path")
+	as.Empty(path, "PATH is set by the shell, not for riff")
+}
+
+func TestGlobalConfigUnderProjectConfig(t *testing.T) {
+	as := assert.New(t)
+	home, err := ioutil.TempDir("", "riff-home")
+	as.NoError(err)
+	defer os.RemoveAll(home)
+	project := filepath.Join(home, "square")
+	as.NoError(os.MkdirAll(filepath.Join(home, ".config", "riff"), 0755))
+	as.NoError(os.Mkdir(project, 0755))
+	global := filepath.Join(home, ".config", "riff", "config.yaml")
+	as.NoError(ioutil.WriteFile(global, []byte("registry: registry.acme.com\nuseraccount: acme\nversion: 1.0.0\n"), 0644))
+	as.NoError(ioutil.WriteFile(filepath.Join(project, ".riff.yaml"), []byte("useraccount: squares\n"), 0644))
+
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	os.Unsetenv("XDG_CONFIG_HOME")
+	defer os.Setenv("XDG_CONFIG_HOME", configHome)
+	files := configFiles(home, project, "")
+	as.Equal([]string{global, filepath.Join(project, ".riff.yaml")}, files)
+
+	defer viper.Reset()
+	as.NoError(loadConfig(files))
+
+	flagset := pflag.NewFlagSet("init", pflag.ContinueOnError)
+	utils.CreateInitFlags(flagset)
+	as.NoError(flagset.Set("version", "2.0.0"))
+	as.NoError(applyConfig(flagset))
+
+	initOptions := options.InitOptions{}
+	utils.MergeInitOptions(*flagset, &initOptions)
+	as.Equal("registry.acme.com", initOptions.Registry)
+	as.Equal("squares", initOptions.UserAccount)
+	as.Equal("2.0.0", initOptions.Version)
+}
+
+func TestConfigFilesLoggedToStderrWhenStdoutCarriesData(t *testing.T) {
+	as := assert.New(t)
+	var stdout, stderr bytes.Buffer
+	ioutils.Stdout = &stdout
+	ioutils.Stderr = &stderr
+	defer func() {
+		ioutils.Stdout = os.Stdout
+		ioutils.Stderr = os.Stderr
+	}()
+
+	flagset := pflag.NewFlagSet("init", pflag.ContinueOnError)
+	flagset.String("tar-out", "", "")
+	configFilesRead = []string{"/home/me/.riff.yaml"}
+	as.NoError(applyConfig(flagset))
+	as.Equal("Using config file: /home/me/.riff.yaml\n", stdout.String())
+
+	stdout.Reset()
+	as.NoError(flagset.Set("tar-out", "-"))
+	configFilesRead = []string{"/home/me/.riff.yaml"}
+	as.NoError(applyConfig(flagset))
+	as.Empty(stdout.String())
+	as.Equal(os.Stderr, ioutils.Stdout)
+}
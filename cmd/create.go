@@ -24,12 +24,15 @@ import (
 	"os"
 	"github.com/projectriff/riff-cli/cmd/utils"
 	"github.com/projectriff/riff-cli/cmd/opts"
+	"github.com/projectriff/riff-cli/pkg/initializers"
 )
 
 var createChainCmd = utils.CommandChain(initCmd, buildCmd, applyCmd)
 
 var createJavaChainCmd = utils.CommandChain(initJavaCmd, buildCmd, applyCmd)
 
+var createDenoChainCmd = utils.CommandChain(initDenoCmd, buildCmd, applyCmd)
+
 var createNodeChainCmd = utils.CommandChain(initNodeCmd, buildCmd, applyCmd)
 
 var createPythonChainCmd = utils.CommandChain(initPythonCmd, buildCmd, applyCmd)
@@ -44,6 +47,7 @@ var createCmd = &cobra.Command{
 	RunE:   createChainCmd.RunE,
 	PreRun: createChainCmd.PreRun,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		recordUsage(cmd)
 		if !opts.CreateOptions.Initialized {
 			opts.CreateOptions = options.CreateOptions{}
 			var flagset pflag.FlagSet
@@ -53,10 +57,20 @@ var createCmd = &cobra.Command{
 				flagset = *cmd.Parent().PersistentFlags()
 			}
 
+			if err := applyConfig(&flagset); err != nil {
+				ioutils.Error(err)
+				os.Exit(1)
+			}
 			utils.MergeInitOptions(flagset, &opts.CreateOptions.InitOptions)
+			opts.CreateOptions.Offline = offline(cmd)
 			utils.MergeBuildOptions(flagset, &opts.CreateOptions)
 			utils.MergeApplyOptions(flagset, &opts.CreateOptions)
 
+			if err := validateFlagCombinations(opts.CreateOptions); err != nil {
+				ioutils.Error(err)
+				os.Exit(1)
+			}
+
 			if len(args) > 0 {
 				if len(args) == 1 && opts.InitOptions.FunctionPath == "" {
 					opts.CreateOptions.FunctionPath = args[0]
@@ -67,15 +81,44 @@ var createCmd = &cobra.Command{
 				}
 			}
 
+			if opts.CreateOptions.FromGit != "" {
+				var err error
+				clonedFunction, err = cloneFunction(&opts.CreateOptions.InitOptions)
+				if err != nil {
+					ioutils.Error(err)
+					os.Exit(1)
+				}
+			}
+
 			err := options.ValidateAndCleanInitOptions(&opts.CreateOptions.InitOptions)
 			if err != nil {
 				ioutils.Error(err)
 				os.Exit(1)
 			}
+			if show, _ := flagset.GetBool("show-config"); show {
+				if err := showConfig(os.Stdout, opts.CreateOptions.InitOptions); err != nil {
+					ioutils.Error(err)
+					os.Exit(1)
+				}
+				os.Exit(0)
+			}
+			if opts.CreateOptions.FromOpenApi != "" {
+				if err := initializers.ScaffoldFromOpenApi(openApiLanguage(cmd), opts.CreateOptions.InitOptions); err != nil {
+					ioutils.Error(err)
+					os.Exit(1)
+				}
+			}
 			opts.CreateOptions.Initialized = true
 		}
 		createChainCmd.PersistentPreRun(cmd, args)
 	},
+	// The function cloned with --from-git is built and applied from the clone, then its artifacts are copied.
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		if clonedFunction != nil {
+			return clonedFunction.finish(opts.CreateOptions.InitOptions)
+		}
+		return nil
+	},
 }
 
 var createJavaCmd = &cobra.Command{
@@ -88,7 +131,10 @@ var createJavaCmd = &cobra.Command{
 		opts.Handler = utils.GetHandler(cmd)
 		createJavaChainCmd.PreRun(cmd, args)
 	},
-	PersistentPreRun: createJavaChainCmd.PersistentPreRun,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		recordUsage(cmd)
+		createJavaChainCmd.PersistentPreRun(cmd, args)
+	},
 }
 
 var createShellCmd = &cobra.Command{
@@ -100,6 +146,18 @@ var createShellCmd = &cobra.Command{
 	RunE:   createShellChainCmd.RunE,
 }
 
+var createDenoCmd = &cobra.Command{
+	Use:   "deno",
+	Short: "Create a Deno function",
+	Long:  utils.CreateDenoCmdLong(),
+
+	PreRun: func(cmd *cobra.Command, args []string) {
+		opts.Handler = utils.GetHandler(cmd)
+		createDenoChainCmd.PreRun(cmd, args)
+	},
+	RunE: createDenoChainCmd.RunE,
+}
+
 var createNodeCmd = &cobra.Command{
 	Use:   "node",
 	Short: "Create a node.js function",
@@ -134,7 +192,9 @@ func init() {
 	utils.CreateInitFlags(createCmd.PersistentFlags())
 	utils.CreateBuildFlags(createCmd.PersistentFlags())
 	utils.CreateApplyFlags(createCmd.PersistentFlags())
+	createCmd.PersistentFlags().Bool("show-config", false, "print the effective options, including config file values and defaults, and exit without creating")
 
+	createCmd.AddCommand(createDenoCmd)
 	createCmd.AddCommand(createJavaCmd)
 	createCmd.AddCommand(createJsCmd)
 	createCmd.AddCommand(createNodeCmd)
@@ -142,8 +202,8 @@ func init() {
 	createCmd.AddCommand(createShellCmd)
 
 	createJavaCmd.Flags().String("handler", "", "the fully qualified class name of the function handler")
-	createJavaCmd.MarkFlagRequired("handler")
+
+	createDenoCmd.Flags().String("handler", "", "the name of the exported function handler (defaults to the default export)")
 
 	createPythonCmd.Flags().String("handler", "", "the name of the function handler")
-	createPythonCmd.MarkFlagRequired("handler")
 }
@@ -17,6 +17,8 @@
 package cmd
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"github.com/stretchr/testify/assert"
 	"github.com/projectriff/riff-cli/pkg/options"
@@ -133,6 +135,30 @@ func TestInitCommandExplicitPathAndLang(t *testing.T) {
 	as.Equal("../test_data/shell/echo", opts.InitOptions.FunctionPath)
 }
 
+func TestInitAndCreateCommandsOffline(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+	defer rootCmd.PersistentFlags().Set("offline", "false")
+	defer initCmd.PersistentFlags().Set("base-image-allowlist", "")
+	defer createCmd.PersistentFlags().Set("base-image-allowlist", "")
+
+	for _, command := range []string{"init", "create"} {
+		clearInitOptions()
+		as := assert.New(t)
+		rootCmd.SetArgs([]string{command, "shell", "--offline", "--dry-run", "-f", "../test_data/shell/echo", "-a", "echo.sh", "-v", "0.0.1-snapshot",
+			"--base-image-allowlist", server.URL + "/allowlist"})
+
+		_, err := rootCmd.ExecuteC()
+		as.NoError(err, command)
+
+		as.True(opts.InitOptions.Offline, command)
+		as.Equal(0, requests, command)
+	}
+}
+
 func clearInitOptions() {
 	opts.InitOptions = options.InitOptions{}
 	opts.CreateOptions = options.CreateOptions{}
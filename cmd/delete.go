@@ -69,8 +69,13 @@ var deleteCmd = &cobra.Command {
 				abs = filepath.Dir(abs)
 				optionPath = filepath.Dir(optionPath)
 			}
+			files, err := resourceFileArgs(abs)
+			if err != nil {
+				ioutils.Errorf("Error: %v\n", err)
+				return
+			}
 			fmt.Printf("Deleting %v resources\n\n", optionPath)
-			cmdArgs = []string{"delete", "-f", abs}
+			cmdArgs = append([]string{"delete"}, files...)
 		} else {
 			if osutils.IsDirectory(abs) {
 				fmt.Printf("Deleting %v function\n\n", deleteOptions.name)
@@ -81,7 +86,7 @@ var deleteCmd = &cobra.Command {
 			}
 		}
 
-		output, err := kubectl.ExecForString(cmdArgs)
+		output, err := withRetries(func() (string, error) { return kubectl.ExecForString(cmdArgs) })
 		if err != nil {
 			ioutils.Errorf("Error: %v\n", err)
 			return
@@ -0,0 +1,210 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/projectriff/riff-cli/pkg/functions"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/spf13/cobra"
+)
+
+type ExportOptions struct {
+	name string
+	path string
+	out  string
+}
+
+var exportOptions ExportOptions
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Bundle the function artifacts into a tarball",
+	Long:  `Bundle the function directory, including its Dockerfile, resource definitions and source, into a gzipped tarball.`,
+	Example: `  riff export -n square
+    or
+  riff export -f function/square --out square-0.0.1.tgz`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportOptions.name == "" {
+			var err error
+			exportOptions.name, err = functions.FunctionNameFromPath(exportOptions.path)
+			if err != nil {
+				return err
+			}
+		}
+		dir, err := functions.AbsPath(exportOptions.path)
+		if err != nil {
+			return err
+		}
+		if !osutils.IsDirectory(dir) {
+			dir = filepath.Dir(dir)
+		}
+		out := exportOptions.out
+		if out == "" {
+			out = exportOptions.name + ".tgz"
+		}
+
+		cmd.SilenceUsage = true
+		file, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		err = exportFunction(dir, exportOptions.name, file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(out)
+			return err
+		}
+		fmt.Printf("Exported %v to %v\n", dir, out)
+		return nil
+	},
+}
+
+/*
+ * Writes the function directory as a gzipped tarball, its entries under a top level directory named
+ * after the function. Version control metadata and the tarball itself, when written in the directory,
+ * are left out.
+ */
+func exportFunction(dir string, name string, w io.Writer) error {
+	if !osutils.FileExists(filepath.Join(dir, "Dockerfile")) {
+		return errors.New(fmt.Sprintf("no Dockerfile found in %s, initialize the function with 'riff init' first", dir))
+	}
+	var self os.FileInfo
+	if file, ok := w.(*os.File); ok {
+		self, _ = file.Stat()
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	err := archiveDirectory(archive, dir, name, self)
+	if err != nil {
+		return err
+	}
+	if err = archive.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+/*
+ * Writes the build context of a function, the directory holding its generated Dockerfile and source, as a
+ * plain tar stream with its entries at the root, such as 'docker build -' reads.
+ */
+func writeBuildContext(dir string, w io.Writer) error {
+	var self os.FileInfo
+	if file, ok := w.(*os.File); ok {
+		self, _ = file.Stat()
+	}
+	archive := tar.NewWriter(w)
+	err := archiveDirectory(archive, dir, "", self)
+	if err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+/*
+ * Adds the files of dir to the archive under prefix, leaving out version control metadata and self, the
+ * archive being written, when it is in the directory.
+ */
+func archiveDirectory(archive *tar.Writer, dir string, prefix string, self os.FileInfo) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() || self != nil && os.SameFile(info, self) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(prefix, rel))
+		if header.Name == "." {
+			return nil
+		}
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err = archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(archive, file)
+		return err
+	})
+}
+
+/*
+ * Streams the build context of the initialized function with --tar-out, to stdout with '-': the --context
+ * directory if given, and the function directory otherwise.
+ */
+func tarOut(initOptions options.InitOptions) error {
+	dir := initOptions.Context
+	if dir == "" {
+		dir = options.FunctionDir(initOptions)
+	}
+	if initOptions.TarOut == "-" {
+		return writeBuildContext(dir, os.Stdout)
+	}
+	file, err := os.Create(initOptions.TarOut)
+	if err != nil {
+		return err
+	}
+	err = writeBuildContext(dir, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(initOptions.TarOut)
+	}
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportOptions.name, "name", "n", "", "the name of the function (defaults to the name of the function directory)")
+	exportCmd.Flags().StringVarP(&exportOptions.path, "filepath", "f", "", "path or directory of the function, if a file is specified then the file's directory will be used (defaults to the current directory)")
+	exportCmd.Flags().StringVarP(&exportOptions.out, "out", "", "", "the tarball to write (defaults to <name>.tgz)")
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportFunction(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-export")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	for file, content := range map[string]string{
+		"Dockerfile":           "FROM projectriff/node-function-invoker:0.0.5\n",
+		"square-function.yaml": "kind: Function\n",
+		"square-topics.yaml":   "kind: Topic\n",
+		"square.js":            "module.exports = x => x ** 2\n",
+		"lib/helper.js":        "module.exports = {}\n",
+		".git/HEAD":            "ref: refs/heads/master\n",
+	} {
+		as.NoError(os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755))
+		as.NoError(ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+	}
+
+	out, err := os.Create(filepath.Join(dir, "square.tgz"))
+	as.NoError(err)
+	as.NoError(exportFunction(dir, "square", out))
+	as.NoError(out.Close())
+
+	tarball, err := os.Open(filepath.Join(dir, "square.tgz"))
+	as.NoError(err)
+	defer tarball.Close()
+	gz, err := gzip.NewReader(tarball)
+	as.NoError(err)
+	archive := tar.NewReader(gz)
+	entries := map[string]string{}
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		as.NoError(err)
+		content, err := ioutil.ReadAll(archive)
+		as.NoError(err)
+		entries[header.Name] = string(content)
+	}
+
+	as.Equal("FROM projectriff/node-function-invoker:0.0.5\n", entries["square/Dockerfile"])
+	as.Contains(entries, "square/square-function.yaml")
+	as.Contains(entries, "square/square-topics.yaml")
+	as.Contains(entries, "square/square.js")
+	as.Contains(entries, "square/lib/helper.js")
+	as.NotContains(entries, "square/.git/HEAD")
+	as.NotContains(entries, "square/square.tgz")
+}
+
+func TestExportUninitializedFunction(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-export")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	err = exportFunction(dir, "square", ioutil.Discard)
+	as.Error(err)
+	as.Contains(err.Error(), "riff init")
+}
+
+func TestWriteBuildContext(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-tar-out")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	for file, content := range map[string]string{
+		"Dockerfile":    "FROM projectriff/node-function-invoker:0.0.5\n",
+		"square.js":     "module.exports = x => x ** 2\n",
+		"lib/helper.js": "module.exports = {}\n",
+		".git/HEAD":     "ref: refs/heads/master\n",
+	} {
+		as.NoError(os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755))
+		as.NoError(ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+	}
+
+	var stream bytes.Buffer
+	as.NoError(writeBuildContext(dir, &stream))
+
+	archive := tar.NewReader(&stream)
+	entries := map[string]string{}
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		as.NoError(err)
+		content, err := ioutil.ReadAll(archive)
+		as.NoError(err)
+		entries[header.Name] = string(content)
+	}
+
+	as.Equal("FROM projectriff/node-function-invoker:0.0.5\n", entries["Dockerfile"])
+	as.Equal("module.exports = x => x ** 2\n", entries["square.js"])
+	as.Contains(entries, "lib/")
+	as.Contains(entries, "lib/helper.js")
+	as.NotContains(entries, ".git/HEAD")
+	as.NotContains(entries, "./")
+}
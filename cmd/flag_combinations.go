@@ -0,0 +1,153 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+)
+
+type flagConflict struct {
+	first    string
+	second   string
+	conflict func(opts options.CreateOptions) bool
+}
+
+var flagConflicts = []flagConflict{
+	{
+		first:    "dry-run",
+		second:   "force",
+		conflict: func(opts options.CreateOptions) bool { return opts.DryRun && opts.Force },
+	},
+	{
+		// A user account that already names a registry host would be prefixed twice.
+		first:  "useraccount",
+		second: "registry",
+		conflict: func(opts options.CreateOptions) bool {
+			return opts.Registry != "" && strings.Contains(opts.UserAccount, "/")
+		},
+	},
+	{
+		first:    "dry-run",
+		second:   "server-dry-run",
+		conflict: func(opts options.CreateOptions) bool { return opts.DryRun && opts.ServerDryRun },
+	},
+	{
+		first:    "diff",
+		second:   "server-dry-run",
+		conflict: func(opts options.CreateOptions) bool { return opts.Diff && opts.ServerDryRun },
+	},
+	{
+		// A fixed replica count leaves nothing to autoscale.
+		first:    "replicas",
+		second:   "min-scale",
+		conflict: func(opts options.CreateOptions) bool { return opts.Replicas != nil && opts.MinScale != 0 },
+	},
+	{
+		first:    "replicas",
+		second:   "max-scale",
+		conflict: func(opts options.CreateOptions) bool { return opts.Replicas != nil && opts.MaxScale != 0 },
+	},
+	{
+		first:    "replicas",
+		second:   "keda",
+		conflict: func(opts options.CreateOptions) bool { return opts.Replicas != nil && opts.Keda },
+	},
+	{
+		first:    "install-cmd",
+		second:   "no-install",
+		conflict: func(opts options.CreateOptions) bool { return opts.InstallCmd != "" && opts.NoInstall },
+	},
+	{
+		// The generated files are streamed, which writes them.
+		first:    "tar-out",
+		second:   "dry-run",
+		conflict: func(opts options.CreateOptions) bool { return opts.TarOut != "" && opts.DryRun },
+	},
+	{
+		// The function stub is written before the artifacts are generated.
+		first:    "from-openapi",
+		second:   "dry-run",
+		conflict: func(opts options.CreateOptions) bool { return opts.FromOpenApi != "" && opts.DryRun },
+	},
+	{
+		// The cloned function already has its source.
+		first:    "from-openapi",
+		second:   "from-git",
+		conflict: func(opts options.CreateOptions) bool { return opts.FromOpenApi != "" && opts.FromGit != "" },
+	},
+	{
+		// The Helm chart is never written with --only.
+		first:    "helm",
+		second:   "only",
+		conflict: func(opts options.CreateOptions) bool { return opts.Helm && opts.Only != "" },
+	},
+	{
+		// The kustomization is written with the resources.
+		first:  "kustomize",
+		second: "only",
+		conflict: func(opts options.CreateOptions) bool {
+			return opts.Kustomize && opts.Only == options.OnlyDockerfile
+		},
+	},
+}
+
+type flagRequirement struct {
+	flag     string
+	required string
+	missing  func(opts options.CreateOptions) bool
+}
+
+var flagRequirements = []flagRequirement{
+	{
+		flag:     "env",
+		required: "env-file",
+		missing:  func(opts options.CreateOptions) bool { return len(opts.Env) > 0 && !opts.EnvFile },
+	},
+	{
+		// Only the KEDA ScaledObject has replica bounds.
+		flag:     "min-replicas",
+		required: "keda",
+		missing:  func(opts options.CreateOptions) bool { return opts.MinReplicas != 0 && !opts.Keda },
+	},
+	{
+		flag:     "max-replicas",
+		required: "keda",
+		missing:  func(opts options.CreateOptions) bool { return opts.MaxReplicas != 0 && !opts.Keda },
+	},
+}
+
+/*
+ * Rejects combinations of flags that cannot be honoured together, and flags given without the one they
+ * depend on, naming both flags.
+ */
+func validateFlagCombinations(opts options.CreateOptions) error {
+	for _, c := range flagConflicts {
+		if c.conflict(opts) {
+			return errors.New(fmt.Sprintf("flags --%s and --%s cannot be used together", c.first, c.second))
+		}
+	}
+	for _, r := range flagRequirements {
+		if r.missing(opts) {
+			return errors.New(fmt.Sprintf("flag --%s is only supported with --%s", r.flag, r.required))
+		}
+	}
+	return nil
+}
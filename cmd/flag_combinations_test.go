@@ -0,0 +1,92 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidFlagCombinations(t *testing.T) {
+	as := assert.New(t)
+	as.NoError(validateFlagCombinations(options.CreateOptions{}))
+	as.NoError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{DryRun: true}, Push: true}))
+	as.NoError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{UserAccount: "me", Registry: "gcr.io"}}))
+}
+
+func TestDryRunConflictsWithForce(t *testing.T) {
+	as := assert.New(t)
+	err := validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{DryRun: true, Force: true}})
+	as.Error(err)
+	as.Equal("flags --dry-run and --force cannot be used together", err.Error())
+}
+
+func TestServerDryRunConflicts(t *testing.T) {
+	as := assert.New(t)
+	as.NoError(validateFlagCombinations(options.CreateOptions{ServerDryRun: true}))
+	as.EqualError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{DryRun: true}, ServerDryRun: true}), "flags --dry-run and --server-dry-run cannot be used together")
+	as.EqualError(validateFlagCombinations(options.CreateOptions{Diff: true, ServerDryRun: true}), "flags --diff and --server-dry-run cannot be used together")
+}
+
+func TestReplicasConflictWithAutoscaling(t *testing.T) {
+	as := assert.New(t)
+	replicas := 2
+	as.NoError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{Replicas: &replicas}}))
+
+	err := validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{Replicas: &replicas, MinScale: 1}})
+	as.EqualError(err, "flags --replicas and --min-scale cannot be used together")
+	err = validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{Replicas: &replicas, MaxScale: 4}})
+	as.EqualError(err, "flags --replicas and --max-scale cannot be used together")
+	replicas = 0
+	err = validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{Replicas: &replicas, Keda: true}})
+	as.EqualError(err, "flags --replicas and --keda cannot be used together")
+}
+
+func TestGeneratedFilesConflicts(t *testing.T) {
+	as := assert.New(t)
+	as.EqualError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{InstallCmd: "make", NoInstall: true}}), "flags --install-cmd and --no-install cannot be used together")
+	as.EqualError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{TarOut: "-", DryRun: true}}), "flags --tar-out and --dry-run cannot be used together")
+	as.EqualError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{FromOpenApi: "api.yaml", DryRun: true}}), "flags --from-openapi and --dry-run cannot be used together")
+	as.EqualError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{FromOpenApi: "api.yaml", FromGit: "https://github.com/me/square.git"}}), "flags --from-openapi and --from-git cannot be used together")
+	as.EqualError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{Helm: true, Only: options.OnlyResources}}), "flags --helm and --only cannot be used together")
+
+	as.NoError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{Kustomize: true, Only: options.OnlyResources}}))
+	as.EqualError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{Kustomize: true, Only: options.OnlyDockerfile}}), "flags --kustomize and --only cannot be used together")
+}
+
+func TestEnvRequiresEnvFile(t *testing.T) {
+	as := assert.New(t)
+	as.NoError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{EnvFile: true, Env: []string{"GREETING=hello"}}}))
+	as.EqualError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{Env: []string{"GREETING=hello"}}}), "flag --env is only supported with --env-file")
+}
+
+func TestReplicaBoundsRequireKeda(t *testing.T) {
+	as := assert.New(t)
+	as.NoError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{Keda: true, MinReplicas: 1, MaxReplicas: 5}}))
+	as.EqualError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{MinReplicas: 1}}), "flag --min-replicas is only supported with --keda")
+	as.EqualError(validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{MaxReplicas: 5}}), "flag --max-replicas is only supported with --keda")
+}
+
+func TestQualifiedUserAccountConflictsWithRegistry(t *testing.T) {
+	as := assert.New(t)
+	err := validateFlagCombinations(options.CreateOptions{InitOptions: options.InitOptions{UserAccount: "gcr.io/me", Registry: "gcr.io"}})
+	as.Error(err)
+	as.Contains(err.Error(), "--useraccount")
+	as.Contains(err.Error(), "--registry")
+}
@@ -17,6 +17,9 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
+
 	"github.com/spf13/cobra"
 	"github.com/projectriff/riff-cli/pkg/ioutils"
 	"github.com/projectriff/riff-cli/pkg/options"
@@ -48,6 +51,11 @@ var initCmd = &cobra.Command{
 		return nil
 	},
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		recordUsage(cmd)
+		if list, _ := cmd.Flags().GetBool("list-languages"); list {
+			listLanguages(os.Stdout)
+			os.Exit(0)
+		}
 		opts.InitOptions = opts.CreateOptions.InitOptions
 		if !opts.CreateOptions.Initialized {
 			opts.InitOptions = opts.CreateOptions.InitOptions
@@ -57,7 +65,17 @@ var initCmd = &cobra.Command{
 			} else {
 				flagset = *cmd.Parent().PersistentFlags()
 			}
+			if err := applyConfig(&flagset); err != nil {
+				ioutils.Error(err)
+				os.Exit(1)
+			}
 			utils.MergeInitOptions(flagset, &opts.InitOptions)
+			opts.InitOptions.Offline = offline(cmd)
+
+			if err := validateFlagCombinations(options.CreateOptions{InitOptions: opts.InitOptions}); err != nil {
+				ioutils.Error(err)
+				os.Exit(1)
+			}
 
 			if len(args) > 0 {
 				if len(args) == 1 && opts.InitOptions.FunctionPath == "" {
@@ -69,14 +87,49 @@ var initCmd = &cobra.Command{
 				}
 			}
 
+			if opts.InitOptions.FromGit != "" {
+				var err error
+				clonedFunction, err = cloneFunction(&opts.InitOptions)
+				if err != nil {
+					ioutils.Error(err)
+					os.Exit(1)
+				}
+			}
+
 			err := options.ValidateAndCleanInitOptions(&opts.InitOptions)
 			if err != nil {
+				ioutils.Error(err)
 				os.Exit(1)
 			}
+			if show, _ := flagset.GetBool("show-config"); show {
+				if err := showConfig(os.Stdout, opts.InitOptions); err != nil {
+					ioutils.Error(err)
+					os.Exit(1)
+				}
+				os.Exit(0)
+			}
+			if opts.InitOptions.FromOpenApi != "" {
+				if err := initializers.ScaffoldFromOpenApi(openApiLanguage(cmd), opts.InitOptions); err != nil {
+					ioutils.Error(err)
+					os.Exit(1)
+				}
+			}
+			ioutils.Debugf("Initializing function %s from %s\n", opts.InitOptions.FunctionName, opts.InitOptions.FunctionPath)
 
 			opts.CreateOptions.Initialized = true
 		}
 	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		if opts.InitOptions.TarOut != "" {
+			if err := tarOut(opts.InitOptions); err != nil {
+				return err
+			}
+		}
+		if clonedFunction != nil {
+			return clonedFunction.finish(opts.InitOptions)
+		}
+		return nil
+	},
 }
 
 /*
@@ -115,6 +168,21 @@ var initShellCmd = &cobra.Command{
 		return nil
 	},
 }
+/*
+ * init deno Command
+ */
+
+var initDenoCmd = &cobra.Command{
+	Use:   "deno",
+	Short: "Initialize a Deno function",
+	Long:  utils.InitDenoCmdLong(),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts.InitOptions.Handler = utils.GetHandler(cmd)
+		return initializers.Deno().Initialize(opts.InitOptions)
+	},
+}
+
 /*
  * init node Command
  */
@@ -134,6 +202,66 @@ var initNodeCmd = &cobra.Command{
 	Aliases: []string{"js"},
 }
 
+/*
+ * init auto Command
+ */
+
+var initAutoCmd = &cobra.Command{
+	Use:   "auto",
+	Short: "Initialize a function, detecting its language",
+	Long: `Initialize the function based on the code available in the path directory, detecting the language from
+  the function file and reporting which one was detected before generating the function resources.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return initAuto(opts.InitOptions)
+	},
+}
+
+func initAuto(initOptions options.InitOptions) error {
+	language, err := initializers.DetectLanguage(initOptions)
+	if err != nil {
+		return err
+	}
+	// A JSON dry run reports the detected language itself.
+	if initOptions.OutputFormat != options.OutputFormatJson {
+		ioutils.Infof("Detected %s function\n", language)
+	}
+	return initializers.Initialize(initOptions)
+}
+
+/*
+ * init bulk Command
+ */
+
+var initBulkCmd = &cobra.Command{
+	Use:   "bulk [directory...]",
+	Short: "Initialize the functions in several directories",
+	Long: `Initialize the function in each of the given directories, detecting the language of each one. The function
+  name is derived from each directory, all other options apply to every function.`,
+	Example: `riff init bulk functions/* --parallel 4`,
+	Args:    cobra.MinimumNArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		parallel, _ := cmd.Flags().GetInt("parallel")
+		return initializers.InitializeAll(args, opts.InitOptions, parallel)
+	},
+	// Directories are validated one at a time, so only the flags are merged here.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		recordUsage(cmd)
+		flagset := *cmd.Parent().PersistentFlags()
+		if err := applyConfig(&flagset); err != nil {
+			ioutils.Error(err)
+			os.Exit(1)
+		}
+		utils.MergeInitOptions(flagset, &opts.InitOptions)
+		opts.InitOptions.Offline = offline(cmd)
+		if err := validateFlagCombinations(options.CreateOptions{InitOptions: opts.InitOptions}); err != nil {
+			ioutils.Error(err)
+			os.Exit(1)
+		}
+	},
+}
+
 /*
  * init python Command
  */
@@ -155,20 +283,42 @@ var initPythonCmd = &cobra.Command{
 }
 
 
+// The language of a function scaffolded from an OpenAPI operation, node unless given by the init or create subcommand.
+func openApiLanguage(cmd *cobra.Command) string {
+	if cmd.Name() == "init" || cmd.Name() == "create" || cmd.Name() == "auto" {
+		return "node"
+	}
+	return cmd.Name()
+}
+
+func listLanguages(w io.Writer) {
+	for _, language := range initializers.Languages() {
+		fmt.Fprintln(w, language)
+	}
+}
+
 func init() {
 
 	rootCmd.AddCommand(initCmd)
 
 	utils.CreateInitFlags(initCmd.PersistentFlags())
+	initCmd.PersistentFlags().String("tar-out", "", "write the build context of the function, with its generated Dockerfile, as a tar stream to the file, or to stdout with '-', e.g. 'riff init --tar-out - | docker build -'")
+	initCmd.PersistentFlags().Bool("show-config", false, "print the effective options, including config file values and defaults, and exit without generating")
+	initCmd.Flags().Bool("list-languages", false, "print the supported function languages and exit")
 
+	initCmd.AddCommand(initAutoCmd)
+	initCmd.AddCommand(initBulkCmd)
+	initCmd.AddCommand(initDenoCmd)
 	initCmd.AddCommand(initJavaCmd)
 	initCmd.AddCommand(initNodeCmd)
 	initCmd.AddCommand(initPythonCmd)
 	initCmd.AddCommand(initShellCmd)
 
 	initJavaCmd.Flags().String("handler", "", "the fully qualified class name of the function handler")
-	initJavaCmd.MarkFlagRequired("handler")
+
+	initDenoCmd.Flags().String("handler", "", "the name of the exported function handler (defaults to the default export)")
+
+	initBulkCmd.Flags().Int("parallel", 1, "the number of functions to initialize at once")
 
 	initPythonCmd.Flags().String("handler", "", "the name of the function handler")
-	initPythonCmd.MarkFlagRequired("handler")
 }
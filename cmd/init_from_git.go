@@ -0,0 +1,148 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/projectriff/riff-cli/pkg/initializers/core"
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+)
+
+// Clones a git repository into a directory.
+type Cloner interface {
+	Clone(url string, dir string) error
+}
+
+// How long a shallow clone may take.
+const gitCloneTimeout = 2 * time.Minute
+
+// Clones with the git CLI, fetching the latest commit only.
+type gitCloner struct{}
+
+func (gitCloner) Clone(url string, dir string) error {
+	_, err := osutils.Exec("git", []string{"clone", "--depth", "1", url, dir}, gitCloneTimeout)
+	if err != nil {
+		return errors.New(fmt.Sprintf("cloning %s failed: %v", url, err))
+	}
+	return nil
+}
+
+// Clones the --from-git repositories, replaced in tests by a fake cloner.
+var cloner Cloner = gitCloner{}
+
+// The clone a function is initialized from with --from-git, and the directory its artifacts are copied to.
+type gitFunction struct {
+	clone  string
+	outDir string
+}
+
+var clonedFunction *gitFunction
+
+/*
+ * Clones the repository of --from-git and points the options at the function in it, in --subdir if given.
+ * The clone is named after the repository, which the function is then named after unless in a subdir. The
+ * function path given, if any, becomes the directory the generated artifacts are copied to.
+ */
+func cloneFunction(initOptions *options.InitOptions) (*gitFunction, error) {
+	subdir := filepath.Clean(initOptions.Subdir)
+	if filepath.IsAbs(subdir) || subdir == ".." || strings.HasPrefix(subdir, ".."+string(filepath.Separator)) {
+		return nil, errors.New(fmt.Sprintf("subdir %s must be a directory within the repository", initOptions.Subdir))
+	}
+	tempDir, err := ioutil.TempDir("", "riff-git")
+	if err != nil {
+		return nil, err
+	}
+	function := &gitFunction{clone: filepath.Join(tempDir, repositoryName(initOptions.FromGit)), outDir: initOptions.FunctionPath}
+	if function.outDir == "" {
+		function.outDir = "."
+	}
+	ioutils.Debugf("cloning %s into %s\n", initOptions.FromGit, function.clone)
+	if err = cloner.Clone(initOptions.FromGit, function.clone); err != nil {
+		os.RemoveAll(tempDir)
+		return nil, err
+	}
+	initOptions.FunctionPath = filepath.Join(function.clone, subdir)
+	if !osutils.IsDirectory(initOptions.FunctionPath) {
+		os.RemoveAll(tempDir)
+		return nil, errors.New(fmt.Sprintf("subdir %s not found in %s", initOptions.Subdir, initOptions.FromGit))
+	}
+	return function, nil
+}
+
+// The name of a repository from its URL, such as square for https://github.com/org/square.git.
+func repositoryName(url string) string {
+	name := path.Base(strings.TrimSuffix(strings.TrimRight(url, "/"), ".git"))
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+/*
+ * Copies the artifacts generated in the clone to the output directory, skipping the existing files unless
+ * --force is set, and removes the clone. A dry run has nothing to copy.
+ */
+func (function *gitFunction) finish(initOptions options.InitOptions) error {
+	defer os.RemoveAll(filepath.Dir(function.clone))
+	if initOptions.DryRun {
+		return nil
+	}
+	files, err := core.GeneratedFiles(initOptions.FunctionPath, initOptions)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		rel, err := filepath.Rel(initOptions.FunctionPath, file)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			// Files outside of the function directory, such as a GitHub Actions workflow, stay in the clone.
+			continue
+		}
+		target := filepath.Join(function.outDir, rel)
+		if !initOptions.Force && osutils.FileExists(target) {
+			if err = options.Warning(initOptions.Strict, fmt.Sprintf("skipping existing file %s  - set --force to overwrite.", target)); err != nil {
+				return err
+			}
+			continue
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(target, content, info.Mode()); err != nil {
+			return err
+		}
+		ioutils.Infof("copied %s\n", target)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/cmd/opts"
+	"github.com/projectriff/riff-cli/pkg/initializers"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/stretchr/testify/assert"
+)
+
+// A cloner copying a fixture directory, recording the URL it was given.
+type fakeCloner struct {
+	fixture string
+	url     string
+}
+
+func (c *fakeCloner) Clone(url string, dir string) error {
+	c.url = url
+	return filepath.Walk(c.fixture, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(c.fixture, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dir, rel), 0755)
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dir, rel), content, info.Mode())
+	})
+}
+
+func TestInitFromGit(t *testing.T) {
+	as := assert.New(t)
+	defer func() { cloner = gitCloner{} }()
+	fake := &fakeCloner{fixture: osutils.Path("../test_data/node")}
+	cloner = fake
+
+	outDir, err := ioutil.TempDir("", "riff-from-git")
+	as.NoError(err)
+	defer os.RemoveAll(outDir)
+
+	initOptions := options.InitOptions{FromGit: "https://github.com/org/functions.git", Subdir: "square", FunctionPath: outDir, UserAccount: "me", Version: "0.0.1", RiffVersion: "0.0.6"}
+	function, err := cloneFunction(&initOptions)
+	as.NoError(err)
+	as.Equal("https://github.com/org/functions.git", fake.url)
+	as.Equal("functions", filepath.Base(filepath.Dir(initOptions.FunctionPath)))
+
+	as.NoError(options.ValidateAndCleanInitOptions(&initOptions))
+	as.Equal("square", initOptions.FunctionName)
+	as.NoError(initializers.Initialize(initOptions))
+	as.NoError(function.finish(initOptions))
+
+	for _, file := range []string{"Dockerfile", "square-function.yaml", "square-topics.yaml"} {
+		as.True(osutils.FileExists(filepath.Join(outDir, file)), file)
+	}
+	as.False(osutils.FileExists(filepath.Join(outDir, "square.js")))
+	as.False(osutils.FileExists(function.clone))
+}
+
+func TestInitFromGitWithMissingSubdir(t *testing.T) {
+	as := assert.New(t)
+	defer func() { cloner = gitCloner{} }()
+	cloner = &fakeCloner{fixture: osutils.Path("../test_data/node")}
+
+	initOptions := options.InitOptions{FromGit: "git@github.com:org/functions", Subdir: "cube"}
+	_, err := cloneFunction(&initOptions)
+	as.EqualError(err, "subdir cube not found in git@github.com:org/functions")
+
+	initOptions.Subdir = "../square"
+	_, err = cloneFunction(&initOptions)
+	as.EqualError(err, "subdir ../square must be a directory within the repository")
+}
+
+func TestRepositoryName(t *testing.T) {
+	as := assert.New(t)
+	as.Equal("functions", repositoryName("https://github.com/org/functions.git"))
+	as.Equal("functions", repositoryName("https://github.com/org/functions/"))
+	as.Equal("functions", repositoryName("git@github.com:org/functions.git"))
+	as.Equal("functions", repositoryName("git@example.com:functions.git"))
+}
+
+func TestCreateFromGit(t *testing.T) {
+	as := assert.New(t)
+	defer func() { cloner = gitCloner{} }()
+	fake := &fakeCloner{fixture: osutils.Path("../test_data/node")}
+	cloner = fake
+	defer createCmd.PersistentFlags().Set("from-git", "")
+	defer createCmd.PersistentFlags().Set("subdir", "")
+
+	clearInitOptions()
+	rootCmd.SetArgs([]string{"create", "node", "--dry-run", "--from-git", "https://github.com/org/functions.git", "--subdir", "square",
+		"-a", "square.js", "-v", "0.0.1-snapshot"})
+	_, err := rootCmd.ExecuteC()
+	as.NoError(err)
+
+	as.Equal("https://github.com/org/functions.git", fake.url)
+	as.Equal("square", opts.CreateOptions.FunctionName)
+	as.False(osutils.FileExists(opts.CreateOptions.FunctionPath), "the clone is removed once created")
+}
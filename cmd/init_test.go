@@ -17,6 +17,8 @@
 package cmd
 
 import (
+	"bytes"
+	"io/ioutil"
 	"testing"
 	"github.com/stretchr/testify/assert"
 	"github.com/projectriff/riff-cli/pkg/osutils"
@@ -24,6 +26,7 @@ import (
 	"os"
 	"path/filepath"
 	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/ioutils"
 )
 
 func TestValidateDefaultFunctionResources(t *testing.T) {
@@ -63,6 +66,13 @@ func TestValidateArtifactIsRegularFile(t *testing.T) {
 	as.Contains(err.Error(), "must be a regular file")
 }
 
+func TestValidateArtifactIsPythonPackage(t *testing.T) {
+	filePath := osutils.Path("../test_data/python/package")
+	as := assert.New(t)
+	opts := options.InitOptions{FunctionPath: filePath, Artifact: "greeter"}
+	as.NoError(options.ValidateAndCleanInitOptions(&opts))
+}
+
 func TestValidateArtifactIsInSubDirectory(t *testing.T) {
 	filePath := osutils.Path("../test_data")
 	as := assert.New(t)
@@ -131,3 +141,146 @@ func TestCleanedProtocol(t *testing.T) {
 	as.Equal("grpc",opts.Protocol)
 }
 
+
+func TestArtifactBaseMustBeFileName(t *testing.T) {
+	as := assert.New(t)
+	opts := options.InitOptions{ArtifactBase: osutils.Path("lib/index.js")}
+	err := options.ValidateAndCleanInitOptions(&opts)
+	as.Error(err)
+	as.Contains(err.Error(), "must be a file name")
+}
+
+func TestInvalidSecretName(t *testing.T) {
+	as := assert.New(t)
+	opts := options.InitOptions{Secrets: []string{"Not_Valid"}}
+	err := options.ValidateAndCleanInitOptions(&opts)
+	as.Error(err)
+	as.Contains(err.Error(), "not a valid DNS-1123 subdomain")
+}
+
+func TestPortOutOfRange(t *testing.T) {
+	as := assert.New(t)
+	opts := options.InitOptions{FunctionPath: osutils.Path("../test_data/python/demo"), Port: 70000}
+	err := options.ValidateAndCleanInitOptions(&opts)
+	as.Error(err)
+	as.Equal("port 70000 must be between 1 and 65535", err.Error())
+}
+
+func TestInvalidResourceQuantity(t *testing.T) {
+	as := assert.New(t)
+	opts := options.InitOptions{FunctionPath: osutils.Path("../test_data/python/demo"), CpuRequest: "100m", MemoryLimit: "lots"}
+	err := options.ValidateAndCleanInitOptions(&opts)
+	as.Error(err)
+	as.Equal("memory-limit lots is not a valid resource quantity", err.Error())
+}
+
+func TestSameInputAndOutputFailsOnlyWhenStrict(t *testing.T) {
+	as := assert.New(t)
+	opts := options.InitOptions{FunctionPath: osutils.Path("../test_data/python/demo"), Input: "words", Output: "words"}
+	as.NoError(options.ValidateAndCleanInitOptions(&opts))
+
+	opts.Strict = true
+	err := options.ValidateAndCleanInitOptions(&opts)
+	as.Error(err)
+	as.Contains(err.Error(), "input and output topic are both words")
+}
+
+func TestInitAutoPrintsDetectedLanguage(t *testing.T) {
+	as := assert.New(t)
+	var out bytes.Buffer
+	ioutils.Stdout = &out
+	defer func() { ioutils.Stdout = os.Stdout }()
+
+	opts := options.InitOptions{FunctionPath: osutils.Path("../test_data/node/square"), Version: "0.0.1", DryRun: true}
+	as.NoError(options.ValidateAndCleanInitOptions(&opts))
+	as.NoError(initAuto(opts))
+	as.Contains(out.String(), "Detected node function")
+}
+
+func TestSymlinkedArtifactWithinFunctionDirectory(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-symlink")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square.js"), []byte("module.exports = x => x ** 2\n"), 0644))
+	if err := os.Symlink(filepath.Join(dir, "square.js"), filepath.Join(dir, "index.js")); err != nil {
+		t.Skip("symlinks are not supported:", err)
+	}
+
+	opts := options.InitOptions{FunctionPath: dir, Artifact: "index.js"}
+	as.NoError(options.ValidateAndCleanInitOptions(&opts))
+}
+
+func TestSymlinkedArtifactEscapingFunctionDirectory(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-symlink")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	functionDir := filepath.Join(dir, "square")
+	as.NoError(os.Mkdir(functionDir, 0755))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "shared.js"), []byte("module.exports = x => x ** 2\n"), 0644))
+	if err := os.Symlink(filepath.Join(dir, "shared.js"), filepath.Join(functionDir, "square.js")); err != nil {
+		t.Skip("symlinks are not supported:", err)
+	}
+
+	opts := options.InitOptions{FunctionPath: functionDir, Artifact: "square.js"}
+	err = options.ValidateAndCleanInitOptions(&opts)
+	as.Error(err)
+	as.Contains(err.Error(), "outside of the build context")
+}
+
+func TestInvalidTimeout(t *testing.T) {
+	as := assert.New(t)
+	opts := options.InitOptions{FunctionPath: osutils.Path("../test_data/python/demo"), Timeout: "30"}
+	err := options.ValidateAndCleanInitOptions(&opts)
+	as.Error(err)
+	as.Contains(err.Error(), "timeout 30 is not a valid duration")
+}
+
+func TestFunctionPathOutsideOfContext(t *testing.T) {
+	as := assert.New(t)
+	opts := options.InitOptions{FunctionPath: osutils.Path("../test_data/python/demo"), Context: osutils.Path("../test_data/shell")}
+	err := options.ValidateAndCleanInitOptions(&opts)
+	as.Error(err)
+	as.Contains(err.Error(), "must be within the build context")
+}
+
+func TestListLanguages(t *testing.T) {
+	as := assert.New(t)
+	var out bytes.Buffer
+	listLanguages(&out)
+	as.Equal("deno\njava\nnode\npython\nshell\n", out.String())
+}
+
+func TestUnsupportedApiVersion(t *testing.T) {
+	as := assert.New(t)
+	opts := options.InitOptions{FunctionPath: osutils.Path("../test_data/shell/echo"), ApiVersion: "projectriff.io/v2"}
+	err := options.ValidateAndCleanInitOptions(&opts)
+	as.Error(err)
+	as.Contains(err.Error(), "api version projectriff.io/v2 is unsupported")
+}
+
+func TestInvalidResourceTemplate(t *testing.T) {
+	as := assert.New(t)
+	file, err := ioutil.TempFile("", "resource-template")
+	as.NoError(err)
+	defer os.Remove(file.Name())
+	file.WriteString("metadata:\n  name: {{.Name\n")
+	file.Close()
+
+	opts := options.InitOptions{FunctionPath: osutils.Path("../test_data/shell/echo"), ResourceTemplate: file.Name()}
+	err = options.ValidateAndCleanInitOptions(&opts)
+	as.Error(err)
+	as.Contains(err.Error(), "resource template "+file.Name()+" is invalid")
+}
+
+func TestContentHashTagDoesNotDependOnArtifact(t *testing.T) {
+	as := assert.New(t)
+	initOpts := options.InitOptions{FunctionPath: osutils.Path("../test_data/python/demo"), Artifact: "demo.py", TagStrategy: options.TagStrategyContentHash}
+	as.NoError(options.ValidateAndCleanInitOptions(&initOpts))
+	buildOpts := options.InitOptions{FunctionPath: osutils.Path("../test_data/python/demo"), TagStrategy: options.TagStrategyContentHash}
+	as.NoError(options.ValidateAndCleanInitOptions(&buildOpts))
+
+	as.Len(initOpts.Version, 12)
+	as.Equal(initOpts.Version, buildOpts.Version)
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/projectriff/riff-cli/pkg/docker"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/spf13/cobra"
+)
+
+type InvokeOptions struct {
+	name        string
+	version     string
+	userAccount string
+	registry    string
+	data        string
+	port        int
+	timeout     time.Duration
+	// How long the function may take to reply once it accepted the request, zero for no limit.
+	requestTimeout time.Duration
+}
+
+var invokeOptions InvokeOptions
+
+// Runs docker commands, replaced in tests by a fake docker.
+var dockerExec = docker.Exec
+
+// How often a function that is not ready yet is invoked again.
+var invokeRetryInterval = 250 * time.Millisecond
+
+// The statuses an invoker that is still starting may reply with, the request being retried until --timeout.
+var notReadyStatuses = map[int]bool{
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// invokeCmd represents the invoke command
+var invokeCmd = &cobra.Command{
+	Use:   "invoke",
+	Short: "Invoke a function image locally",
+	Long: `Run the function image locally with docker, post the data to the function once it is ready and print its
+reply. The container is removed afterwards. The function must use the http protocol.`,
+	Example: `  riff invoke -n square -d 7
+    or
+  riff invoke -n square -v 0.0.2 -d 7 --port 8080 --timeout 1m --request-timeout 5s`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return invoke(invokeOptions, os.Stdout)
+	},
+}
+
+/*
+ * Runs the function image with its port published on localhost, posts the data to it until it accepts
+ * the request or the timeout expires, and writes the reply to out. The container is always removed.
+ */
+func invoke(opts InvokeOptions, out io.Writer) error {
+	image := options.ImageName(options.BuildOptions{
+		FunctionName: opts.name,
+		Version:      opts.version,
+		UserAccount:  opts.userAccount,
+		Registry:     opts.registry,
+	})
+	output, err := dockerExec([]string{"run", "-d", "--rm", "-p", fmt.Sprintf("%d:%d", opts.port, opts.port), image})
+	if err != nil {
+		return err
+	}
+	container := strings.TrimSpace(output)
+	defer dockerExec([]string{"rm", "-f", container})
+
+	url := fmt.Sprintf("http://localhost:%d/", opts.port)
+	client := &http.Client{Timeout: opts.requestTimeout}
+	deadline := time.Now().Add(opts.timeout)
+	for {
+		resp, err := client.Post(url, "text/plain", strings.NewReader(opts.data))
+		// The function got the data, posting it again could process it twice.
+		if timeout, ok := err.(net.Error); ok && timeout.Timeout() {
+			return errors.New(fmt.Sprintf("function %s did not reply within %v", opts.name, opts.requestTimeout))
+		}
+		if err == nil {
+			body, readErr := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if readErr != nil {
+				return readErr
+			}
+			if resp.StatusCode == http.StatusOK {
+				_, err = out.Write(body)
+				return err
+			}
+			err = errors.New(fmt.Sprintf("function %s replied %s: %s", opts.name, resp.Status, body))
+			if !notReadyStatuses[resp.StatusCode] {
+				return err
+			}
+		}
+		if time.Now().After(deadline) {
+			return errors.New(fmt.Sprintf("function %s was not ready after %v: %v", opts.name, opts.timeout, err))
+		}
+		time.Sleep(invokeRetryInterval)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(invokeCmd)
+
+	invokeCmd.Flags().StringVarP(&invokeOptions.name, "name", "n", osutils.GetCWDBasePath(), "the name of the function (defaults to the name of the current directory)")
+	invokeCmd.Flags().StringVarP(&invokeOptions.version, "version", "v", "0.0.1", "the version of the function image")
+	invokeCmd.Flags().StringVarP(&invokeOptions.userAccount, "useraccount", "u", osutils.GetCurrentUsername(), "the Docker user account owning the function image")
+	invokeCmd.Flags().StringVar(&invokeOptions.registry, "registry", "", "the registry the function image was pushed to, if any")
+	invokeCmd.Flags().StringVarP(&invokeOptions.data, "data", "d", "", "the data to post to the function")
+	invokeCmd.Flags().IntVar(&invokeOptions.port, "port", options.DefaultPorts["http"], "the port the function listens on, published on localhost")
+	invokeCmd.Flags().DurationVar(&invokeOptions.timeout, "timeout", 30*time.Second, "how long to wait for the function to be ready")
+	invokeCmd.Flags().DurationVar(&invokeOptions.requestTimeout, "request-timeout", 30*time.Second, "how long to wait for the reply of the function once it is ready")
+
+	invokeCmd.MarkFlagRequired("data")
+}
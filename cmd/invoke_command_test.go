@@ -0,0 +1,129 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/projectriff/riff-cli/pkg/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvoke(t *testing.T) {
+	as := assert.New(t)
+	// The test server stands for the function container, still starting when first invoked.
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		n, _ := strconv.Atoi(string(body))
+		w.Write([]byte(strconv.Itoa(n * n)))
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	var commands [][]string
+	dockerExec = func(args []string) (string, error) {
+		commands = append(commands, args)
+		return "f00d\n", nil
+	}
+	defer func() { dockerExec = docker.Exec }()
+
+	opts := InvokeOptions{name: "square", version: "0.0.1", userAccount: "me", data: "7", timeout: time.Second}
+	opts.port, _ = strconv.Atoi(port)
+	var out bytes.Buffer
+	as.NoError(invoke(opts, &out))
+	as.Equal("49", out.String())
+	as.Equal(int32(2), atomic.LoadInt32(&requests), "the data is posted again once the function is ready")
+	as.Equal([][]string{
+		{"run", "-d", "--rm", "-p", port + ":" + port, "me/square:0.0.1"},
+		{"rm", "-f", "f00d"},
+	}, commands)
+}
+
+func TestInvokeFailsOnErrorReply(t *testing.T) {
+	as := assert.New(t)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Error(w, "division by zero", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	dockerExec = func(args []string) (string, error) { return "f00d\n", nil }
+	defer func() { dockerExec = docker.Exec }()
+
+	opts := InvokeOptions{name: "square", version: "0.0.1", userAccount: "me", data: "7", timeout: time.Second}
+	opts.port, _ = strconv.Atoi(port)
+	err := invoke(opts, &bytes.Buffer{})
+	as.EqualError(err, "function square replied 500 Internal Server Error: division by zero\n")
+	as.Equal(int32(1), atomic.LoadInt32(&requests), "the data is posted once")
+}
+
+func TestInvokeTimesOutWaitingForTheReply(t *testing.T) {
+	as := assert.New(t)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	dockerExec = func(args []string) (string, error) { return "f00d\n", nil }
+	defer func() { dockerExec = docker.Exec }()
+
+	opts := InvokeOptions{name: "square", version: "0.0.1", userAccount: "me", data: "7", timeout: time.Second, requestTimeout: 100 * time.Millisecond}
+	opts.port, _ = strconv.Atoi(port)
+	err := invoke(opts, &bytes.Buffer{})
+	as.EqualError(err, "function square did not reply within 100ms")
+	as.Equal(int32(1), atomic.LoadInt32(&requests), "the data is posted once")
+}
+
+func TestInvokeTimesOutWhenNotReady(t *testing.T) {
+	as := assert.New(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	as.NoError(err)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	var commands [][]string
+	dockerExec = func(args []string) (string, error) {
+		commands = append(commands, args)
+		return "f00d\n", nil
+	}
+	defer func() { dockerExec = docker.Exec }()
+
+	opts := InvokeOptions{name: "square", version: "0.0.1", userAccount: "me", data: "7", timeout: 100 * time.Millisecond}
+	opts.port, _ = strconv.Atoi(port)
+	err = invoke(opts, &bytes.Buffer{})
+	as.Error(err)
+	as.Contains(err.Error(), "was not ready after 100ms")
+	as.Equal([]string{"rm", "-f", "f00d"}, commands[len(commands)-1], "the container is removed")
+}
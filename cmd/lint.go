@@ -0,0 +1,160 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/projectriff/riff-cli/cmd/utils"
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Lint the Dockerfile of a function",
+	Long: `Check the Dockerfile of a function directory against Dockerfile best practices. When hadolint is found
+  on the PATH, it lints the Dockerfile and its findings are reported. Otherwise a few built-in checks are run:
+  base images must be pinned to a tag or digest, and files ADDed from a URL must have a checksum.`,
+	Example: `riff lint -f ./square`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagset := cmd.Flags()
+		if err := applyConfig(flagset); err != nil {
+			return err
+		}
+		var initOptions options.InitOptions
+		utils.MergeInitOptions(*flagset, &initOptions)
+		initOptions.Offline = offline(cmd)
+		if len(args) == 1 && initOptions.FunctionPath == "" {
+			initOptions.FunctionPath = args[0]
+		} else if len(args) > 0 {
+			return errors.New(fmt.Sprintf("invalid argument(s) %v", args))
+		}
+		if initOptions.FunctionPath == "" {
+			initOptions.FunctionPath = "."
+		}
+
+		cmd.SilenceUsage = true
+		return lintDockerfile(filepath.Join(options.FunctionDir(initOptions), "Dockerfile"))
+	},
+}
+
+// Finds hadolint on the PATH, replaced in tests.
+var lookPathHadolint = func() (string, error) {
+	return exec.LookPath("hadolint")
+}
+
+func lintDockerfile(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return errors.New(fmt.Sprintf("%s is missing, run 'riff init' to generate it", path))
+	}
+	if err != nil {
+		return err
+	}
+
+	if hadolint, err := lookPathHadolint(); err == nil {
+		return runHadolint(hadolint, path)
+	}
+	ioutils.Debugf("hadolint not found, running the built-in checks\n")
+	findings := checkDockerfile(path, string(content))
+	for _, finding := range findings {
+		ioutils.Warn(finding)
+	}
+	if len(findings) > 0 {
+		return errors.New(fmt.Sprintf("%s has %d finding(s)", path, len(findings)))
+	}
+	ioutils.Infof("%s follows the built-in checks, install hadolint for a thorough lint\n", path)
+	return nil
+}
+
+// Runs hadolint, which prints its findings and exits non-zero when there are any.
+func runHadolint(hadolint string, path string) error {
+	cmd := exec.Command(hadolint, path)
+	cmd.Stdout = ioutils.Stdout
+	cmd.Stderr = ioutils.Stderr
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return errors.New(fmt.Sprintf("hadolint reported findings in %s (exit code %d)", path, status.ExitStatus()))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	ioutils.Infof("%s passes hadolint\n", path)
+	return nil
+}
+
+/*
+ * The built-in checks, for when hadolint is not installed: every base image is pinned to a tag other than
+ * latest, or to a digest, and every file ADDed from a URL is verified with a checksum.
+ */
+func checkDockerfile(path string, content string) []error {
+	var findings []error
+	stages := map[string]bool{}
+	for i, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "FROM":
+			image := fields[1]
+			// Earlier stages, scratch and images named by build args are not pulled as they are written.
+			if !stages[image] && image != "scratch" && !strings.Contains(image, "$") && !pinned(image) {
+				findings = append(findings, errors.New(fmt.Sprintf("%s:%d: base image %s is not pinned, give it a tag other than latest or a digest", path, i+1, image)))
+			}
+			if len(fields) == 4 && strings.ToUpper(fields[2]) == "AS" {
+				stages[fields[3]] = true
+			}
+		case "ADD":
+			checksum := false
+			for _, field := range fields[1:] {
+				if strings.HasPrefix(field, "--checksum=") {
+					checksum = true
+				}
+				if options.IsURL(field) && !checksum {
+					findings = append(findings, errors.New(fmt.Sprintf("%s:%d: %s is added without a checksum, set ADD --checksum", path, i+1, field)))
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func pinned(image string) bool {
+	if strings.Contains(image, "@") {
+		return true
+	}
+	tag := strings.LastIndex(image, ":")
+	return tag > strings.LastIndex(image, "/") && image[tag+1:] != "latest"
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	utils.CreateInitFlags(lintCmd.Flags())
+}
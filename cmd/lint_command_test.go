@@ -0,0 +1,79 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintFlagsUnpinnedBaseImages(t *testing.T) {
+	as := assert.New(t)
+
+	findings := checkDockerfile("Dockerfile", `FROM projectriff/node-function-invoker AS invoker
+FROM example/runtime:latest
+FROM invoker
+FROM scratch
+FROM projectriff/java-function-invoker:0.0.6
+FROM projectriff/python3-function-invoker@sha256:0123456789abcdef
+FROM localhost:5000/invoker
+`)
+	var messages []string
+	for _, finding := range findings {
+		messages = append(messages, finding.Error())
+	}
+	as.Equal([]string{
+		"Dockerfile:1: base image projectriff/node-function-invoker is not pinned, give it a tag other than latest or a digest",
+		"Dockerfile:2: base image example/runtime:latest is not pinned, give it a tag other than latest or a digest",
+		"Dockerfile:7: base image localhost:5000/invoker is not pinned, give it a tag other than latest or a digest",
+	}, messages)
+}
+
+func TestLintFlagsUrlsAddedWithoutChecksum(t *testing.T) {
+	as := assert.New(t)
+
+	findings := checkDockerfile("Dockerfile", `FROM projectriff/java-function-invoker:0.0.6
+ADD https://example.com/square.jar /functions/square.jar
+ADD --checksum=sha256:0123456789abcdef https://example.com/cube.jar /functions/cube.jar
+ADD square.jar /functions/square.jar
+`)
+	if as.Len(findings, 1) {
+		as.EqualError(findings[0], "Dockerfile:2: https://example.com/square.jar is added without a checksum, set ADD --checksum")
+	}
+}
+
+func TestLintFallsBackToBuiltInChecks(t *testing.T) {
+	as := assert.New(t)
+	defer func(look func() (string, error)) { lookPathHadolint = look }(lookPathHadolint)
+	lookPathHadolint = func() (string, error) { return "", errors.New("hadolint not found") }
+
+	dir, err := ioutil.TempDir("", "riff-lint")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	dockerfile := filepath.Join(dir, "Dockerfile")
+
+	as.NoError(ioutil.WriteFile(dockerfile, []byte("FROM projectriff/node-function-invoker:0.0.6\nADD square.js /functions/square.js\n"), 0644))
+	as.NoError(lintDockerfile(dockerfile))
+
+	as.NoError(ioutil.WriteFile(dockerfile, []byte("FROM projectriff/node-function-invoker\n"), 0644))
+	as.EqualError(lintDockerfile(dockerfile), dockerfile+" has 1 finding(s)")
+}
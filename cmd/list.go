@@ -35,7 +35,7 @@ var listCmd = &cobra.Command{
 
 		cmdArgs := []string{"get", "functions"}
 
-		output, err := kubectl.ExecForString(cmdArgs)
+		output, err := withRetries(func() (string, error) { return kubectl.ExecForString(cmdArgs) })
 
 		if err != nil {
 			ioutils.Errorf("Error: %v\n", err)
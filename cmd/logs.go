@@ -52,10 +52,10 @@ will tail the logs from the 'sidecar' container for the function 'myfunc'
 
 		cmdArgs := []string{"get", "pod", "-l", "function=" + logsOptions.function, "-o", "jsonpath={.items[0].metadata.name}"}
 
-		output, err := kubectl.ExecForString(cmdArgs)
+		output, err := withRetries(func() (string, error) { return kubectl.ExecForString(cmdArgs) })
 
 		if err != nil {
-			ioutils.Errorf("Error %v - Function %v may not be currently active\n%", err, logsOptions.function)
+			ioutils.Errorf("Error %v - Function %v may not be currently active\n", err, logsOptions.function)
 			return
 		}
 
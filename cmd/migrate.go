@@ -0,0 +1,200 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/projectriff/riff-cli/pkg/functions"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/spf13/cobra"
+)
+
+type MigrateOptions struct {
+	path   string
+	dryRun bool
+}
+
+var migrateOptions MigrateOptions
+
+/*
+ * A rewrite of a Dockerfile line generated by an older riff to the conventions of the current templates.
+ * The line is replaced by the lines returned, if any, and left as is otherwise.
+ */
+type dockerfileMigration struct {
+	description string
+	rewrite     func(line string, previous []string) []string
+}
+
+// A FUNCTION_URI naming the jar and class itself, instead of through build args.
+var javaFunctionUriRegexp = regexp.MustCompile(`^ENV FUNCTION_URI file://(/functions/[^?\s$]+\.jar)\?handler=([\w.$]+)$`)
+
+// A FUNCTION_URI naming the python module and handler itself, instead of through build args.
+var pythonFunctionUriRegexp = regexp.MustCompile(`^ENV FUNCTION_URI file:///([\w.]+)\?handler=(\w+)$`)
+
+// An artifact added to the root directory, without naming it in the image.
+var addToRootRegexp = regexp.MustCompile(`^(ADD|COPY) (\S+) /$`)
+
+var dockerfileMigrations = []dockerfileMigration{
+	{
+		description: "name the jar and class of FUNCTION_URI with build args",
+		rewrite: func(line string, previous []string) []string {
+			match := javaFunctionUriRegexp.FindStringSubmatch(line)
+			if match == nil {
+				return nil
+			}
+			return []string{
+				"ARG FUNCTION_JAR=" + match[1],
+				"ARG FUNCTION_CLASS=" + match[2],
+				"ENV FUNCTION_URI file://${FUNCTION_JAR}?handler=${FUNCTION_CLASS}",
+			}
+		},
+	},
+	{
+		description: "name the module and handler of FUNCTION_URI with build args",
+		rewrite: func(line string, previous []string) []string {
+			match := pythonFunctionUriRegexp.FindStringSubmatch(line)
+			if match == nil {
+				return nil
+			}
+			return []string{
+				"ARG FUNCTION_MODULE=" + match[1],
+				"ARG FUNCTION_HANDLER=" + match[2],
+				"ENV FUNCTION_URI file:///${FUNCTION_MODULE}?handler=${FUNCTION_HANDLER}",
+			}
+		},
+	},
+	{
+		description: "name the artifact added to the root directory",
+		rewrite: func(line string, previous []string) []string {
+			match := addToRootRegexp.FindStringSubmatch(line)
+			// requirements.txt is installed from the root directory, where it is still added.
+			if match == nil || path.Base(match[2]) == "requirements.txt" {
+				return nil
+			}
+			return []string{fmt.Sprintf("%s %s /%s", match[1], match[2], path.Base(match[2]))}
+		},
+	},
+	{
+		description: "let the installed requirements be rebuilt with the CACHE_BUST build arg",
+		rewrite: func(line string, previous []string) []string {
+			if !strings.HasPrefix(line, "RUN ") || !strings.Contains(line, "pip install") {
+				return nil
+			}
+			// other instructions, such as ARG PIP_INDEX_CREDENTIALS, may follow the ARG before the RUN
+			for _, instruction := range previous {
+				if strings.HasPrefix(instruction, "ARG CACHE_BUST") {
+					return nil
+				}
+			}
+			return []string{"ARG CACHE_BUST", line}
+		},
+	},
+}
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate a Dockerfile generated by an older riff to the current conventions",
+	Long: `Rewrite the lines of a Dockerfile generated by an older riff that the current templates generate differently,
+such as a FUNCTION_URI naming the function itself instead of through build args. Only the lines recognized as
+generated are rewritten, so other edits of the Dockerfile are kept.`,
+	Example: `  riff migrate -f function/square --dry-run`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := functions.AbsPath(migrateOptions.path)
+		if err != nil {
+			return err
+		}
+		if !osutils.IsDirectory(dir) {
+			dir = filepath.Dir(dir)
+		}
+
+		cmd.SilenceUsage = true
+		return migrateFunction(dir, migrateOptions.dryRun, os.Stdout)
+	},
+}
+
+/*
+ * Migrates the Dockerfile of the function in dir, printing each change. With dryRun, the changes are only
+ * shown.
+ */
+func migrateFunction(dir string, dryRun bool, out io.Writer) error {
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	content, err := ioutil.ReadFile(dockerfile)
+	if os.IsNotExist(err) {
+		return errors.New(fmt.Sprintf("%s is missing, run 'riff init' to generate it", dockerfile))
+	}
+	if err != nil {
+		return err
+	}
+
+	migrated, changes := migrateDockerfile(displayPath(dir, dockerfile), string(content), out)
+	if changes == 0 {
+		fmt.Fprintf(out, "%s is up to date\n", displayPath(dir, dockerfile))
+	}
+	if changes == 0 || dryRun {
+		return nil
+	}
+	info, err := os.Stat(dockerfile)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dockerfile, []byte(migrated), info.Mode())
+}
+
+// The migrated Dockerfile and the number of lines rewritten, each rewrite being shown as it is made.
+func migrateDockerfile(name string, content string, out io.Writer) (string, int) {
+	var lines []string
+	changes := 0
+	for i, line := range strings.Split(content, "\n") {
+		migrated := false
+		for _, migration := range dockerfileMigrations {
+			rewritten := migration.rewrite(line, lines)
+			if rewritten == nil {
+				continue
+			}
+			fmt.Fprintf(out, "%s:%d: %s\n- %s\n", name, i+1, migration.description, line)
+			for _, migratedLine := range rewritten {
+				fmt.Fprintf(out, "+ %s\n", migratedLine)
+			}
+			lines = append(lines, rewritten...)
+			changes++
+			migrated = true
+			break
+		}
+		if !migrated {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n"), changes
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().StringVarP(&migrateOptions.path, "filepath", "f", "", "path or directory of the function, if a file is specified then the file's directory will be used (defaults to the current directory)")
+	migrateCmd.Flags().BoolVarP(&migrateOptions.dryRun, "dry-run", "", false, "show the changes without making them")
+}
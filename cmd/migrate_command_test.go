@@ -0,0 +1,124 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A Dockerfile of a python function generated by riff 0.0.2, with a custom LABEL.
+const oldPythonDockerfile = `FROM projectriff/python2-function-invoker:0.0.2
+LABEL maintainer="squares@example.com"
+ADD ./demo.py /
+ADD ./requirements.txt /
+RUN  pip install --upgrade pip && pip install -r /requirements.txt
+ENV FUNCTION_URI file:///demo.py?handler=process
+`
+
+const migratedPythonDockerfile = `FROM projectriff/python2-function-invoker:0.0.2
+LABEL maintainer="squares@example.com"
+ADD ./demo.py /demo.py
+ADD ./requirements.txt /
+ARG CACHE_BUST
+RUN  pip install --upgrade pip && pip install -r /requirements.txt
+ARG FUNCTION_MODULE=demo.py
+ARG FUNCTION_HANDLER=process
+ENV FUNCTION_URI file:///${FUNCTION_MODULE}?handler=${FUNCTION_HANDLER}
+`
+
+func functionWithDockerfile(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "riff-migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestMigratePythonDockerfile(t *testing.T) {
+	as := assert.New(t)
+	dir := functionWithDockerfile(t, oldPythonDockerfile)
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	as.NoError(migrateFunction(dir, false, &out))
+	content, err := ioutil.ReadFile(filepath.Join(dir, "Dockerfile"))
+	as.NoError(err)
+	as.Equal(migratedPythonDockerfile, string(content))
+	as.Contains(out.String(), "Dockerfile:6: name the module and handler of FUNCTION_URI with build args\n- ENV FUNCTION_URI file:///demo.py?handler=process\n+ ARG FUNCTION_MODULE=demo.py\n")
+
+	out.Reset()
+	as.NoError(migrateFunction(dir, false, &out))
+	as.Equal("Dockerfile is up to date\n", out.String())
+}
+
+func TestMigrateCurrentPythonDockerfile(t *testing.T) {
+	as := assert.New(t)
+	dir := functionWithDockerfile(t, "FROM projectriff/python2-function-invoker:0.0.6\n"+
+		"ARG FUNCTION_MODULE=demo.py\n"+
+		"ARG FUNCTION_HANDLER=process\n"+
+		"ADD ./demo.py /demo.py\n"+
+		"ADD ./requirements.txt /\n"+
+		"ARG CACHE_BUST\n"+
+		"ARG PIP_INDEX_CREDENTIALS\n"+
+		"RUN  pip install --upgrade pip && pip install --index-url \"https://pypi.example.com/simple\" -r /requirements.txt\n"+
+		"ENV FUNCTION_URI file:///${FUNCTION_MODULE}?handler=${FUNCTION_HANDLER}\n")
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	as.NoError(migrateFunction(dir, false, &out))
+	as.Equal("Dockerfile is up to date\n", out.String())
+}
+
+func TestMigrateJavaDockerfile(t *testing.T) {
+	as := assert.New(t)
+	dir := functionWithDockerfile(t, "FROM projectriff/java-function-invoker:0.0.2\n"+
+		"ADD target/greeter-1.0.0.jar /functions/greeter-1.0.0.jar\n"+
+		"ENV FUNCTION_URI file:///functions/greeter-1.0.0.jar?handler=functions.Greeter\n")
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	as.NoError(migrateFunction(dir, false, &out))
+	content, err := ioutil.ReadFile(filepath.Join(dir, "Dockerfile"))
+	as.NoError(err)
+	as.Equal("FROM projectriff/java-function-invoker:0.0.2\n"+
+		"ADD target/greeter-1.0.0.jar /functions/greeter-1.0.0.jar\n"+
+		"ARG FUNCTION_JAR=/functions/greeter-1.0.0.jar\n"+
+		"ARG FUNCTION_CLASS=functions.Greeter\n"+
+		"ENV FUNCTION_URI file://${FUNCTION_JAR}?handler=${FUNCTION_CLASS}\n", string(content))
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	as := assert.New(t)
+	dir := functionWithDockerfile(t, oldPythonDockerfile)
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	as.NoError(migrateFunction(dir, true, &out))
+	content, err := ioutil.ReadFile(filepath.Join(dir, "Dockerfile"))
+	as.NoError(err)
+	as.Equal(oldPythonDockerfile, string(content))
+	as.Contains(out.String(), "Dockerfile:3: name the artifact added to the root directory\n- ADD ./demo.py /\n+ ADD ./demo.py /demo.py\n")
+}
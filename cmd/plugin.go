@@ -0,0 +1,53 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/projectriff/riff-cli/pkg/plugins"
+)
+
+// pluginCmd represents the plugin command
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manage riff CLI plugins",
+	Long: `Plugins are executables named 'riff-<name>' found on the PATH. They are invoked as 'riff <name> [args]'
+when <name> is not a built-in riff command.`,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the plugins found on the PATH",
+	Run: func(cmd *cobra.Command, args []string) {
+		found := plugins.Discover(os.Getenv("PATH"))
+		if len(found) == 0 {
+			fmt.Println("No plugins found")
+			return
+		}
+		for _, plugin := range found {
+			fmt.Printf("%-20s %s\n", plugin.Name, plugin.Path)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withFakePluginPath(t *testing.T, plugins ...string) func() {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts require a POSIX shell")
+	}
+	dir, err := ioutil.TempDir("", "riff-plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plugin := range plugins {
+		ioutil.WriteFile(filepath.Join(dir, plugin), []byte("#!/bin/sh\nexit 0\n"), 0755)
+	}
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir)
+	return func() {
+		os.Setenv("PATH", path)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestUnknownCommandResolvesToPlugin(t *testing.T) {
+	as := assert.New(t)
+	defer withFakePluginPath(t, "riff-foo")()
+
+	plugin, args, found := pluginForArgs([]string{"foo", "--bar", "baz"})
+	as.True(found)
+	as.Equal("foo", plugin.Name)
+	as.Equal([]string{"--bar", "baz"}, args)
+}
+
+func TestBuiltinCommandTakesPrecedenceOverPlugin(t *testing.T) {
+	as := assert.New(t)
+	defer withFakePluginPath(t, "riff-init", "riff-foo")()
+
+	_, _, found := pluginForArgs([]string{"init", "--dry-run"})
+	as.False(found)
+
+	_, _, found = pluginForArgs([]string{"--config", "foo"})
+	as.False(found)
+
+	_, _, found = pluginForArgs([]string{"missing"})
+	as.False(found)
+}
+
+func TestPluginAfterGlobalFlags(t *testing.T) {
+	as := assert.New(t)
+	defer withFakePluginPath(t, "riff-foo")()
+
+	plugin, args, found := pluginForArgs([]string{"--log-level", "debug", "--offline", "--config=.riff.yaml", "foo", "--bar", "baz"})
+	as.True(found)
+	as.Equal("foo", plugin.Name)
+	as.Equal([]string{"--bar", "baz"}, args)
+
+	_, _, found = pluginForArgs([]string{"--log-level", "debug", "init", "--dry-run"})
+	as.False(found)
+
+	_, _, found = pluginForArgs([]string{"--unknown", "foo"})
+	as.False(found)
+}
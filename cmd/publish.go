@@ -54,14 +54,14 @@ will post 'hello' to the 'greetings' topic and wait for a reply.
 	Run: func(cmd *cobra.Command, args []string) {
 
 		cmdArgs := []string{"get", "svc", "-l", "component=http-gateway", "-o", "json"}
-		output, err := kubectl.ExecForBytes(cmdArgs)
+		output, err := withRetries(func() (string, error) { return kubectl.ExecForString(cmdArgs) })
 
 		if err != nil {
 			ioutils.Errorf("Error querying http-gateway %v\n %v\n", err, output)
 			return
 		}
 
-		parser := jsonpath.NewParser(output)
+		parser := jsonpath.NewParser([]byte(output))
 
 		portType := parser.Value(`$.items[0].spec.type+`)
 
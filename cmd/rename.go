@@ -0,0 +1,185 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/projectriff/riff-cli/pkg/functions"
+	"github.com/projectriff/riff-cli/pkg/initializers/core"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/spf13/cobra"
+)
+
+type RenameOptions struct {
+	from   string
+	to     string
+	path   string
+	dryRun bool
+}
+
+var renameOptions RenameOptions
+
+// The words of generated files a function name may appear as, such as in 'me/square:0.0.1' or 'name: square'.
+var nameTokenRegexp = regexp.MustCompile(`[-\w.]+`)
+
+// renameCmd represents the rename command
+var renameCmd = &cobra.Command{
+	Use:   "rename",
+	Short: "Rename a function across its generated artifacts",
+	Long: `Rename a function across the artifacts generated by 'riff init': the resource definitions, Dockerfile,
+Helm chart, devcontainer configuration and GitHub Actions workflow. The files named after the function are
+renamed, and the references to the function in them, including the topics named after it, are rewritten.`,
+	Example: `  riff rename --to cube
+    or
+  riff rename --from square --to cube -f function/square --dry-run`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if renameOptions.from == "" {
+			var err error
+			renameOptions.from, err = functions.FunctionNameFromPath(renameOptions.path)
+			if err != nil {
+				return err
+			}
+		}
+		dir, err := functions.AbsPath(renameOptions.path)
+		if err != nil {
+			return err
+		}
+		if !osutils.IsDirectory(dir) {
+			dir = filepath.Dir(dir)
+		}
+
+		cmd.SilenceUsage = true
+		return renameFunction(dir, renameOptions.from, renameOptions.to, renameOptions.dryRun, os.Stdout)
+	},
+}
+
+/*
+ * Renames the generated artifacts of the function in dir from one name to the other, and rewrites the
+ * references to the function they contain. All files are checked before any is written. With dryRun, the
+ * changes are only shown.
+ */
+func renameFunction(dir string, from string, to string, dryRun bool, out io.Writer) error {
+	if err := options.ValidateFunctionName(to); err != nil {
+		return err
+	}
+	if from == to {
+		return errors.New(fmt.Sprintf("function %s already has that name", from))
+	}
+	files, err := core.GeneratedFiles(dir, options.InitOptions{FunctionName: from})
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New(fmt.Sprintf("no generated artifacts found for function %s in %s", from, dir))
+	}
+
+	renamed := make([]string, len(files))
+	contents := make([]string, len(files))
+	for i, file := range files {
+		renamed[i] = renamedPath(file, from, to)
+		if renamed[i] != file && osutils.FileExists(renamed[i]) {
+			return errors.New(fmt.Sprintf("cannot rename %s, %s already exists", file, renamed[i]))
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		contents[i] = string(content)
+	}
+
+	for i, file := range files {
+		content := nameTokenRegexp.ReplaceAllStringFunc(contents[i], func(token string) string {
+			if token == from {
+				return to
+			}
+			// A kustomization lists the resource files, named after the function.
+			if strings.HasPrefix(token, from+"-") && strings.HasSuffix(token, ".yaml") {
+				return renamedPath(token, from, to)
+			}
+			return token
+		})
+		if renamed[i] != file {
+			fmt.Fprintf(out, "rename %s to %s\n", displayPath(dir, file), displayPath(dir, renamed[i]))
+		}
+		if dryRun {
+			showChanges(displayPath(dir, renamed[i]), contents[i], content, out)
+			continue
+		}
+		if renamed[i] == file && content == contents[i] {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(renamed[i], []byte(content), info.Mode()); err != nil {
+			return err
+		}
+		if renamed[i] != file {
+			if err = os.Remove(file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// The path of a file named after the function, such as square-function.yaml, once the function is renamed.
+func renamedPath(file string, from string, to string) string {
+	base := filepath.Base(file)
+	if strings.HasPrefix(base, from+"-") || strings.HasPrefix(base, from+".") {
+		return filepath.Join(filepath.Dir(file), to+strings.TrimPrefix(base, from))
+	}
+	return file
+}
+
+func showChanges(name string, before string, after string, out io.Writer) {
+	beforeLines := strings.Split(before, "\n")
+	afterLines := strings.Split(after, "\n")
+	for i := range beforeLines {
+		if beforeLines[i] != afterLines[i] {
+			fmt.Fprintf(out, "%s:%d\n- %s\n+ %s\n", name, i+1, beforeLines[i], afterLines[i])
+		}
+	}
+}
+
+// The path relative to the function directory, or as is when outside of it, like a GitHub Actions workflow.
+func displayPath(dir string, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+
+	renameCmd.Flags().StringVarP(&renameOptions.from, "from", "", "", "the current name of the function (defaults to the name of the function directory)")
+	renameCmd.Flags().StringVarP(&renameOptions.to, "to", "", "", "the new name of the function")
+	renameCmd.Flags().StringVarP(&renameOptions.path, "filepath", "f", "", "path or directory of the function, if a file is specified then the file's directory will be used (defaults to the current directory)")
+	renameCmd.Flags().BoolVarP(&renameOptions.dryRun, "dry-run", "", false, "show the changes without making them")
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSquareFunction(as *assert.Assertions) string {
+	dir, err := ioutil.TempDir("", "riff-rename")
+	as.NoError(err)
+	for file, content := range map[string]string{
+		"Dockerfile":           "FROM projectriff/node-function-invoker:0.0.5\nENV FUNCTION_URI /functions/square.js\n",
+		"square-function.yaml": "kind: Function\nmetadata:\n  name: square\nspec:\n  input: square\n  container:\n    image: me/square:0.0.1\n",
+		"square-topics.yaml":   "kind: Topic\nmetadata:\n  name: square\n",
+		"kustomization.yaml":   "kind: Kustomization\nresources:\n- square-topics.yaml\n- square-function.yaml\n",
+		"square.js":            "module.exports = square => square ** 2\n",
+	} {
+		as.NoError(ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestRenameFunction(t *testing.T) {
+	as := assert.New(t)
+	dir := writeSquareFunction(as)
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	as.NoError(renameFunction(dir, "square", "cube", false, &out))
+
+	as.False(osutils.FileExists(filepath.Join(dir, "square-function.yaml")))
+	as.False(osutils.FileExists(filepath.Join(dir, "square-topics.yaml")))
+	function, err := ioutil.ReadFile(filepath.Join(dir, "cube-function.yaml"))
+	as.NoError(err)
+	as.Equal("kind: Function\nmetadata:\n  name: cube\nspec:\n  input: cube\n  container:\n    image: me/cube:0.0.1\n", string(function))
+	topics, err := ioutil.ReadFile(filepath.Join(dir, "cube-topics.yaml"))
+	as.NoError(err)
+	as.Equal("kind: Topic\nmetadata:\n  name: cube\n", string(topics))
+	kustomization, err := ioutil.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+	as.NoError(err)
+	as.Equal("kind: Kustomization\nresources:\n- cube-topics.yaml\n- cube-function.yaml\n", string(kustomization))
+
+	// The function source is not a generated artifact, and keeps its name.
+	dockerfile, err := ioutil.ReadFile(filepath.Join(dir, "Dockerfile"))
+	as.NoError(err)
+	as.Contains(string(dockerfile), "/functions/square.js")
+	source, err := ioutil.ReadFile(filepath.Join(dir, "square.js"))
+	as.NoError(err)
+	as.Equal("module.exports = square => square ** 2\n", string(source))
+}
+
+func TestRenameFunctionDryRun(t *testing.T) {
+	as := assert.New(t)
+	dir := writeSquareFunction(as)
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	as.NoError(renameFunction(dir, "square", "cube", true, &out))
+	as.Contains(out.String(), "rename square-topics.yaml to cube-topics.yaml\n")
+	as.Contains(out.String(), "cube-function.yaml:7\n-     image: me/square:0.0.1\n+     image: me/cube:0.0.1\n")
+	as.True(osutils.FileExists(filepath.Join(dir, "square-function.yaml")))
+	as.False(osutils.FileExists(filepath.Join(dir, "cube-function.yaml")))
+}
+
+func TestRenameFunctionToInvalidName(t *testing.T) {
+	as := assert.New(t)
+	dir := writeSquareFunction(as)
+	defer os.RemoveAll(dir)
+
+	as.EqualError(renameFunction(dir, "square", "Cube", false, &bytes.Buffer{}), "function name Cube is not a valid DNS-1123 subdomain")
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+)
+
+// The delay before the first retry, doubled for each further attempt.
+const retryBackoff = 2 * time.Second
+
+// Error output from docker and kubectl that indicates a failure worth retrying.
+var transientErrorPatterns = []string{
+	"connection refused",
+	"connection reset by peer",
+	"i/o timeout",
+	"TLS handshake timeout",
+	"net/http: request canceled",
+	"unexpected EOF",
+	"502 Bad Gateway",
+	"503 Service Unavailable",
+	"504 Gateway Timeout",
+	"429 Too Many Requests",
+	"toomanyrequests",
+	"the server is currently unable to handle the request",
+}
+
+/*
+ * Runs an external command up to attempts times, waiting backoff before the first retry and doubling it
+ * for each following one. Only transient failures are retried, anything else is returned right away.
+ */
+func runWithRetry(cmd func() (string, error), attempts int, backoff time.Duration) (string, error) {
+	for attempt := 1; ; attempt++ {
+		out, err := cmd()
+		if err == nil || attempt >= attempts || !isTransient(err) {
+			return out, err
+		}
+		ioutils.Warnf("transient failure, retrying in %v (attempt %d of %d)\n", backoff, attempt+1, attempts)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func withRetries(cmd func() (string, error)) (string, error) {
+	return runWithRetry(cmd, retries+1, retryBackoff)
+}
+
+func isTransient(err error) bool {
+	if err == context.DeadlineExceeded {
+		return true
+	}
+	message := err.Error()
+	if execErr, ok := err.(*osutils.ExecError); ok {
+		message = execErr.Stderr
+	}
+	for _, pattern := range transientErrorPatterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRunner struct {
+	failures []error
+	calls    int
+}
+
+func (f *fakeRunner) run() (string, error) {
+	f.calls++
+	if f.calls <= len(f.failures) {
+		return "", f.failures[f.calls-1]
+	}
+	return "done", nil
+}
+
+func transientError() error {
+	return &osutils.ExecError{Err: errors.New("exit status 1"), Stderr: "dial tcp 10.0.0.1:443: connect: connection refused"}
+}
+
+func TestRetryUntilSuccess(t *testing.T) {
+	as := assert.New(t)
+	runner := &fakeRunner{failures: []error{transientError(), transientError()}}
+
+	out, err := runWithRetry(runner.run, 3, 0)
+	as.NoError(err)
+	as.Equal("done", out)
+	as.Equal(3, runner.calls)
+}
+
+func TestRetryGivesUpAfterAttempts(t *testing.T) {
+	as := assert.New(t)
+	runner := &fakeRunner{failures: []error{transientError(), transientError()}}
+
+	_, err := runWithRetry(runner.run, 2, 0)
+	as.Error(err)
+	as.Equal(2, runner.calls)
+}
+
+func TestNoRetryForPermanentFailure(t *testing.T) {
+	as := assert.New(t)
+	compileError := &osutils.ExecError{Err: errors.New("exit status 1"), Stderr: "The command '/bin/sh -c pip install -r /requirements.txt' returned a non-zero code: 1"}
+	runner := &fakeRunner{failures: []error{compileError}}
+
+	_, err := runWithRetry(runner.run, 3, 0)
+	as.Equal(compileError, err)
+	as.Equal(1, runner.calls)
+}
@@ -19,14 +19,25 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"os/exec"
+	"strings"
+	"syscall"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/projectriff/riff-cli/pkg/plugins"
+	"github.com/projectriff/riff-cli/pkg/ioutils"
 )
 
 var cfgFile string
 
+var logLevel string
+
+var logFormat string
+
+var retries int
+
 var RIFF_VERSION = "0.0.2"
 
 // rootCmd represents the base command when called without any subcommands
@@ -35,25 +46,93 @@ var rootCmd = &cobra.Command{
 	Short: "Commands for creating and managing function resources",
 	Long: `riff is a CLI tool that creates and manages function resources for the riff FaaS platform https://projectriff.io/`,
 	SilenceErrors: true,
+	// Commands with their own PersistentPreRun record their usage themselves, as cobra only runs the closest one.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		recordUsage(cmd)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	if plugin, args, ok := pluginForArgs(os.Args[1:]); ok {
+		if err := plugin.Exec(args); err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+					os.Exit(status.ExitStatus())
+				}
+			}
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
+/*
+ * An unknown subcommand is delegated to a riff-<subcommand> executable found on the PATH, if any.
+ * Built-in commands always take precedence over plugins. Global flags before the subcommand, such as
+ * --log-level debug, are skipped to find it, and the plugin is passed the arguments that follow it.
+ */
+func pluginForArgs(args []string) (plugins.Plugin, []string, bool) {
+	args = skipGlobalFlags(args)
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || isBuiltinCommand(args[0]) {
+		return plugins.Plugin{}, nil, false
+	}
+	plugin, found := plugins.Find(os.Getenv("PATH"), args[0])
+	return plugin, args[1:], found
+}
+
+// skipGlobalFlags drops the leading root persistent flags and their values, up to the first other argument.
+func skipGlobalFlags(args []string) []string {
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		flag := rootCmd.PersistentFlags().Lookup(strings.SplitN(args[0][2:], "=", 2)[0])
+		if flag == nil {
+			return args
+		}
+		if !strings.Contains(args[0], "=") && flag.NoOptDefVal == "" && len(args) > 1 {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	return args
+}
+
+func isBuiltinCommand(name string) bool {
+	if name == "help" {
+		return true
+	}
+	for _, command := range rootCmd.Commands() {
+		if command.Name() == name {
+			return true
+		}
+		for _, alias := range command.Aliases {
+			if alias == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func init() {
 
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(initLogging, initConfig)
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.riff.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "project config file, layered over the global $HOME/.config/riff/config.yaml (default is ./.riff.yaml, else $HOME/.riff.yaml)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "the minimum level of messages to log (debug, info, warn, error)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "the format of log messages (text, or json for one JSON object per line)")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", 2, "the number of times a docker or kubectl command is retried after a transient failure")
+	rootCmd.PersistentFlags().Bool("offline", false, "skip checks that need the network, such as fetching a remote base image allowlist")
+	rootCmd.PersistentFlags().StringVar(&usageLog, "usage-log", "", "a local file to append the commands and languages used to, as JSON lines")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -61,29 +140,49 @@ func init() {
 
 }
 
+// offline reads the root --offline flag, which is only found in the flags inherited by the running command.
+func offline(cmd *cobra.Command) bool {
+	offline, _ := cmd.Flags().GetBool("offline")
+	return offline
+}
+
+// initLogging applies the log level and format flags.
+func initLogging() {
+	level, err := ioutils.ParseLevel(logLevel)
+	if err != nil {
+		ioutils.Error(err)
+		os.Exit(1)
+	}
+	ioutils.SetLogLevel(level)
+
+	if err := ioutils.SetLogFormat(logFormat); err != nil {
+		ioutils.Error(err)
+		os.Exit(1)
+	}
+}
+
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Find home directory.
-		home, err := homedir.Dir()
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
-
-		// Search config in home directory with functionName ".riff" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigName(".riff")
+	// Find home directory.
+	home, err := homedir.Dir()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 
+	// Environment variables are read as RIFF_<NAME>. The unprefixed names read by earlier versions are
+	// still honored when no prefixed one is set, see configValue.
+	viper.SetEnvPrefix("riff")
 	viper.AutomaticEnv() // read in environment variables that match
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	if err := loadConfig(configFiles(home, dir, cfgFile)); err != nil {
+		ioutils.Error(err)
+		os.Exit(1)
 	}
 }
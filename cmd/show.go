@@ -0,0 +1,84 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/projectriff/riff-cli/cmd/utils"
+	"github.com/projectriff/riff-cli/pkg/initializers"
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show what riff would generate for a function",
+}
+
+var showDockerfileCmd = &cobra.Command{
+	Use:   "dockerfile",
+	Short: "Print the Dockerfile riff would generate for a function",
+	Long: `Print the Dockerfile 'riff init' would now generate for the function, without writing anything. The
+  options are taken from the config file and the flags, and the language is detected from the function file.
+  The output can be compared with a hand-edited Dockerfile.`,
+	Example: `riff show dockerfile -f ./square | diff ./square/Dockerfile -`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// The Dockerfile alone goes to stdout.
+		ioutils.Stdout = os.Stderr
+		flagset := cmd.Flags()
+		if err := applyConfig(flagset); err != nil {
+			return err
+		}
+		var initOptions options.InitOptions
+		utils.MergeInitOptions(*flagset, &initOptions)
+		initOptions.Offline = offline(cmd)
+		if len(args) == 1 && initOptions.FunctionPath == "" {
+			initOptions.FunctionPath = args[0]
+		} else if len(args) > 0 {
+			return errors.New(fmt.Sprintf("invalid argument(s) %v", args))
+		}
+
+		cmd.SilenceUsage = true
+		return showDockerfile(initOptions, os.Stdout)
+	},
+}
+
+// Prints the Dockerfile as it would be written.
+func showDockerfile(initOptions options.InitOptions, out io.Writer) error {
+	if err := options.ValidateAndCleanInitOptions(&initOptions); err != nil {
+		return err
+	}
+	dockerfile, err := initializers.GenerateDockerFile(initOptions)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(out, strings.TrimLeft(dockerfile, "\n"))
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+	showCmd.AddCommand(showDockerfileCmd)
+	utils.CreateInitFlags(showDockerfileCmd.Flags())
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShowDockerfileOfNodeFunction(t *testing.T) {
+	as := assert.New(t)
+	dir := initializedFunction(t)
+	defer os.RemoveAll(filepath.Dir(dir))
+	generated, err := ioutil.ReadFile(filepath.Join(dir, "Dockerfile"))
+	as.NoError(err)
+
+	var out bytes.Buffer
+	opts := options.InitOptions{FunctionPath: dir, UserAccount: "me", Version: "0.0.1", RiffVersion: "0.0.5"}
+	as.NoError(showDockerfile(opts, &out))
+	as.Equal(string(generated), out.String())
+	as.Contains(out.String(), "FROM projectriff/node-function-invoker:0.0.5\n")
+}
+
+func TestShowDockerfileWithoutFunction(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-show")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	as.Error(showDockerfile(options.InitOptions{FunctionPath: dir, RiffVersion: "0.0.5"}, &out))
+	as.Empty(out.String())
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/spf13/cobra"
+)
+
+// The file usage entries are appended to, when given with --usage-log.
+var usageLog string
+
+// Whether the command being run was recorded, as chained commands may try again.
+var usageRecorded bool
+
+// An entry of the usage log, one JSON object per line.
+type UsageEntry struct {
+	Timestamp string `json:"timestamp"`
+	Command   string `json:"command"`
+	Language  string `json:"language,omitempty"`
+}
+
+// The commands whose subcommands are named after a language.
+var languageCommands = []string{"init", "create"}
+
+/*
+ * Appends the command being run to the --usage-log, if given, once. The log is only ever written locally,
+ * and failing to write it does not fail the command.
+ */
+func recordUsage(cmd *cobra.Command) {
+	if usageLog == "" || usageRecorded || !cmd.HasParent() {
+		return
+	}
+	usageRecorded = true
+	if err := appendUsage(usageLog, cmd, time.Now()); err != nil {
+		ioutils.Warnf("cannot write usage log %s: %v\n", usageLog, err)
+	}
+}
+
+func appendUsage(path string, cmd *cobra.Command, now time.Time) error {
+	names := strings.Fields(cmd.CommandPath())[1:]
+	entry := UsageEntry{Timestamp: now.UTC().Format(time.RFC3339), Command: strings.Join(names, " ")}
+	if len(names) == 2 {
+		for _, command := range languageCommands {
+			if names[0] == command && names[1] != "bulk" {
+				entry.Command, entry.Language = command, names[1]
+			}
+		}
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageIsAppended(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-usage")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, "usage.jsonl")
+
+	root := &cobra.Command{Use: "riff"}
+	initCommand := &cobra.Command{Use: "init"}
+	nodeCommand := &cobra.Command{Use: "node"}
+	buildCommand := &cobra.Command{Use: "build"}
+	initCommand.AddCommand(nodeCommand)
+	root.AddCommand(initCommand, buildCommand)
+
+	now := time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+	as.NoError(appendUsage(log, nodeCommand, now))
+	as.NoError(appendUsage(log, buildCommand, now.Add(time.Minute)))
+
+	content, err := ioutil.ReadFile(log)
+	as.NoError(err)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	as.Len(lines, 2)
+
+	var entry UsageEntry
+	as.NoError(json.Unmarshal([]byte(lines[0]), &entry))
+	as.Equal(UsageEntry{Timestamp: "2018-03-01T12:00:00Z", Command: "init", Language: "node"}, entry)
+	as.Equal(`{"timestamp":"2018-03-01T12:01:00Z","command":"build"}`, lines[1])
+}
+
+func TestUsageIsRecordedOnce(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-usage")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	usageLog = filepath.Join(dir, "usage.jsonl")
+	defer func() { usageLog, usageRecorded = "", false }()
+
+	rootCmd.PersistentPreRun(applyCmd, nil)
+	rootCmd.PersistentPreRun(buildCmd, nil)
+
+	content, err := ioutil.ReadFile(usageLog)
+	as.NoError(err)
+	as.Equal(1, strings.Count(string(content), "\n"))
+	as.Contains(string(content), `"command":"apply"`)
+}
@@ -91,9 +91,6 @@ func CommandChain(commands ... *cobra.Command) *cobra.Command {
 	persistentPreRun := func(cmd *cobra.Command, args []string) {
 
 		for _, command := range commands {
-			for ; command.Root() != nil && command.Root().PersistentPreRun != nil; {
-				command = command.Root()
-			}
 			if command.PersistentPreRun != nil {
 				command.PersistentPreRun(command, args)
 			}
@@ -102,9 +99,6 @@ func CommandChain(commands ... *cobra.Command) *cobra.Command {
 
 	persistentPreRunE := func(cmd *cobra.Command, args []string) error {
 		for _, command := range commands {
-			for ; command.Root() != nil && command.Root().PersistentPreRun != nil; {
-				command = command.Root()
-			}
 			if command.PersistentPreRunE != nil {
 				err := command.PersistentPreRunE(command, args)
 				if err != nil {
@@ -118,9 +112,6 @@ func CommandChain(commands ... *cobra.Command) *cobra.Command {
 	persistentPostRun := func(cmd *cobra.Command, args []string) {
 
 		for _, command := range commands {
-			for ; command.Root() != nil && command.Root().PersistentPreRun != nil; {
-				command = command.Root()
-			}
 			if command.PersistentPostRun != nil {
 				command.PersistentPostRun(command, args)
 			}
@@ -129,9 +120,6 @@ func CommandChain(commands ... *cobra.Command) *cobra.Command {
 
 	persistentPostRunE := func(cmd *cobra.Command, args []string) error {
 		for _, command := range commands {
-			for ; command.Root() != nil && command.Root().PersistentPreRun != nil; {
-				command = command.Root()
-			}
 			if command.PersistentPostRunE != nil {
 				err := command.PersistentPostRunE(command, args)
 				if err != nil {
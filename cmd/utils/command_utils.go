@@ -79,6 +79,20 @@ from the 'square' directory
 
 to {{.Result}}.`
 
+const baseDenoDescription = `{{.Process}} the function based on the function source code specified as the filename, using the name
+and version specified for the function image repository and tag. The function file is a TypeScript or JavaScript module,
+run by Deno. For example, if you have a directory named 'square' containing a function 'square.ts', you can simply type :
+
+riff {{.Command}} -f square
+
+or
+
+riff {{.Command}}
+
+from the 'square' directory
+
+to {{.Result}}.`
+
 const basePythonDescription = `{{.Process}} the function based on the function source code specified as the filename, handler, name, artifact
   and version specified for the function image repository and tag. 
 For example, type:
@@ -110,6 +124,10 @@ func InitNodeCmdLong() string {
 	return createCmdLong(baseNodeDescription, LongVals{Process: initDefinition, Command: "init node", Result: initResult})
 }
 
+func InitDenoCmdLong() string {
+	return createCmdLong(baseDenoDescription, LongVals{Process: initDefinition, Command: "init deno", Result: initResult})
+}
+
 func InitPythonCmdLong() string {
 	return createCmdLong(basePythonDescription, LongVals{Process: initDefinition, Command: "init python", Result: initResult})
 }
@@ -130,6 +148,10 @@ func CreateNodeCmdLong() string {
 	return createCmdLong(baseNodeDescription, LongVals{Process: createDefinition, Command: "create node", Result: createResult})
 }
 
+func CreateDenoCmdLong() string {
+	return createCmdLong(baseDenoDescription, LongVals{Process: createDefinition, Command: "create deno", Result: createResult})
+}
+
 func CreatePythonCmdLong() string {
 	return createCmdLong(basePythonDescription, LongVals{Process: createDefinition, Command: "create python", Result: createResult})
 }
@@ -17,6 +17,8 @@
 package utils
 
 import (
+	"fmt"
+	"strings"
 	"github.com/projectriff/riff-cli/pkg/osutils"
 	"github.com/spf13/pflag"
 	"github.com/projectriff/riff-cli/pkg/options"
@@ -52,10 +54,70 @@ func CreateInitFlags(flagset *pflag.FlagSet) {
 	setInputFlag(flagset)
 	setOutputFlag(flagset)
 	setArtifactFlag(flagset)
+	setArtifactBaseFlag(flagset)
 	setRiffVersionFlag(flagset)
 	setUserAccountFlag(flagset)
+	setRegistryFlag(flagset)
 	setForceFlag(flagset)
 	setDryRunFlag(flagset)
+	setSecretFlag(flagset)
+	setConfigMapFlag(flagset)
+	setGitHubActionsFlag(flagset)
+	setPortFlag(flagset)
+	setBaseImageAllowlistFlag(flagset)
+	setResourceFlags(flagset)
+	setUriParamFlag(flagset)
+	setStrictFlag(flagset)
+	setHelmFlag(flagset)
+	setImageRefOutFlag(flagset)
+	setTemplateDirFlag(flagset)
+	setTimeoutFlag(flagset)
+	setContextFlag(flagset)
+	setGitIgnoreFlag(flagset)
+	setBaseImageFlag(flagset)
+	setRunAsUserFlag(flagset)
+	setTagStrategyFlag(flagset)
+	setApiVersionFlag(flagset)
+	setPipIndexUrlFlag(flagset)
+	setResourceTemplateFlag(flagset)
+	setExecutableModeFlag(flagset)
+	setInlineFlag(flagset)
+	setResourceNameFlag(flagset)
+	setDevContainerFlag(flagset)
+	setTopicProviderFlag(flagset)
+	setNamespaceFlag(flagset)
+	setHookFlags(flagset)
+	setMinimalFlag(flagset)
+	setOnlyFlag(flagset)
+	setInstallCmdFlag(flagset)
+	setNoInstallFlag(flagset)
+	setCloudEventsFlag(flagset)
+	setImageVariantFlag(flagset)
+	setKedaFlags(flagset)
+	setUseCopyFlag(flagset)
+	setAnnotationFlag(flagset)
+	setShellFlag(flagset)
+	setQuietDepsFlag(flagset)
+	setExplainDetectionFlag(flagset)
+	setFunctionDirFlag(flagset)
+	setProbeFlags(flagset)
+	setKustomizeFlag(flagset)
+	setOutputFormatFlag(flagset)
+	setFromGitFlags(flagset)
+	setManifestOutFlag(flagset)
+	setTektonFlag(flagset)
+	setExecFormFlags(flagset)
+	setExplainUriFlag(flagset)
+	setArgoCDFlags(flagset)
+	setScaleFlags(flagset)
+	setBuildToolFlag(flagset)
+	setLintSourceFlag(flagset)
+	setKeyedFlag(flagset)
+	setOpenApiFlags(flagset)
+	setReplicasFlag(flagset)
+	setEnvFileFlags(flagset)
+	setVerifyHandlerFlag(flagset)
+	setNonRootFlag(flagset)
 }
 
 func CreateBuildFlags(flagset *pflag.FlagSet) {
@@ -65,12 +127,23 @@ func CreateBuildFlags(flagset *pflag.FlagSet) {
 	setRiffVersionFlag(flagset)
 	setDryRunFlag(flagset)
 	setPushFlag(flagset)
+	setNoCacheFlag(flagset)
+	setCacheBustFlag(flagset)
 	setUserAccountFlag(flagset)
+	setRegistryFlag(flagset)
+	setContextFlag(flagset)
+	setTagStrategyFlag(flagset)
+	setPlatformFlag(flagset)
+	setPreviewBuildFlag(flagset)
 }
 
 func CreateApplyFlags(flagset *pflag.FlagSet) {
 	setFilePathFlag(flagset)
 	setDryRunFlag(flagset)
+	setDiffFlag(flagset)
+	setReplaceTopicsFlag(flagset)
+	setWaitFlag(flagset)
+	setServerDryRunFlag(flagset)
 }
 
 func MergeInitOptions(flagset pflag.FlagSet, opts *options.InitOptions) {
@@ -95,18 +168,256 @@ func MergeInitOptions(flagset pflag.FlagSet, opts *options.InitOptions) {
 	if opts.Artifact == "" {
 		opts.Artifact, _ = flagset.GetString("artifact")
 	}
+	if opts.ArtifactBase == "" {
+		opts.ArtifactBase, _ = flagset.GetString("artifact-base")
+	}
 	if opts.RiffVersion == "" {
 		opts.RiffVersion, _ = flagset.GetString("riff-version")
 	}
 	if opts.UserAccount == "" {
 		opts.UserAccount, _ = flagset.GetString("useraccount")
 	}
+	if opts.Registry == "" {
+		opts.Registry, _ = flagset.GetString("registry")
+	}
 	if opts.DryRun == false {
 		opts.DryRun, _ = flagset.GetBool("dry-run")
 	}
 	if opts.Force == false {
 		opts.Force, _ = flagset.GetBool("force")
 	}
+	if len(opts.Secrets) == 0 {
+		opts.Secrets, _ = flagset.GetStringArray("secret")
+	}
+	if len(opts.ConfigMaps) == 0 {
+		opts.ConfigMaps, _ = flagset.GetStringArray("configmap")
+	}
+	if opts.GitHubActions == false {
+		opts.GitHubActions, _ = flagset.GetBool("github-actions")
+	}
+	if opts.Port == 0 {
+		opts.Port, _ = flagset.GetInt("port")
+	}
+	if opts.BaseImageAllowlist == "" {
+		opts.BaseImageAllowlist, _ = flagset.GetString("base-image-allowlist")
+	}
+	if opts.CpuRequest == "" {
+		opts.CpuRequest, _ = flagset.GetString("cpu-request")
+	}
+	if opts.CpuLimit == "" {
+		opts.CpuLimit, _ = flagset.GetString("cpu-limit")
+	}
+	if opts.MemoryRequest == "" {
+		opts.MemoryRequest, _ = flagset.GetString("memory-request")
+	}
+	if opts.MemoryLimit == "" {
+		opts.MemoryLimit, _ = flagset.GetString("memory-limit")
+	}
+	if len(opts.UriParams) == 0 {
+		opts.UriParams, _ = flagset.GetStringArray("uri-param")
+	}
+	if opts.Strict == false {
+		opts.Strict, _ = flagset.GetBool("strict")
+	}
+	if opts.Helm == false {
+		opts.Helm, _ = flagset.GetBool("helm")
+	}
+	if opts.ImageRefOut == "" {
+		opts.ImageRefOut, _ = flagset.GetString("image-ref-out")
+	}
+	if opts.TemplateDir == "" {
+		opts.TemplateDir, _ = flagset.GetString("template-dir")
+	}
+	if opts.Timeout == "" {
+		opts.Timeout, _ = flagset.GetString("timeout")
+	}
+	if opts.Context == "" {
+		opts.Context, _ = flagset.GetString("context")
+	}
+	if opts.GitIgnore == false {
+		opts.GitIgnore, _ = flagset.GetBool("gitignore")
+	}
+	if opts.BaseImage == "" {
+		opts.BaseImage, _ = flagset.GetString("base-image")
+	}
+	if opts.RunAsUser == "" {
+		opts.RunAsUser, _ = flagset.GetString("run-as-user")
+	}
+	if opts.NonRoot == false {
+		opts.NonRoot, _ = flagset.GetBool("non-root")
+	}
+	if opts.TagStrategy == "" {
+		opts.TagStrategy, _ = flagset.GetString("tag-strategy")
+	}
+	if opts.ApiVersion == "" {
+		opts.ApiVersion, _ = flagset.GetString("api-version")
+	}
+	if opts.PipIndexUrl == "" {
+		opts.PipIndexUrl, _ = flagset.GetString("pip-index-url")
+	}
+	if opts.ResourceTemplate == "" {
+		opts.ResourceTemplate, _ = flagset.GetString("resource-template")
+	}
+	if opts.ExecutableMode == "" {
+		opts.ExecutableMode, _ = flagset.GetString("executable-mode")
+	}
+	if opts.Inline == false {
+		opts.Inline, _ = flagset.GetBool("inline")
+	}
+	if opts.ResourceName == "" {
+		opts.ResourceName, _ = flagset.GetString("resource-name")
+	}
+	if opts.DevContainer == false {
+		opts.DevContainer, _ = flagset.GetBool("devcontainer")
+	}
+	if opts.TopicProvider == "" {
+		opts.TopicProvider, _ = flagset.GetString("topic-provider")
+	}
+	if opts.Namespace == "" {
+		opts.Namespace, _ = flagset.GetString("namespace")
+	}
+	if opts.PreHook == "" {
+		opts.PreHook, _ = flagset.GetString("pre-hook")
+	}
+	if opts.PostHook == "" {
+		opts.PostHook, _ = flagset.GetString("post-hook")
+	}
+	if opts.Minimal == false {
+		opts.Minimal, _ = flagset.GetBool("minimal")
+	}
+	if opts.Only == "" {
+		opts.Only, _ = flagset.GetString("only")
+	}
+	if opts.InstallCmd == "" {
+		opts.InstallCmd, _ = flagset.GetString("install-cmd")
+	}
+	if opts.NoInstall == false {
+		opts.NoInstall, _ = flagset.GetBool("no-install")
+	}
+	if opts.CloudEvents == false {
+		opts.CloudEvents, _ = flagset.GetBool("cloudevents")
+	}
+	if opts.ImageVariant == "" {
+		opts.ImageVariant, _ = flagset.GetString("image-variant")
+	}
+	if opts.Keda == false {
+		opts.Keda, _ = flagset.GetBool("keda")
+	}
+	if opts.MinReplicas == 0 {
+		opts.MinReplicas, _ = flagset.GetInt("min-replicas")
+	}
+	if opts.MaxReplicas == 0 {
+		opts.MaxReplicas, _ = flagset.GetInt("max-replicas")
+	}
+	if opts.UseCopy == false {
+		opts.UseCopy, _ = flagset.GetBool("use-copy")
+	}
+	if len(opts.Annotations) == 0 {
+		opts.Annotations, _ = flagset.GetStringArray("annotation")
+	}
+	if opts.Shell == "" {
+		opts.Shell, _ = flagset.GetString("shell")
+	}
+	if opts.QuietDeps == false {
+		opts.QuietDeps, _ = flagset.GetBool("quiet-deps")
+	}
+	if opts.ExplainDetection == false {
+		opts.ExplainDetection, _ = flagset.GetBool("explain-detection")
+	}
+	if opts.ImageFunctionDir == "" {
+		opts.ImageFunctionDir, _ = flagset.GetString("function-dir")
+	}
+	if opts.ReadinessPath == "" {
+		opts.ReadinessPath, _ = flagset.GetString("readiness-path")
+	}
+	if opts.LivenessPath == "" {
+		opts.LivenessPath, _ = flagset.GetString("liveness-path")
+	}
+	if opts.ProbeInitialDelay == 0 {
+		opts.ProbeInitialDelay, _ = flagset.GetInt("probe-initial-delay")
+	}
+	if opts.ProbePeriod == 0 {
+		opts.ProbePeriod, _ = flagset.GetInt("probe-period")
+	}
+	if opts.ProbeTimeout == 0 {
+		opts.ProbeTimeout, _ = flagset.GetInt("probe-timeout")
+	}
+	if opts.Kustomize == false {
+		opts.Kustomize, _ = flagset.GetBool("kustomize")
+	}
+	if opts.OutputFormat == "" {
+		opts.OutputFormat, _ = flagset.GetString("output-format")
+	}
+	if opts.FromGit == "" {
+		opts.FromGit, _ = flagset.GetString("from-git")
+	}
+	if opts.Subdir == "" {
+		opts.Subdir, _ = flagset.GetString("subdir")
+	}
+	if opts.ManifestOut == "" {
+		opts.ManifestOut, _ = flagset.GetString("manifest-out")
+	}
+	if opts.Tekton == false {
+		opts.Tekton, _ = flagset.GetBool("tekton")
+	}
+	if opts.Entrypoint == "" {
+		opts.Entrypoint, _ = flagset.GetString("entrypoint")
+	}
+	if opts.Cmd == "" {
+		opts.Cmd, _ = flagset.GetString("cmd")
+	}
+	if opts.ExplainUri == false {
+		opts.ExplainUri, _ = flagset.GetBool("explain-uri")
+	}
+	if opts.ArgoCD == false {
+		opts.ArgoCD, _ = flagset.GetBool("argocd")
+	}
+	if opts.RepoUrl == "" {
+		opts.RepoUrl, _ = flagset.GetString("repo-url")
+	}
+	if opts.RepoPath == "" {
+		opts.RepoPath, _ = flagset.GetString("path")
+	}
+	if opts.MinScale == 0 {
+		opts.MinScale, _ = flagset.GetInt("min-scale")
+	}
+	if opts.MaxScale == 0 {
+		opts.MaxScale, _ = flagset.GetInt("max-scale")
+	}
+	if opts.BuildTool == "" {
+		opts.BuildTool, _ = flagset.GetString("build-tool")
+	}
+	if opts.TarOut == "" {
+		opts.TarOut, _ = flagset.GetString("tar-out")
+	}
+	if opts.LintSource == false {
+		opts.LintSource, _ = flagset.GetBool("lint-source")
+	}
+	if opts.Keyed == false {
+		opts.Keyed, _ = flagset.GetBool("keyed")
+	}
+	if opts.FromOpenApi == "" {
+		opts.FromOpenApi, _ = flagset.GetString("from-openapi")
+	}
+	if opts.Operation == "" {
+		opts.Operation, _ = flagset.GetString("operation")
+	}
+	if opts.Replicas == nil && flagset.Changed("replicas") {
+		replicas, _ := flagset.GetInt("replicas")
+		opts.Replicas = &replicas
+	}
+	if opts.EnvFile == false {
+		opts.EnvFile, _ = flagset.GetBool("env-file")
+	}
+	if len(opts.Env) == 0 {
+		opts.Env, _ = flagset.GetStringArray("env")
+	}
+	if opts.VerifyHandler == false {
+		opts.VerifyHandler, _ = flagset.GetBool("verify-handler")
+	}
+	if opts.RiffVersionPinned == false {
+		opts.RiffVersionPinned = flagset.Changed("riff-version")
+	}
 }
 
 func MergeBuildOptions(flagset pflag.FlagSet, opts *options.CreateOptions) {
@@ -125,12 +436,33 @@ func MergeBuildOptions(flagset pflag.FlagSet, opts *options.CreateOptions) {
 	if opts.UserAccount == "" {
 		opts.UserAccount, _ = flagset.GetString("useraccount")
 	}
+	if opts.Registry == "" {
+		opts.Registry, _ = flagset.GetString("registry")
+	}
 	if opts.DryRun == false {
 		opts.DryRun, _ = flagset.GetBool("dry-run")
 	}
 	if opts.Push == false {
 		opts.Push, _ = flagset.GetBool("push")
 	}
+	if opts.NoCache == false {
+		opts.NoCache, _ = flagset.GetBool("no-cache")
+	}
+	if opts.CacheBust == "" {
+		opts.CacheBust, _ = flagset.GetString("cache-bust")
+	}
+	if opts.Context == "" {
+		opts.Context, _ = flagset.GetString("context")
+	}
+	if opts.TagStrategy == "" {
+		opts.TagStrategy, _ = flagset.GetString("tag-strategy")
+	}
+	if opts.Platform == "" {
+		opts.Platform, _ = flagset.GetString("platform")
+	}
+	if opts.PreviewBuild == false {
+		opts.PreviewBuild, _ = flagset.GetBool("preview-build")
+	}
 }
 
 func MergeApplyOptions(flagset pflag.FlagSet, opts *options.CreateOptions) {
@@ -140,6 +472,18 @@ func MergeApplyOptions(flagset pflag.FlagSet, opts *options.CreateOptions) {
 	if opts.DryRun == false {
 		opts.DryRun, _ = flagset.GetBool("dry-run")
 	}
+	if opts.Diff == false {
+		opts.Diff, _ = flagset.GetBool("diff")
+	}
+	if opts.ReplaceTopics == false {
+		opts.ReplaceTopics, _ = flagset.GetBool("replace-topics")
+	}
+	if opts.Wait == false {
+		opts.Wait, _ = flagset.GetBool("wait")
+	}
+	if opts.ServerDryRun == false {
+		opts.ServerDryRun, _ = flagset.GetBool("server-dry-run")
+	}
 }
 
 func GetHandler(cmd *cobra.Command) string {
@@ -168,6 +512,30 @@ func setFilePathFlag(flagset *pflag.FlagSet) {
 	}
 }
 
+func setDiffFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "diff") {
+		flagset.Bool("diff", false, "show how the cluster resources would change instead of applying them, exiting non-zero when they differ")
+	}
+}
+
+func setReplaceTopicsFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "replace-topics") {
+		flagset.Bool("replace-topics", false, "delete and recreate the topics that already exist, instead of leaving them as they are")
+	}
+}
+
+func setWaitFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "wait") {
+		flagset.Bool("wait", false, "wait for the topics to be ready before applying the functions")
+	}
+}
+
+func setServerDryRunFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "server-dry-run") {
+		flagset.Bool("server-dry-run", false, "validate the resources against the schemas installed in the cluster, without applying them")
+	}
+}
+
 func setDryRunFlag(flagset *pflag.FlagSet) {
 	if !flagDefined(flagset, "dry-run") {
 		flagset.Bool("dry-run", defaults.dryRun, "print generated function artifacts content to stdout only")
@@ -186,6 +554,12 @@ func setUserAccountFlag(flagset *pflag.FlagSet) {
 	}
 }
 
+func setRegistryFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "registry") {
+		flagset.String("registry", "", "the Docker registry host prefixed to the image name (defaults to Docker Hub)")
+	}
+}
+
 func setProtocolFlag(flagset *pflag.FlagSet) {
 	if !flagDefined(flagset, "protocol") {
 		flagset.StringP("protocol", "p", "", "the protocol to use for function invocations (defaults to 'stdio' for shell and python, to 'http' for java and node)")
@@ -194,12 +568,12 @@ func setProtocolFlag(flagset *pflag.FlagSet) {
 
 func setInputFlag(flagset *pflag.FlagSet) {
 	if !flagDefined(flagset, "input") {
-		flagset.StringP("input", "i", "", "the name of the input topic (defaults to function name)")
+		flagset.StringP("input", "i", "", "the name of the input topic, or a comma separated list of input topics (defaults to function name)")
 	}
 }
 func setOutputFlag(flagset *pflag.FlagSet) {
 	if !flagDefined(flagset, "output") {
-		flagset.StringP("output", "o", "", "the name of the output topic (optional)")
+		flagset.StringP("output", "o", "", "the name of the output topic, or a comma separated list of output topics (optional)")
 	}
 }
 
@@ -209,18 +583,447 @@ func setArtifactFlag(flagset *pflag.FlagSet) {
 	}
 }
 
+func setArtifactBaseFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "artifact-base") {
+		flagset.String("artifact-base", "", "the file name given to the artifact inside the function image (defaults to the base name of the artifact)")
+	}
+}
+
 func setForceFlag(flagset *pflag.FlagSet) {
 	if !flagDefined(flagset, "force") {
 		flagset.Bool("force", defaults.force, "overwrite existing functions artifacts")
 	}
 }
 
+func setSecretFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "secret") {
+		flagset.StringArray("secret", []string{}, "the name of a secret exposed to the function as environment variables (can be repeated)")
+	}
+}
+
+func setConfigMapFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "configmap") {
+		flagset.StringArray("configmap", []string{}, "the name of a configmap exposed to the function as environment variables (can be repeated)")
+	}
+}
+
+func setGitHubActionsFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "github-actions") {
+		flagset.Bool("github-actions", false, "generate a GitHub Actions workflow that builds and pushes the function image")
+	}
+}
+
+func setPortFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "port") {
+		flagset.Int("port", 0, "the port the function container listens on (defaults to 8080 for http and 10382 for grpc)")
+	}
+}
+
+func setBaseImageAllowlistFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "base-image-allowlist") {
+		flagset.String("base-image-allowlist", "", "path or http(s) URL of a file listing the base images the generated Dockerfile may use, one per line")
+	}
+}
+
+func setResourceFlags(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "cpu-request") {
+		flagset.String("cpu-request", "", "the CPU requested by the function container, e.g. 100m")
+	}
+	if !flagDefined(flagset, "cpu-limit") {
+		flagset.String("cpu-limit", "", "the maximum CPU the function container may use, e.g. 500m")
+	}
+	if !flagDefined(flagset, "memory-request") {
+		flagset.String("memory-request", "", "the memory requested by the function container, e.g. 128Mi")
+	}
+	if !flagDefined(flagset, "memory-limit") {
+		flagset.String("memory-limit", "", "the maximum memory the function container may use, e.g. 256Mi")
+	}
+}
+
+func setUriParamFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "uri-param") {
+		flagset.StringArray("uri-param", []string{}, "a key=value parameter appended to the FUNCTION_URI query string (can be repeated)")
+	}
+}
+
+func setStrictFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "strict") {
+		flagset.Bool("strict", false, "treat warnings (input topic same as output, skipped existing files) as errors")
+	}
+}
+
+func setHelmFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "helm") {
+		flagset.Bool("helm", false, "generate a Helm chart for the function in the chart directory")
+	}
+}
+
+func setImageRefOutFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "image-ref-out") {
+		flagset.String("image-ref-out", "", "write the resolved image reference to the given file, also in dry-run mode")
+	}
+}
+
+func setTemplateDirFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "template-dir") {
+		flagset.String("template-dir", "", "a directory of *.tmpl Dockerfile templates; Dockerfile.tmpl replaces the generated Dockerfile and can include the others")
+	}
+}
+
+func setTimeoutFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "timeout") {
+		flagset.String("timeout", "", "the maximum duration of a function invocation, e.g. 30s")
+	}
+}
+
+func setContextFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "context") {
+		flagset.String("context", "", "the docker build context, a directory containing the function (defaults to the function directory)")
+	}
+}
+
+func setGitIgnoreFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "gitignore") {
+		flagset.Bool("gitignore", false, "add the language's build output to the function's .gitignore, creating it if needed")
+	}
+}
+
+func setBaseImageFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "base-image") {
+		flagset.String("base-image", "", "the image the function image is built FROM, replacing the invoker image for the riff version, e.g. a digest-pinned reference")
+	}
+}
+
+func setRunAsUserFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "run-as-user") {
+		flagset.String("run-as-user", "", "the numeric user id the function container runs as")
+	}
+}
+
+func setNonRootFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "non-root") {
+		flagset.Bool("non-root", false, "run the function container as a non-root user (defaults to user id "+options.DefaultNonRootUser+")")
+	}
+}
+
+func setTagStrategyFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "tag-strategy") {
+		flagset.String("tag-strategy", options.TagStrategyVersion, "how the image is tagged: 'version' uses --version, 'content-hash' a hash of the function source and dependency files")
+	}
+}
+
+func setApiVersionFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "api-version") {
+		flagset.String("api-version", options.SupportedApiVersions[0], "the apiVersion of the generated riff resources, one of "+strings.Join(options.SupportedApiVersions, ", "))
+	}
+}
+
+func setPipIndexUrlFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "pip-index-url") {
+		flagset.String("pip-index-url", "", "the index python requirements are installed from, credentials being read from the PIP_INDEX_CREDENTIALS build arg (python only)")
+	}
+}
+
+func setResourceTemplateFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "resource-template") {
+		flagset.String("resource-template", "", "path to a Go template replacing the built-in function resource template")
+	}
+}
+
+func setExecutableModeFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "executable-mode") {
+		flagset.String("executable-mode", options.DefaultExecutableMode, "the octal mode of scaffolded scripts and Makefiles, other generated files being written 0644")
+	}
+}
+
+func setInlineFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "inline") {
+		flagset.Bool("inline", false, "write the function source into the Dockerfile instead of adding the artifact (shell and node only)")
+	}
+}
+
+func setResourceNameFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "resource-name") {
+		flagset.String("resource-name", "", "the name of the function resource, when it must differ from the function and image name (defaults to --name)")
+	}
+}
+
+func setDevContainerFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "devcontainer") {
+		flagset.Bool("devcontainer", false, "generate a .devcontainer/devcontainer.json for developing the function in a container")
+	}
+}
+
+func setTopicProviderFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "topic-provider") {
+		flagset.String("topic-provider", "", "the provider the generated topics are bound to, when several brokers are available")
+	}
+}
+
+func setNamespaceFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "namespace") {
+		flagset.String("namespace", "", "the namespace written into the generated resources (defaults to none, leaving it to the kubectl context)")
+	}
+}
+
+func setPlatformFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "platform") {
+		flagset.String("platform", "", "the platforms to build the image for with docker buildx, such as linux/amd64,linux/arm64 (several platforms require --push)")
+	}
+}
+
+func setPreviewBuildFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "preview-build") {
+		flagset.Bool("preview-build", false, "print the docker command that would build the image, quoted to be run as is, without running it")
+	}
+}
+
+func setHookFlags(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "pre-hook") {
+		flagset.String("pre-hook", "", "a shell command run in the function directory before generating the artifacts, which are not generated if it fails")
+	}
+	if !flagDefined(flagset, "post-hook") {
+		flagset.String("post-hook", "", "a shell command run in the function directory once the artifacts are generated")
+	}
+}
+
+func setMinimalFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "minimal") {
+		flagset.Bool("minimal", false, "run the function on a distroless base, copying the invoker from its image, for smaller images (java only)")
+	}
+}
+
+func setOnlyFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "only") {
+		flagset.String("only", "", "write only one kind of artifacts, one of "+strings.Join(options.OnlyKinds, ", ")+", leaving the others untouched")
+	}
+}
+
+func setInstallCmdFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "install-cmd") {
+		flagset.String("install-cmd", "", "the command installing the function dependencies in the image, replacing pip, npm or maven's (node, java and python only)")
+	}
+}
+
+func setNoInstallFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "no-install") {
+		flagset.Bool("no-install", false, "do not install the function dependencies in the image, as they are vendored with the function")
+	}
+}
+
+func setCloudEventsFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "cloudevents") {
+		flagset.Bool("cloudevents", false, "have the invoker expect events as structured CloudEvents")
+	}
+}
+
+func setImageVariantFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "image-variant") {
+		flagset.String("image-variant", "", "a suffix appended to the invoker image tag to use one of its variants, such as -debug or -jre11")
+	}
+}
+
+func setKedaFlags(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "keda") {
+		flagset.Bool("keda", false, "generate a KEDA ScaledObject scaling the function on the lag of its input topics")
+	}
+	if !flagDefined(flagset, "min-replicas") {
+		flagset.Int("min-replicas", 0, "the fewest replicas KEDA scales the function to, with --keda")
+	}
+	if !flagDefined(flagset, "max-replicas") {
+		flagset.Int("max-replicas", 0, fmt.Sprintf("the most replicas KEDA scales the function to, with --keda (defaults to %d)", options.DefaultMaxReplicas))
+	}
+}
+
+func setUseCopyFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "use-copy") {
+		flagset.Bool("use-copy", false, "add the function files with COPY instead of ADD in the generated Dockerfile")
+	}
+}
+
+func setAnnotationFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "annotation") {
+		flagset.StringArray("annotation", []string{}, "a key=value annotation of the generated function and topics (can be repeated)")
+	}
+}
+
+func setShellFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "shell") {
+		flagset.String("shell", "", "the interpreter the invoker runs the script with, one of "+strings.Join(options.SupportedShells, ", ")+" (shell only, defaults to the invoker's)")
+	}
+}
+
+func setQuietDepsFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "quiet-deps") {
+		flagset.Bool("quiet-deps", false, "install the function dependencies quietly, with pip install -q or npm's silent log level, to keep build logs short (python and node only)")
+	}
+}
+
+func setExplainDetectionFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "explain-detection") {
+		flagset.Bool("explain-detection", false, "print the score of each language the function could be written in, with the signals found for it")
+	}
+}
+
+func setFunctionDirFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "function-dir") {
+		flagset.String("function-dir", options.DefaultImageFunctionDir, "the absolute directory of the image the invoker loads the function from (node, java and deno only)")
+	}
+}
+
+func setProbeFlags(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "readiness-path") {
+		flagset.String("readiness-path", "", "the HTTP path of the function port probed to tell when the function is ready")
+	}
+	if !flagDefined(flagset, "liveness-path") {
+		flagset.String("liveness-path", "", "the HTTP path of the function port probed to tell when the function must be restarted")
+	}
+	if !flagDefined(flagset, "probe-initial-delay") {
+		flagset.Int("probe-initial-delay", 0, "the seconds before the first probe (defaults to Kubernetes')")
+	}
+	if !flagDefined(flagset, "probe-period") {
+		flagset.Int("probe-period", 0, "the seconds between probes (defaults to Kubernetes')")
+	}
+	if !flagDefined(flagset, "probe-timeout") {
+		flagset.Int("probe-timeout", 0, "the seconds a probe may take (defaults to Kubernetes')")
+	}
+}
+
+func setKustomizeFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "kustomize") {
+		flagset.Bool("kustomize", false, "generate a kustomization.yaml listing the function resources, as a kustomize base for overlays")
+	}
+}
+
+func setOutputFormatFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "output-format") {
+		flagset.String("output-format", options.OutputFormatText, "the format --dry-run shows the generated artifacts in, one of "+strings.Join(options.OutputFormats, ", ")+", json including what language detection found")
+	}
+}
+
+func setFromGitFlags(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "from-git") {
+		flagset.String("from-git", "", "a git repository URL to shallow clone and initialize the function from, copying the generated artifacts to the function path")
+	}
+	if !flagDefined(flagset, "subdir") {
+		flagset.String("subdir", "", "the directory of the function within the --from-git repository, for monorepos")
+	}
+}
+
+func setManifestOutFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "manifest-out") {
+		flagset.String("manifest-out", "", "a file to write a JSON manifest of the files written, with their roles, to")
+	}
+}
+
+func setTektonFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "tekton") {
+		flagset.Bool("tekton", false, "generate a Tekton task in .tekton building the function image in-cluster with kaniko and pushing it")
+	}
+}
+
+func setExecFormFlags(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "entrypoint") {
+		flagset.String("entrypoint", "", "the ENTRYPOINT of the image in exec form, as words separated by spaces or a JSON array (defaults to the base image's)")
+	}
+	if !flagDefined(flagset, "cmd") {
+		flagset.String("cmd", "", "the CMD of the image in exec form, as words separated by spaces or a JSON array (defaults to the base image's)")
+	}
+}
+
+func setExplainUriFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "explain-uri") {
+		flagset.Bool("explain-uri", false, "explain how the FUNCTION_URI of the generated Dockerfile is assembled from the artifact and handler")
+	}
+}
+
+func setArgoCDFlags(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "argocd") {
+		flagset.Bool("argocd", false, "generate an ArgoCD Application in argocd syncing the function resources from --repo-url and --path")
+	}
+	if !flagDefined(flagset, "repo-url") {
+		flagset.String("repo-url", "", "the URL of the git repository ArgoCD syncs the function resources from")
+	}
+	if !flagDefined(flagset, "path") {
+		flagset.String("path", "", "the directory of the function resources in the --repo-url repository")
+	}
+}
+
+func setScaleFlags(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "min-scale") {
+		flagset.Int("min-scale", 0, "the fewest replicas of the function resource (defaults to the function controller's)")
+	}
+	if !flagDefined(flagset, "max-scale") {
+		flagset.Int("max-scale", 0, "the most replicas of the function resource (defaults to the function controller's)")
+	}
+}
+
+func setBuildToolFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "build-tool") {
+		flagset.String("build-tool", "", "the tool building the jar of a java function in the image, one of "+strings.Join(options.BuildTools, ", ")+" (defaults to maven with a pom.xml and gradle with a build.gradle)")
+	}
+}
+
+func setLintSourceFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "lint-source") {
+		flagset.Bool("lint-source", false, "lint the function source with the linter of its language, such as eslint, flake8 or checkstyle, and fail on lint errors (skipped when the linter is not installed)")
+	}
+}
+
+func setKeyedFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "keyed") {
+		flagset.Bool("keyed", false, "generate keyed topics, keeping the messages of a key in order (a single topic is keyed by suffixing it with :keyed, e.g. -i orders:keyed)")
+	}
+}
+
+func setOpenApiFlags(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "from-openapi") {
+		flagset.String("from-openapi", "", "the OpenAPI spec, in YAML or JSON, to scaffold the function from, writing a node, python or shell stub of its --operation")
+	}
+	if !flagDefined(flagset, "operation") {
+		flagset.String("operation", "", "the operationId of the --from-openapi operation handled by the function, e.g. getUser")
+	}
+}
+
+func setReplicasFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "replicas") {
+		flagset.Int("replicas", 0, "a fixed number of replicas of a function that is not autoscaled, which cannot be used with --min-scale, --max-scale or --keda")
+	}
+}
+
+func setEnvFileFlags(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "env-file") {
+		flagset.Bool("env-file", false, "generate a .env file with the environment of the function, for local runs with 'docker run --env-file .env'")
+	}
+	if !flagDefined(flagset, "env") {
+		flagset.StringArray("env", []string{}, "an environment variable NAME=value written to the --env-file (can be repeated)")
+	}
+}
+
+func setVerifyHandlerFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "verify-handler") {
+		flagset.Bool("verify-handler", false, "fail unless the function defines its handler, looking for the class in the jar or compiled classes of java functions and for the function in the source of python and node ones")
+	}
+}
+
 func setPushFlag(flagset *pflag.FlagSet) {
 	if !flagDefined(flagset, "push") {
 		flagset.BoolP("push", "", defaults.push, "push the image to Docker registry")
 	}
 }
 
+func setNoCacheFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "no-cache") {
+		flagset.Bool("no-cache", false, "do not use the Docker layer cache when building the image")
+	}
+}
+
+func setCacheBustFlag(flagset *pflag.FlagSet) {
+	if !flagDefined(flagset, "cache-bust") {
+		flagset.String("cache-bust", "", "a value for the CACHE_BUST build arg, changing it reinstalls the dependencies of the function without rebuilding every layer")
+	}
+}
+
 func flagDefined(flagset *pflag.FlagSet, name string) bool {
 	return flagset.Lookup(name) != nil
 }
@@ -0,0 +1,191 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/projectriff/riff-cli/cmd/utils"
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// The instructions a Dockerfile may use.
+var dockerfileInstructions = map[string]bool{
+	"FROM": true, "RUN": true, "CMD": true, "LABEL": true, "MAINTAINER": true, "EXPOSE": true, "ENV": true,
+	"ADD": true, "COPY": true, "ENTRYPOINT": true, "VOLUME": true, "USER": true, "WORKDIR": true, "ARG": true,
+	"ONBUILD": true, "STOPSIGNAL": true, "HEALTHCHECK": true, "SHELL": true,
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a function directory",
+	Long: `Check the options and the generated artifacts of a function directory without writing anything: the
+  options are validated as by 'riff init', the Dockerfile must be present and well formed, and the resource
+  definitions must be valid and include a Function.`,
+	Example: `riff validate -f ./square`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagset := cmd.Flags()
+		if err := applyConfig(flagset); err != nil {
+			return err
+		}
+		var initOptions options.InitOptions
+		utils.MergeInitOptions(*flagset, &initOptions)
+		initOptions.Offline = offline(cmd)
+		if len(args) == 1 && initOptions.FunctionPath == "" {
+			initOptions.FunctionPath = args[0]
+		} else if len(args) > 0 {
+			return errors.New(fmt.Sprintf("invalid argument(s) %v", args))
+		}
+
+		cmd.SilenceUsage = true
+		problems := validateFunction(initOptions)
+		for _, problem := range problems {
+			ioutils.Error(problem)
+		}
+		if len(problems) > 0 {
+			return errors.New(fmt.Sprintf("function in %s has %d problem(s)", initOptions.FunctionPath, len(problems)))
+		}
+		ioutils.Infof("function in %s is valid\n", initOptions.FunctionPath)
+		return nil
+	},
+}
+
+/*
+ * Collects the problems of an already initialized function: invalid options, a missing or malformed
+ * Dockerfile and invalid resource definitions.
+ */
+func validateFunction(initOptions options.InitOptions) []error {
+	if err := options.ValidateAndCleanInitOptions(&initOptions); err != nil {
+		return []error{err}
+	}
+	dir := options.FunctionDir(initOptions)
+	problems := validateDockerfile(filepath.Join(dir, "Dockerfile"))
+	return append(problems, validateResources(dir)...)
+}
+
+func validateDockerfile(path string) []error {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []error{errors.New(fmt.Sprintf("%s is missing, run 'riff init' to generate it", path))}
+	}
+	if err != nil {
+		return []error{err}
+	}
+
+	var problems []error
+	from := false
+	continued := false
+	for i, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		wasContinued := continued
+		continued = strings.HasSuffix(trimmed, "\\")
+		if wasContinued || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		instruction := strings.ToUpper(strings.Fields(trimmed)[0])
+		if !dockerfileInstructions[instruction] {
+			problems = append(problems, errors.New(fmt.Sprintf("%s:%d: unknown instruction %s", path, i+1, instruction)))
+			continue
+		}
+		// Only ARG instructions may come before the first FROM.
+		if !from && instruction != "FROM" && instruction != "ARG" {
+			problems = append(problems, errors.New(fmt.Sprintf("%s:%d: %s before any FROM instruction", path, i+1, instruction)))
+		}
+		from = from || instruction == "FROM"
+	}
+	if !from {
+		problems = append(problems, errors.New(fmt.Sprintf("%s has no FROM instruction", path)))
+	}
+	return problems
+}
+
+type resourceHeader struct {
+	ApiVersion string `yaml:"apiVersion"`
+	Kind       string
+	Metadata   struct {
+		Name string
+	}
+}
+
+func validateResources(dir string) []error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return []error{err}
+	}
+
+	var problems []error
+	functions := 0
+	for _, file := range files {
+		if isKustomization(file) {
+			continue
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		for i, document := range splitYamlDocuments(string(content)) {
+			if strings.TrimSpace(document) == "" {
+				continue
+			}
+			var resource resourceHeader
+			if err := yaml.Unmarshal([]byte(document), &resource); err != nil {
+				problems = append(problems, errors.New(fmt.Sprintf("%s: document %d is not valid YAML: %v", file, i+1, err)))
+				continue
+			}
+			if resource.ApiVersion == "" || resource.Kind == "" || resource.Metadata.Name == "" {
+				problems = append(problems, errors.New(fmt.Sprintf("%s: document %d needs an apiVersion, a kind and a metadata.name", file, i+1)))
+				continue
+			}
+			if resource.Kind == "Function" {
+				functions++
+			}
+		}
+	}
+	if functions == 0 {
+		problems = append(problems, errors.New(fmt.Sprintf("no Function resource found in %s, run 'riff init' to generate it", dir)))
+	}
+	return problems
+}
+
+func splitYamlDocuments(content string) []string {
+	var documents []string
+	var document []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimRight(line, " \t") == "---" {
+			documents = append(documents, strings.Join(document, "\n"))
+			document = nil
+			continue
+		}
+		document = append(document, line)
+	}
+	return append(documents, strings.Join(document, "\n"))
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	utils.CreateInitFlags(validateCmd.Flags())
+}
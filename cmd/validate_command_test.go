@@ -0,0 +1,99 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/initializers"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func initializedFunction(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "riff-validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir = filepath.Join(dir, "square")
+	if err = os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "square.js"), []byte("module.exports = x => x ** 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := options.InitOptions{FunctionPath: dir, UserAccount: "me", Version: "0.0.1", RiffVersion: "0.0.5"}
+	if err = options.ValidateAndCleanInitOptions(&opts); err != nil {
+		t.Fatal(err)
+	}
+	if err = initializers.Node().Initialize(opts); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestValidateInitializedFunction(t *testing.T) {
+	as := assert.New(t)
+	dir := initializedFunction(t)
+	defer os.RemoveAll(filepath.Dir(dir))
+
+	as.Empty(validateFunction(options.InitOptions{FunctionPath: dir}))
+}
+
+func TestValidateSkipsKustomization(t *testing.T) {
+	as := assert.New(t)
+	dir := initializedFunction(t)
+	defer os.RemoveAll(filepath.Dir(dir))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(kustomizationTestResource), 0644))
+
+	as.Empty(validateFunction(options.InitOptions{FunctionPath: dir}))
+}
+
+func TestValidateFunctionWithoutDockerfile(t *testing.T) {
+	as := assert.New(t)
+	dir := initializedFunction(t)
+	defer os.RemoveAll(filepath.Dir(dir))
+	as.NoError(os.Remove(filepath.Join(dir, "Dockerfile")))
+
+	problems := validateFunction(options.InitOptions{FunctionPath: dir})
+	if as.Len(problems, 1) {
+		as.Contains(problems[0].Error(), "Dockerfile is missing")
+	}
+}
+
+func TestValidateMalformedArtifacts(t *testing.T) {
+	as := assert.New(t)
+	dir := initializedFunction(t)
+	defer os.RemoveAll(filepath.Dir(dir))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("ADD square.js /functions/\nFORM node\n"), 0644))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square-function.yaml"), []byte("kind: Function\n  name: [square\n"), 0644))
+
+	problems := validateFunction(options.InitOptions{FunctionPath: dir})
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.Error())
+	}
+	as.Len(messages, 5, "%v", messages)
+	as.Contains(messages[0], "ADD before any FROM instruction")
+	as.Contains(messages[1], "unknown instruction FORM")
+	as.Contains(messages[2], "has no FROM instruction")
+	as.Contains(messages[3], "is not valid YAML")
+	as.Contains(messages[4], "no Function resource found")
+}
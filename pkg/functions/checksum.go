@@ -0,0 +1,115 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package functions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The files listing a function's dependencies, which change its image as much as its source does.
+var dependencyFiles = []string{"requirements.txt", "package.json", "pom.xml", "setup.py"}
+
+// The extensions of the source files checksummed, artifacts included.
+var sourceExtensions = map[string]bool{".sh": true, ".js": true, ".ts": true, ".py": true, ".java": true, ".jar": true}
+
+// Directories never holding function sources, such as installed node modules.
+var skippedDirs = map[string]bool{"node_modules": true}
+
+// The length of the checksum prefix used as an image tag.
+const contentHashTagLength = 12
+
+/*
+ * A SHA-256 checksum of the function sources in dir: every source file of dir and its subdirectories, such as
+ * a jar in target, and the dependency files. It only depends on the directory, so that 'riff init' and
+ * 'riff build' derive the same tag whether an artifact is given or not. Hidden directories are skipped. File
+ * names and contents are hashed in a stable order, so identical sources always have the same checksum.
+ */
+func SourceChecksum(dir string) (string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && (strings.HasPrefix(info.Name(), ".") || skippedDirs[info.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() && (sourceExtensions[filepath.Ext(path)] || contains(dependencyFiles, info.Name())) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	hash := sha256.New()
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return "", err
+		}
+		if err = hashFile(hash, filepath.ToSlash(rel), file); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// The image tag derived from the function sources, a prefix of their checksum.
+func ContentHashTag(dir string) (string, error) {
+	checksum, err := SourceChecksum(dir)
+	if err != nil {
+		return "", err
+	}
+	return checksum[:contentHashTagLength], nil
+}
+
+func hashFile(w io.Writer, name string, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	// The name and size delimit each file, so moving content between files changes the checksum.
+	fmt.Fprintf(w, "%s\x00%d\x00", name, info.Size())
+	_, err = io.Copy(w, file)
+	return err
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package functions
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentHashTag(t *testing.T) {
+	as := assert.New(t)
+	first := manifestDir(t, "square.js", "module.exports = x => x ** 2\n")
+	defer os.RemoveAll(first)
+	second := manifestDir(t, "square.js", "module.exports = x => x ** 2\n")
+	defer os.RemoveAll(second)
+
+	tag, err := ContentHashTag(first)
+	as.NoError(err)
+	as.Len(tag, 12)
+	same, err := ContentHashTag(second)
+	as.NoError(err)
+	as.Equal(tag, same, "identical sources have the same tag")
+
+	as.NoError(ioutil.WriteFile(filepath.Join(second, "square.js"), []byte("module.exports = x => x * x\n"), 0644))
+	changed, err := ContentHashTag(second)
+	as.NoError(err)
+	as.NotEqual(tag, changed, "changed sources have another tag")
+}
+
+func TestContentHashTagIncludesDependencies(t *testing.T) {
+	as := assert.New(t)
+	dir := manifestDir(t, "square.js", "module.exports = x => x ** 2\n")
+	defer os.RemoveAll(dir)
+
+	withoutDependencies, err := ContentHashTag(dir)
+	as.NoError(err)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "square"}`), 0644))
+	withDependencies, err := ContentHashTag(dir)
+	as.NoError(err)
+	as.NotEqual(withoutDependencies, withDependencies)
+
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square-function.yaml"), []byte("image: me/square\n"), 0644))
+	generated, err := ContentHashTag(dir)
+	as.NoError(err)
+	as.Equal(withDependencies, generated, "generated resources are not hashed")
+}
+
+func TestContentHashTagIncludesArtifactsInSubdirectories(t *testing.T) {
+	as := assert.New(t)
+	dir := manifestDir(t, "pom.xml", "<project/>\n")
+	defer os.RemoveAll(dir)
+
+	withoutJar, err := ContentHashTag(dir)
+	as.NoError(err)
+	as.NoError(os.Mkdir(filepath.Join(dir, "target"), 0755))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "target", "greeter-1.0.0.jar"), []byte("PK"), 0644))
+	withJar, err := ContentHashTag(dir)
+	as.NoError(err)
+	as.NotEqual(withoutJar, withJar)
+
+	for _, skipped := range []string{".git", "node_modules"} {
+		as.NoError(os.Mkdir(filepath.Join(dir, skipped), 0755))
+		as.NoError(ioutil.WriteFile(filepath.Join(dir, skipped, "index.js"), []byte("module.exports = {}\n"), 0644))
+	}
+	skipped, err := ContentHashTag(dir)
+	as.NoError(err)
+	as.Equal(withJar, skipped, "hidden directories and node modules are not hashed")
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/projectriff/riff-cli/pkg/osutils"
+)
+
+// A function directory initialized by 'riff init'.
+type FunctionDir struct {
+	Dir  string
+	Name string
+}
+
+/*
+ * Finds the function directories under root: those with a Dockerfile and a <name>-function.yaml resource
+ * definition, the function being named after the latter. Hidden directories and node_modules are skipped.
+ */
+func FindFunctionDirs(root string) ([]FunctionDir, error) {
+	var found []FunctionDir
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(info.Name(), ".") || info.Name() == "node_modules") {
+			return filepath.SkipDir
+		}
+		if !osutils.FileExists(filepath.Join(path, "Dockerfile")) {
+			return nil
+		}
+		resources, err := filepath.Glob(filepath.Join(path, "*-function.yaml"))
+		if err != nil {
+			return err
+		}
+		sort.Strings(resources)
+		if len(resources) > 0 {
+			found = append(found, FunctionDir{Dir: path, Name: strings.TrimSuffix(filepath.Base(resources[0]), "-function.yaml")})
+		}
+		return nil
+	})
+	return found, err
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package functions
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindFunctionDirs(t *testing.T) {
+	as := assert.New(t)
+	root, err := ioutil.TempDir("", "riff-discovery")
+	as.NoError(err)
+	defer os.RemoveAll(root)
+	for _, file := range []string{
+		"square/Dockerfile",
+		"square/square-function.yaml",
+		"math/cube/Dockerfile",
+		"math/cube/cube-function.yaml",
+		"math/README.md",
+		"uninitialized/Dockerfile",
+		".cache/hidden/Dockerfile",
+		".cache/hidden/hidden-function.yaml",
+	} {
+		as.NoError(os.MkdirAll(filepath.Join(root, filepath.Dir(file)), 0755))
+		as.NoError(ioutil.WriteFile(filepath.Join(root, file), []byte{}, 0644))
+	}
+
+	found, err := FindFunctionDirs(root)
+	as.NoError(err)
+	as.Equal([]FunctionDir{
+		{Dir: filepath.Join(root, "math", "cube"), Name: "cube"},
+		{Dir: filepath.Join(root, "square"), Name: "square"},
+	}, found)
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package functions
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var setupNameRegexp = regexp.MustCompile(`\bname\s*=\s*['"]([^'"]+)['"]`)
+
+var manifestNameReaders = map[string]func(dir string) string{
+	"node":   nameFromPackageJson,
+	"java":   nameFromPom,
+	"python": nameFromSetupPy,
+}
+
+/*
+ * Reads the project name from the language's manifest in dir: name in package.json, artifactId in pom.xml
+ * or the name given to setup() in setup.py. Returns false when there is no manifest or it has no name.
+ */
+func FunctionNameFromManifest(dir string, language string) (string, bool) {
+	reader, ok := manifestNameReaders[language]
+	if !ok {
+		return "", false
+	}
+	name := reader(dir)
+	return name, name != ""
+}
+
+func nameFromPackageJson(dir string) string {
+	content, err := ioutil.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var manifest struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(content, &manifest) != nil {
+		return ""
+	}
+	// A scoped package such as @scope/square is named after the package alone.
+	return manifest.Name[strings.LastIndex(manifest.Name, "/")+1:]
+}
+
+func nameFromPom(dir string) string {
+	content, err := ioutil.ReadFile(filepath.Join(dir, "pom.xml"))
+	if err != nil {
+		return ""
+	}
+	// Only the project's own artifactId is matched, not the one nested in <parent>.
+	var manifest struct {
+		ArtifactId string `xml:"artifactId"`
+	}
+	if xml.Unmarshal(content, &manifest) != nil {
+		return ""
+	}
+	return strings.TrimSpace(manifest.ArtifactId)
+}
+
+func nameFromSetupPy(dir string) string {
+	content, err := ioutil.ReadFile(filepath.Join(dir, "setup.py"))
+	if err != nil {
+		return ""
+	}
+	match := setupNameRegexp.FindSubmatch(content)
+	if match == nil {
+		return ""
+	}
+	return string(match[1])
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package functions
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func manifestDir(t *testing.T, file string, content string) string {
+	dir, err := ioutil.TempDir("", "riff-manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file != "" {
+		if err = ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFunctionNameFromPackageJson(t *testing.T) {
+	as := assert.New(t)
+	dir := manifestDir(t, "package.json", `{"name": "@acme/square", "version": "1.0.0"}`)
+	defer os.RemoveAll(dir)
+
+	name, ok := FunctionNameFromManifest(dir, "node")
+	as.True(ok)
+	as.Equal("square", name)
+}
+
+func TestFunctionNameFromPom(t *testing.T) {
+	as := assert.New(t)
+	dir := manifestDir(t, "pom.xml", `<project>
+  <parent><artifactId>spring-boot-starter-parent</artifactId></parent>
+  <groupId>functions</groupId>
+  <artifactId>greeter</artifactId>
+</project>`)
+	defer os.RemoveAll(dir)
+
+	name, ok := FunctionNameFromManifest(dir, "java")
+	as.True(ok)
+	as.Equal("greeter", name)
+}
+
+func TestFunctionNameFromSetupPy(t *testing.T) {
+	as := assert.New(t)
+	dir := manifestDir(t, "setup.py", "from setuptools import setup\n\nsetup(\n    name='wordcount',\n    version='0.1',\n)\n")
+	defer os.RemoveAll(dir)
+
+	name, ok := FunctionNameFromManifest(dir, "python")
+	as.True(ok)
+	as.Equal("wordcount", name)
+}
+
+func TestFunctionNameWithoutManifest(t *testing.T) {
+	as := assert.New(t)
+	dir := manifestDir(t, "", "")
+	defer os.RemoveAll(dir)
+
+	_, ok := FunctionNameFromManifest(dir, "node")
+	as.False(ok)
+	_, ok = FunctionNameFromManifest(dir, "shell")
+	as.False(ok)
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package initializers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+)
+
+/*
+ * Initializes the function in each directory, detecting its language, with up to parallel directories
+ * generated at once. The function name and artifact are derived per directory, all other options are shared.
+ * Generation keeps no shared mutable state, so directories are independent of each other.
+ */
+func InitializeAll(dirs []string, opts options.InitOptions, parallel int) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	work := make(chan string)
+	var failures []string
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for dir := range work {
+				if err := initializeDir(dir, opts); err != nil {
+					mutex.Lock()
+					failures = append(failures, fmt.Sprintf("%s: %v", dir, err))
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+	for _, dir := range dirs {
+		work <- dir
+	}
+	close(work)
+	wg.Wait()
+
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return errors.New(fmt.Sprintf("failed to initialize %d of %d functions\n%s", len(failures), len(dirs), strings.Join(failures, "\n")))
+	}
+	return nil
+}
+
+func initializeDir(dir string, opts options.InitOptions) error {
+	opts.FunctionPath = dir
+	opts.FunctionName = ""
+	opts.Artifact = ""
+	err := options.ValidateAndCleanInitOptions(&opts)
+	if err != nil {
+		return err
+	}
+	return Initialize(opts)
+}
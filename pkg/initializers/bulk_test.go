@@ -0,0 +1,66 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package initializers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitializeAllInParallel(t *testing.T) {
+	as := assert.New(t)
+	root, err := ioutil.TempDir("", "riff-bulk")
+	as.NoError(err)
+	defer os.RemoveAll(root)
+
+	var dirs []string
+	for i := 0; i < 6; i++ {
+		name := fmt.Sprintf("echo%d", i)
+		dir := filepath.Join(root, name)
+		as.NoError(os.Mkdir(dir, 0755))
+		as.NoError(ioutil.WriteFile(filepath.Join(dir, name+".sh"), []byte("#!/bin/sh\necho $1\n"), 0755))
+		dirs = append(dirs, dir)
+	}
+
+	opts := options.InitOptions{UserAccount: "me", Version: "0.0.1", RiffVersion: "0.0.3"}
+	as.NoError(InitializeAll(dirs, opts, 4))
+
+	for i, dir := range dirs {
+		as.True(osutils.FileExists(filepath.Join(dir, "Dockerfile")))
+		function, err := ioutil.ReadFile(filepath.Join(dir, fmt.Sprintf("echo%d-function.yaml", i)))
+		as.NoError(err)
+		as.Contains(string(function), fmt.Sprintf("image: me/echo%d:0.0.1", i))
+	}
+}
+
+func TestInitializeAllReportsFailures(t *testing.T) {
+	as := assert.New(t)
+	root, err := ioutil.TempDir("", "riff-bulk")
+	as.NoError(err)
+	defer os.RemoveAll(root)
+
+	err = InitializeAll([]string{filepath.Join(root, "missing")}, options.InitOptions{}, 2)
+	as.Error(err)
+	as.Contains(err.Error(), "failed to initialize 1 of 1 functions")
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"text/template"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+)
+
+// The directory of the generated ArgoCD Application, relative to the function directory, for an app-of-apps to sync.
+const ArgoCDDir = "argocd"
+
+// The namespace ArgoCD watches for Applications.
+const argoCDNamespace = "argocd"
+
+type ArgoApplication struct {
+	Name                 string
+	Namespace            string
+	RepoUrl              string
+	Path                 string
+	DestinationNamespace string
+}
+
+// The Application syncs the function resources found at the path of the repository into the function namespace.
+var argoApplicationTemplate = `
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: {{.Name}}
+  namespace: {{.Namespace}}
+spec:
+  project: default
+  source:
+    repoURL: {{.RepoUrl}}
+    targetRevision: HEAD
+    path: {{.Path}}
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: {{.DestinationNamespace}}
+`
+
+func generateArgoApplication(opts options.InitOptions) (string, error) {
+	destinationNamespace := opts.Namespace
+	if destinationNamespace == "" {
+		destinationNamespace = "default"
+	}
+	application := ArgoApplication{
+		Name:                 options.ResourceName(opts),
+		Namespace:            argoCDNamespace,
+		RepoUrl:              opts.RepoUrl,
+		Path:                 opts.RepoPath,
+		DestinationNamespace: destinationNamespace,
+	}
+
+	tmpl, err := template.New("argocd").Parse(argoApplicationTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, application)
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func argoApplicationPath(workdir string, opts options.InitOptions) string {
+	return filepath.Join(workdir, ArgoCDDir, fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "application"))
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+type YArgoApplication struct {
+	Kind     string
+	Metadata struct {
+		Name      string
+		Namespace string
+	}
+	Spec struct {
+		Source struct {
+			RepoURL string `yaml:"repoURL"`
+			Path    string
+		}
+		Destination struct {
+			Namespace string
+		}
+	}
+}
+
+func TestArgoApplication(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "square",
+		Namespace:    "functions",
+		ArgoCD:       true,
+		RepoUrl:      "https://github.com/me/functions.git",
+		RepoPath:     "square",
+	}
+
+	content, err := generateArgoApplication(opts)
+	as.NoError(err)
+
+	application := YArgoApplication{}
+	as.NoError(yaml.Unmarshal([]byte(content), &application))
+	as.Equal("Application", application.Kind)
+	as.Equal("square", application.Metadata.Name)
+	as.Equal("argocd", application.Metadata.Namespace)
+	as.Equal("https://github.com/me/functions.git", application.Spec.Source.RepoURL)
+	as.Equal("square", application.Spec.Source.Path)
+	as.Equal("functions", application.Spec.Destination.Namespace)
+}
+
+func TestArgoApplicationValidation(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{FunctionPath: "../../../test_data/node/square", ArgoCD: true, RepoUrl: "git@github.com:me/functions.git", RepoPath: "functions/square"}
+	as.NoError(options.ValidateAndCleanInitOptions(&opts))
+
+	opts.RepoUrl = "github.com/me/functions"
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "repo URL github.com/me/functions is not a git repository URL, such as https://github.com/me/functions.git")
+
+	opts.RepoUrl = "https://github.com/me/functions.git"
+	opts.RepoPath = "../square"
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "path ../square must be relative to the root of the repository")
+
+	opts.RepoPath = ""
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "--argocd requires --repo-url and --path")
+
+	opts.ArgoCD = false
+	opts.RepoPath = "square"
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "--repo-url and --path are only supported with --argocd")
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+)
+
+/*
+ * Resolves where the Dockerfile adds the function artifact from. The artifact is a file of the build context,
+ * unless it is given as a URL, whose scheme selects the resolver.
+ */
+type ArtifactResolver interface {
+	// The source of the Dockerfile instruction adding the artifact.
+	Source(artifact string, functionDir string) string
+	// Whether the artifact is fetched when building the image, which only ADD does.
+	Remote() bool
+}
+
+// The resolvers of remote artifacts by URL scheme, in which other schemes can be registered.
+var ArtifactResolvers = map[string]ArtifactResolver{
+	"http":  urlArtifact{},
+	"https": urlArtifact{},
+	"s3":    s3Artifact{},
+}
+
+// Resolves the artifact, a local file unless it is a URL with a registered scheme.
+func ResolveArtifact(artifact string) (ArtifactResolver, error) {
+	if !options.IsRemoteArtifact(artifact) {
+		return localArtifact{}, nil
+	}
+	scheme := artifact[:strings.Index(artifact, "://")]
+	resolver, ok := ArtifactResolvers[scheme]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("artifact %s cannot be fetched, no resolver is known for %s URLs", artifact, scheme))
+	}
+	return resolver, nil
+}
+
+// An artifact of the function directory, relative to the build context.
+type localArtifact struct{}
+
+func (localArtifact) Source(artifact string, functionDir string) string {
+	return path.Join(functionDir, filepath.ToSlash(artifact))
+}
+
+func (localArtifact) Remote() bool {
+	return false
+}
+
+// An artifact Docker downloads itself, such as a jar of a maven repository.
+type urlArtifact struct{}
+
+func (urlArtifact) Source(artifact string, functionDir string) string {
+	return artifact
+}
+
+func (urlArtifact) Remote() bool {
+	return true
+}
+
+// An S3 object, downloaded from the bucket's https endpoint, so it must be readable without credentials.
+type s3Artifact struct{}
+
+func (s3Artifact) Source(artifact string, functionDir string) string {
+	u, err := url.Parse(artifact)
+	if err != nil {
+		return artifact
+	}
+	return fmt.Sprintf("https://%s.s3.amazonaws.com%s", u.Host, u.Path)
+}
+
+func (s3Artifact) Remote() bool {
+	return true
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveArtifact(t *testing.T) {
+	as := assert.New(t)
+
+	resolver, err := ResolveArtifact("lib/square.js")
+	as.NoError(err)
+	as.False(resolver.Remote())
+	as.Equal("square/lib/square.js", resolver.Source("lib/square.js", "square"))
+
+	resolver, err = ResolveArtifact("https://example.com/square.js")
+	as.NoError(err)
+	as.True(resolver.Remote())
+	as.Equal("https://example.com/square.js", resolver.Source("https://example.com/square.js", "square"))
+
+	_, err = ResolveArtifact("ftp://example.com/square.js")
+	as.EqualError(err, "artifact ftp://example.com/square.js cannot be fetched, no resolver is known for ftp URLs")
+}
+
+func TestRemoteArtifactIsNotChecked(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionPath: osutils.Path("../../../test_data/node/square"),
+		Artifact:     "https://example.com/functions/square.js",
+	}
+	as.NoError(options.ValidateAndCleanInitOptions(&opts))
+	as.Equal("https://example.com/functions/square.js", opts.Artifact)
+
+	opts.TagStrategy = options.TagStrategyContentHash
+	as.Error(options.ValidateAndCleanInitOptions(&opts))
+}
+
+type mirroredArtifact struct{}
+
+func (mirroredArtifact) Source(artifact string, functionDir string) string {
+	return "https://mirror.example.com/" + artifact[len("mirror://"):]
+}
+
+func (mirroredArtifact) Remote() bool {
+	return true
+}
+
+func TestDockerFileTokensOfResolvedArtifact(t *testing.T) {
+	as := assert.New(t)
+	ArtifactResolvers["mirror"] = mirroredArtifact{}
+	defer delete(ArtifactResolvers, "mirror")
+
+	opts := options.InitOptions{Artifact: "mirror://square.js", UseCopy: true}
+	resolver, err := ResolveArtifact(opts.Artifact)
+	as.NoError(err)
+	tokens := NewDockerFileTokens(opts, resolver)
+	as.Equal("https://mirror.example.com/square.js", tokens.Artifact)
+	as.Equal("ADD", tokens.AddInstruction)
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+)
+
+// allowlistFetchTimeout bounds fetching a remote allowlist, so that an unreachable host fails the check.
+const allowlistFetchTimeout = 30 * time.Second
+
+/*
+ * Checks the generated Dockerfile against the base image allowlist. A remote allowlist is not fetched
+ * with --offline, the base images are then not checked at all, which is an error under --strict.
+ */
+func verifyBaseImages(dockerfile string, opts options.InitOptions) error {
+	if opts.Offline && options.IsURL(opts.BaseImageAllowlist) {
+		return options.Warning(opts.Strict, fmt.Sprintf("offline, skipping the base image check against %s", opts.BaseImageAllowlist))
+	}
+	return checkBaseImages(dockerfile, opts.BaseImageAllowlist)
+}
+
+/*
+ * Checks every FROM image of a generated Dockerfile against an allowlist file or URL. It lists one image
+ * per line; blank lines and lines starting with '#' are ignored. An entry without a tag allows every tag
+ * of that repository.
+ */
+func checkBaseImages(dockerfile string, allowlistPath string) error {
+	allowlist, err := readAllowlist(allowlistPath)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(dockerfile, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.ToUpper(fields[0]) != "FROM" {
+			continue
+		}
+		if !imageAllowed(fields[1], allowlist) {
+			return errors.New(fmt.Sprintf("base image %s is not allowed by %s", fields[1], allowlistPath))
+		}
+	}
+	return nil
+}
+
+func readAllowlist(path string) ([]string, error) {
+	var content []byte
+	var err error
+	if options.IsURL(path) {
+		content, err = fetch(path)
+	} else {
+		content, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var allowlist []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		allowlist = append(allowlist, line)
+	}
+	return allowlist, nil
+}
+
+func imageAllowed(image string, allowlist []string) bool {
+	for _, allowed := range allowlist {
+		if image == allowed || (!hasTag(allowed) && repository(image) == allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTag(image string) bool {
+	return strings.Contains(image[strings.LastIndex(image, "/")+1:], ":") || strings.Contains(image, "@")
+}
+
+func repository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
+	}
+	return image
+}
+
+func fetch(url string) ([]byte, error) {
+	client := http.Client{Timeout: allowlistFetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(fmt.Sprintf("fetching %s failed: %s", url, resp.Status))
+	}
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeAllowlist(t *testing.T, content string) string {
+	file, err := ioutil.TempFile("", "allowlist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	file.WriteString(content)
+	return file.Name()
+}
+
+func TestBaseImageAllowed(t *testing.T) {
+	as := assert.New(t)
+	allowlist := writeAllowlist(t, "# invokers\nprojectriff/node-function-invoker\n\nprojectriff/java-function-invoker:0.0.3\n")
+	defer os.Remove(allowlist)
+
+	as.NoError(checkBaseImages("FROM projectriff/node-function-invoker:0.0.5\nADD square.js /functions/", allowlist))
+	as.NoError(checkBaseImages("FROM projectriff/java-function-invoker:0.0.3\n", allowlist))
+}
+
+func TestBaseImageNotAllowed(t *testing.T) {
+	as := assert.New(t)
+	allowlist := writeAllowlist(t, "projectriff/java-function-invoker:0.0.3\n")
+	defer os.Remove(allowlist)
+
+	err := checkBaseImages("FROM projectriff/java-function-invoker:0.0.4\n", allowlist)
+	as.Error(err)
+	as.Contains(err.Error(), "base image projectriff/java-function-invoker:0.0.4 is not allowed")
+
+	err = checkBaseImages("FROM myregistry:5000/custom-invoker\n", allowlist)
+	as.Error(err)
+	as.Contains(err.Error(), "myregistry:5000/custom-invoker")
+}
+
+func TestRemoteBaseImageAllowlist(t *testing.T) {
+	as := assert.New(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		io.WriteString(w, "projectriff/node-function-invoker\n")
+	}))
+	defer server.Close()
+
+	opts := options.InitOptions{BaseImageAllowlist: server.URL + "/allowlist"}
+	as.NoError(verifyBaseImages("FROM projectriff/node-function-invoker:0.0.3\n", opts))
+	as.EqualError(verifyBaseImages("FROM alpine:3.7\n", opts), "base image alpine:3.7 is not allowed by "+opts.BaseImageAllowlist)
+	as.Equal(2, requests)
+}
+
+func TestRemoteBaseImageAllowlistSkippedOffline(t *testing.T) {
+	as := assert.New(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	opts := options.InitOptions{BaseImageAllowlist: server.URL + "/allowlist", Offline: true}
+	as.NoError(verifyBaseImages("FROM alpine:3.7\n", opts))
+	as.Equal(0, requests)
+
+	opts.Strict = true
+	as.EqualError(verifyBaseImages("FROM alpine:3.7\n", opts), "offline, skipping the base image check against "+opts.BaseImageAllowlist)
+	as.Equal(0, requests)
+
+	allowlist := writeAllowlist(t, "projectriff/node-function-invoker\n")
+	defer os.Remove(allowlist)
+	opts.BaseImageAllowlist = allowlist
+	as.Error(verifyBaseImages("FROM alpine:3.7\n", opts), "local allowlists are still checked offline")
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"text/template"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+)
+
+// The directory of the generated devcontainer configuration, relative to the function directory.
+const DevContainerDir = ".devcontainer"
+
+type DevContainer struct {
+	Name  string
+	Image string
+	Port  int
+}
+
+// The image providing the language toolchain in the devcontainer.
+var devContainerImages = map[string]string{
+	"deno":   "denoland/deno:latest",
+	"java":   "openjdk:8-jdk",
+	"node":   "node:8",
+	"python": "python:2.7",
+	"shell":  "bash:4.4",
+}
+
+// The function directory is mounted as the workspace, so the sources are edited in place.
+var devContainerTemplate = `
+{
+  "name": "{{.Name}}",
+  "image": "{{.Image}}",
+  "workspaceMount": "source=${localWorkspaceFolder},target=/workspace/{{.Name}},type=bind",
+  "workspaceFolder": "/workspace/{{.Name}}"{{if .Port}},
+  "forwardPorts": [{{.Port}}]{{end}}
+}
+`
+
+func generateDevContainer(language string, opts options.InitOptions) (string, error) {
+	image, ok := devContainerImages[language]
+	if !ok {
+		return "", errors.New(fmt.Sprintf("no devcontainer image is known for %s functions", language))
+	}
+	devContainer := DevContainer{
+		Name:  opts.FunctionName,
+		Image: image,
+		Port:  options.FunctionPort(opts),
+	}
+
+	tmpl, err := template.New("devcontainer").Parse(devContainerTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, devContainer)
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func devContainerPath(workdir string) string {
+	return filepath.Join(workdir, DevContainerDir, "devcontainer.json")
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeDevContainer(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "square",
+		Protocol:     "http",
+	}
+
+	content, err := generateDevContainer("node", opts)
+	as.NoError(err)
+
+	devContainer := struct {
+		Name            string
+		Image           string
+		WorkspaceMount  string
+		WorkspaceFolder string
+		ForwardPorts    []int
+	}{}
+	as.NoError(json.Unmarshal([]byte(content), &devContainer))
+	as.Equal("square", devContainer.Name)
+	as.Equal("node:8", devContainer.Image)
+	as.Equal("source=${localWorkspaceFolder},target=/workspace/square,type=bind", devContainer.WorkspaceMount)
+	as.Equal("/workspace/square", devContainer.WorkspaceFolder)
+	as.Equal([]int{8080}, devContainer.ForwardPorts)
+}
+
+func TestDevContainerForUnknownLanguage(t *testing.T) {
+	as := assert.New(t)
+
+	_, err := generateDevContainer("cobol", options.InitOptions{FunctionName: "square"})
+	as.EqualError(err, "no devcontainer image is known for cobol functions")
+}
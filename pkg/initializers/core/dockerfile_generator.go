@@ -18,20 +18,183 @@ package core
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
 	"text/template"
+
+	"github.com/projectriff/riff-cli/pkg/options"
 )
+
+// The template in a template directory that replaces the language's Dockerfile template.
+const CustomDockerfileTemplate = "Dockerfile.tmpl"
+
 type DockerFileTokens struct {
-	Artifact     string
-	ArtifactBase string
-	RiffVersion  string
-	Handler      string
+	Artifact         string
+	ArtifactBase     string
+	RiffVersion      string
+	Handler          string
+	Port             int
+	UriParams        []string
+	FunctionDir      string
+	ImageFunctionDir string
+	BaseImage        string
+	RunAsUser        string
+	Inline           string
+	InstallCmd       string
+	NoInstall        bool
+	QuietDeps        bool
+	CloudEvents      bool
+	Variant          string
+	AddInstruction   string
+	Entrypoint       string
+	Cmd              string
+}
+
+/*
+ * Tokens shared by all Dockerfile templates. ArtifactBase is the file name used inside the image and
+ * defaults to the base name of the artifact. Port is exposed when the protocol listens on one.
+ * Artifact is the source of the artifact given by its resolver: relative to the build context, which is the
+ * function directory unless given, or the URL a remote artifact is fetched from. ImageFunctionDir is where the invoker loads the function from.
+ * BaseImage, when given, replaces the language's invoker image, whose tag
+ * is otherwise suffixed with the Variant, if any. RunAsUser, when given, is set last so
+ * that it applies to the function only and not to installing its dependencies. InstallCmd, when given,
+ * is run in place of the language's own dependency install step, which NoInstall omits altogether and
+ * QuietDeps keeps from logging anything but warnings and errors.
+ * CloudEvents tells the invoker to expect structured CloudEvents.
+ * AddInstruction adds the function files, with COPY when asked to instead of ADD.
+ * Entrypoint and Cmd, when given, are the JSON arrays of ENTRYPOINT and CMD instructions in exec form,
+ * which otherwise are those of the base image.
+ */
+func NewDockerFileTokens(opts options.InitOptions, resolver ArtifactResolver) DockerFileTokens {
+	artifactBase := opts.ArtifactBase
+	if artifactBase == "" {
+		artifactBase = options.ArtifactName(opts.Artifact)
+	}
+	functionDir := options.FunctionDirInContext(opts)
+	return DockerFileTokens{
+		Artifact:         resolver.Source(opts.Artifact, functionDir),
+		ArtifactBase:     artifactBase,
+		RiffVersion:      opts.RiffVersion,
+		Handler:          opts.Handler,
+		Port:             options.FunctionPort(opts),
+		UriParams:        opts.UriParams,
+		FunctionDir:      functionDir,
+		ImageFunctionDir: options.ImageFunctionDir(opts),
+		BaseImage:        opts.BaseImage,
+		RunAsUser:        options.RunAsUser(opts),
+		InstallCmd:       opts.InstallCmd,
+		NoInstall:        opts.NoInstall,
+		QuietDeps:        opts.QuietDeps,
+		CloudEvents:      opts.CloudEvents,
+		Variant:          opts.ImageVariant,
+		AddInstruction:   addInstruction(opts, resolver),
+		Entrypoint:       execForm(opts.Entrypoint),
+		Cmd:              execForm(opts.Cmd),
+	}
 }
 
-func GenerateFunctionDockerFileContents(tmpl string, name string, tokens interface{}) (string, error) {
-	t, err := template.New(name).Parse(tmpl)
+// The words of an --entrypoint or --cmd as a JSON array, e.g. ["node", "server.js"], or empty when unset.
+func execForm(value string) string {
+	words, err := options.ExecForm(value)
+	if err != nil || len(words) == 0 {
+		return ""
+	}
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	// Dockerfiles are not HTML, so '<', '>' and '&' are kept as is.
+	encoder.SetEscapeHTML(false)
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		buffer.Reset()
+		encoder.Encode(word)
+		quoted[i] = strings.TrimSuffix(buffer.String(), "\n")
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// COPY only adds files of the build context, so remote artifacts are always added with ADD.
+func addInstruction(opts options.InitOptions, resolver ArtifactResolver) string {
+	if opts.UseCopy && !resolver.Remote() {
+		return "COPY"
+	}
+	return "ADD"
+}
+
+/*
+ * The lines of the artifact as single-quoted shell words, one per Dockerfile line, for templates to write the
+ * source with printf '%s\n' instead of adding the artifact. Quoting leaves the source as is, and no line
+ * starts with '#', which Docker would take for a comment.
+ */
+func InlineSource(opts options.InitOptions) (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(options.FunctionDir(opts), opts.Artifact))
 	if err != nil {
 		return "", err
 	}
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "'" + strings.Replace(line, "'", `'"'"'`, -1) + "'"
+	}
+	return strings.Join(lines, " \\\n    "), nil
+}
+
+// The path of a file of the function directory relative to the build context, for ADD instructions.
+func (tokens DockerFileTokens) Source(file string) string {
+	return path.Join(tokens.FunctionDir, file)
+}
+
+/*
+ * The FUNCTION_URI query string for the given handler, usually a reference to a build ARG, followed by the
+ * extra key=value parameters in the order given. Parameter keys and values are URL-encoded.
+ */
+func FunctionUriQuery(handler string, params []string) string {
+	var query []string
+	if handler != "" {
+		query = append(query, "handler="+handler)
+	}
+	for _, param := range params {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) < 2 {
+			kv = append(kv, "")
+		}
+		query = append(query, url.QueryEscape(kv[0])+"="+url.QueryEscape(kv[1]))
+	}
+	return strings.Join(query, "&")
+}
+
+// Lets templates render the query string, e.g. {{.UriQuery "${FUNCTION_HANDLER}"}}.
+func (tokens DockerFileTokens) UriQuery(handler string) string {
+	return FunctionUriQuery(handler, tokens.UriParams)
+}
+
+/*
+ * Renders a Dockerfile template. When a template directory is given, all its *.tmpl files are registered as
+ * named templates, under their file name with and without the extension, and a Dockerfile.tmpl found there
+ * replaces the language's template.
+ */
+func GenerateFunctionDockerFileContents(tmpl string, name string, templateDir string, tokens interface{}) (string, error) {
+	t := template.New(name)
+	var err error
+	if templateDir != "" {
+		t, err = parseTemplateDir(t, templateDir)
+		if err != nil {
+			return "", err
+		}
+	}
+	if custom := t.Lookup(CustomDockerfileTemplate); custom != nil {
+		t = custom
+	} else {
+		t, err = t.New(name).Parse(tmpl)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	var buffer bytes.Buffer
 	err = t.Execute(&buffer, tokens)
 	if err != nil {
@@ -39,3 +202,26 @@ func GenerateFunctionDockerFileContents(tmpl string, name string, tokens interfa
 	}
 	return buffer.String(), nil
 }
+
+func parseTemplateDir(t *template.Template, templateDir string) (*template.Template, error) {
+	pattern := filepath.Join(templateDir, "*.tmpl")
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.New(fmt.Sprintf("no *.tmpl files found in template directory %s", templateDir))
+	}
+	t, err = t.ParseGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, named := range t.Templates() {
+		if alias := strings.TrimSuffix(named.Name(), ".tmpl"); alias != named.Name() && t.Lookup(alias) == nil {
+			if _, err = t.AddParseTree(alias, named.Tree); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunctionUriQuery(t *testing.T) {
+	as := assert.New(t)
+
+	as.Equal("handler=${FUNCTION_CLASS}", FunctionUriQuery("${FUNCTION_CLASS}", nil))
+	as.Equal("handler=process&timeout=5s&retries=3", FunctionUriQuery("process", []string{"timeout=5s", "retries=3"}))
+	as.Equal("greeting=hello+world&path=%2Fa%3Fb%3Dc", FunctionUriQuery("", []string{"greeting=hello world", "path=/a?b=c"}))
+	as.Equal("", FunctionUriQuery("", nil))
+}
+
+func TestDockerfileFromTemplateDir(t *testing.T) {
+	as := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "riff-templates")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "Dockerfile.tmpl"), []byte("FROM acme/invoker:{{.RiffVersion}}\n{{template \"common\" .}}"), 0644))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "common.tmpl"), []byte("LABEL artifact={{.ArtifactBase}}\n"), 0644))
+
+	tokens := DockerFileTokens{ArtifactBase: "square.js", RiffVersion: "0.0.3"}
+	docker, err := GenerateFunctionDockerFileContents("FROM builtin", "docker-test", dir, tokens)
+	as.NoError(err)
+	as.Equal("FROM acme/invoker:0.0.3\nLABEL artifact=square.js\n", docker)
+}
+
+func TestDockerfileWithoutTemplateDir(t *testing.T) {
+	as := assert.New(t)
+
+	docker, err := GenerateFunctionDockerFileContents("FROM builtin:{{.RiffVersion}}", "docker-test", "", DockerFileTokens{RiffVersion: "0.0.3"})
+	as.NoError(err)
+	as.Equal("FROM builtin:0.0.3", docker)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+)
+
+// The file of the function environment, relative to the function directory.
+const EnvFile = ".env"
+
+// An ENV instruction of a Dockerfile, as ENV NAME value or ENV NAME=value.
+var envRegexp = regexp.MustCompile(`^ENV\s+(\w+)[\s=]+(.*)$`)
+
+/*
+ * Generates the .env file giving a local 'docker run --env-file' the environment of the function: the ENV of
+ * its Dockerfile, such as the FUNCTION_URI, with the build ARGs they reference, the handler and the --env
+ * variables, which take precedence. The build ARGs are replaced by their defaults.
+ */
+func generateEnvFile(opts options.InitOptions, dockerFile string) (string, error) {
+	var names []string
+	values := map[string]string{}
+	set := func(name string, value string) {
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		values[name] = value
+	}
+
+	args := map[string]string{}
+	for _, line := range strings.Split(dockerFile, "\n") {
+		line = strings.TrimSpace(line)
+		if match := argRegexp.FindStringSubmatch(line); match != nil {
+			args[match[1]] = unquote(match[2])
+		}
+		if match := envRegexp.FindStringSubmatch(line); match != nil {
+			value := argReferenceRegexp.ReplaceAllStringFunc(unquote(match[2]), func(reference string) string {
+				match := argReferenceRegexp.FindStringSubmatch(reference)
+				name := match[1] + match[2]
+				arg, ok := args[name]
+				if !ok {
+					return reference
+				}
+				set(name, arg)
+				return arg
+			})
+			set(match[1], value)
+		}
+	}
+	if _, ok := values["FUNCTION_HANDLER"]; !ok && opts.Handler != "" {
+		set("FUNCTION_HANDLER", opts.Handler)
+	}
+	for _, env := range opts.Env {
+		name, value := options.SplitEnv(env)
+		set(name, value)
+	}
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "# The environment of the %s function, for 'docker run --env-file %s'.\n", opts.FunctionName, EnvFile)
+	for _, name := range names {
+		fmt.Fprintf(&buffer, "%s=%s\n", name, values[name])
+	}
+	return buffer.String(), nil
+}
+
+func envFilePath(workdir string) string {
+	return filepath.Join(workdir, EnvFile)
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+const pythonDockerFile = `
+FROM projectriff/python2-function-invoker:0.0.6
+ARG FUNCTION_MODULE=demo.py
+ARG FUNCTION_HANDLER=process
+ADD ./demo.py /demo.py
+ENV FUNCTION_URI file:///${FUNCTION_MODULE}?handler=${FUNCTION_HANDLER}
+ENV FUNCTION_CONTENT_MODE structured
+`
+
+func TestEnvFile(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{FunctionName: "demo", Handler: "process", EnvFile: true, Env: []string{"GREETING=hello world", "FUNCTION_CONTENT_MODE=binary"}}
+	envFile, err := generateEnvFile(opts, pythonDockerFile)
+	as.NoError(err)
+	as.Equal(`# The environment of the demo function, for 'docker run --env-file .env'.
+FUNCTION_MODULE=demo.py
+FUNCTION_HANDLER=process
+FUNCTION_URI=file:///demo.py?handler=process
+FUNCTION_CONTENT_MODE=binary
+GREETING=hello world
+`, envFile)
+}
+
+func TestEnvFileAddsHandler(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{FunctionName: "greeter", Handler: "functions.Greeter", EnvFile: true}
+	envFile, err := generateEnvFile(opts, "FROM projectriff/java-function-invoker:0.0.6\nENV FUNCTION_URI file:///functions/greeter.jar?handler=functions.Greeter\n")
+	as.NoError(err)
+	as.Contains(envFile, "\nFUNCTION_URI=file:///functions/greeter.jar?handler=functions.Greeter\nFUNCTION_HANDLER=functions.Greeter\n")
+}
+
+func TestEnvFileValidation(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{FunctionPath: "../../../test_data/node/square", EnvFile: true, Env: []string{"GREETING"}}
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "env GREETING must be NAME=value, the name being letters, digits and underscores")
+}
@@ -0,0 +1,232 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+)
+
+const (
+	tempSuffix   = ".riff-tmp"
+	backupSuffix = ".riff-bak"
+)
+
+// The roles of the generated files, as listed by the --manifest-out manifest.
+const (
+	RoleDockerfile           = "dockerfile"
+	RoleFunctionResource     = "function-resource"
+	RoleTopicResource        = "topic-resource"
+	RoleScaledObjectResource = "scaledobject-resource"
+	RoleKustomization        = "kustomization"
+	RoleGitHubWorkflow       = "github-workflow"
+	RoleDevContainer         = "devcontainer"
+	RoleGitIgnore            = "gitignore"
+	RoleHelmChart            = "helm-chart"
+	RoleTektonTask           = "tekton-task"
+	RoleArgoApplication      = "argocd-application"
+	RoleEnvFile              = "env-file"
+	RoleScript               = "script"
+	RoleFunctionStub         = "function-stub"
+)
+
+// The mode of generated files that are not executable, such as the Dockerfile and resource definitions.
+const fileMode os.FileMode = 0644
+
+// Writes the staged content of a file, replaced in tests to inject failures.
+var writeStagedFile = ioutil.WriteFile
+
+// Renames a file to its backup or into place, replaced in tests to inject failures.
+var renameFile = os.Rename
+
+type stagedFile struct {
+	role     string
+	filename string
+	text     string
+	mode     os.FileMode
+	replaced bool
+}
+
+/*
+ * Writes the generated files all-or-nothing: every file is first written next to its target, and only
+ * once all of them are written are they renamed into place. On any failure, the files written so far are
+ * removed and the files they replaced are restored, so a failed generation leaves the directory as it was.
+ */
+type fileWriter struct {
+	opts   options.InitOptions
+	staged []stagedFile
+	dirs   []string
+}
+
+// Stages a file, skipping it when it exists and --force is not set.
+func (w *fileWriter) add(role string, filename string, text string) error {
+	return w.addWithMode(role, filename, text, fileMode)
+}
+
+// Stages a scaffolded script or Makefile, written with the --executable-mode.
+func (w *fileWriter) addExecutable(role string, filename string, text string) error {
+	return w.addWithMode(role, filename, text, options.ExecutableMode(w.opts))
+}
+
+func (w *fileWriter) addWithMode(role string, filename string, text string, mode os.FileMode) error {
+	if !w.opts.Force && osutils.FileExists(filename) {
+		return options.Warning(w.opts.Strict, fmt.Sprintf("skipping existing file %s  - set --force to overwrite.", filename))
+	}
+	w.stage(role, filename, text, mode)
+	return nil
+}
+
+// Stages a file, replacing any existing one.
+func (w *fileWriter) replace(role string, filename string, text string) {
+	w.stage(role, filename, text, fileMode)
+}
+
+func (w *fileWriter) stage(role string, filename string, text string, mode os.FileMode) {
+	w.staged = append(w.staged, stagedFile{role: role, filename: filename, text: strings.TrimLeft(text, "\n"), mode: mode})
+}
+
+func (w *fileWriter) commit() error {
+	for _, file := range w.staged {
+		err := w.mkdirs(filepath.Dir(file.filename))
+		if err == nil {
+			err = writeStagedFile(file.filename+tempSuffix, []byte(file.text), file.mode)
+		}
+		// The mode is set explicitly as the umask applies when the file is created.
+		if err == nil {
+			err = os.Chmod(file.filename+tempSuffix, file.mode)
+		}
+		if err != nil {
+			w.rollback(0)
+			return err
+		}
+	}
+
+	for i := range w.staged {
+		file := &w.staged[i]
+		ioutils.Debugf("writing %s\n", file.filename)
+		if osutils.FileExists(file.filename) {
+			if err := renameFile(file.filename, file.filename+backupSuffix); err != nil {
+				// The existing file is still in place, it must not be removed.
+				w.rollback(i)
+				return err
+			}
+			file.replaced = true
+		}
+		if err := renameFile(file.filename+tempSuffix, file.filename); err != nil {
+			w.rollback(i + 1)
+			return err
+		}
+	}
+
+	for _, file := range w.staged {
+		if file.replaced {
+			os.Remove(file.filename + backupSuffix)
+		}
+	}
+	return nil
+}
+
+/*
+ * Writes a file scaffolded for the function, such as a stub from an OpenAPI operation, skipping an existing file
+ * unless --force is set. An executable script is written with the --executable-mode.
+ */
+func WriteScaffold(opts options.InitOptions, filename string, text string, executable bool) error {
+	writer := fileWriter{opts: opts}
+	var err error
+	if executable {
+		err = writer.addExecutable(RoleScript, filename, text)
+	} else {
+		err = writer.add(RoleFunctionStub, filename, text)
+	}
+	if err != nil {
+		return err
+	}
+	return writer.commit()
+}
+
+// A file written by the last commit, as listed by the --manifest-out manifest.
+type ManifestEntry struct {
+	Path    string `json:"path"`
+	Role    string `json:"role"`
+	Updated bool   `json:"updated"`
+}
+
+type Manifest struct {
+	Files []ManifestEntry `json:"files"`
+}
+
+/*
+ * Writes the manifest of the committed files to manifestPath, their paths relative to workdir, for CI to
+ * track and clean up what was generated.
+ */
+func (w *fileWriter) writeManifest(manifestPath string, workdir string) error {
+	manifest := Manifest{Files: []ManifestEntry{}}
+	for _, file := range w.staged {
+		path := file.filename
+		if rel, err := filepath.Rel(workdir, file.filename); err == nil {
+			path = rel
+		}
+		manifest.Files = append(manifest.Files, ManifestEntry{Path: filepath.ToSlash(path), Role: file.role, Updated: file.replaced})
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(manifestPath, append(content, '\n'), fileMode)
+}
+
+// Undoes the first renamed files and removes all staged files and created directories.
+func (w *fileWriter) rollback(renamed int) {
+	for i, file := range w.staged {
+		os.Remove(file.filename + tempSuffix)
+		if i < renamed {
+			os.Remove(file.filename)
+		}
+		if file.replaced {
+			os.Rename(file.filename+backupSuffix, file.filename)
+		}
+	}
+	for i := len(w.dirs) - 1; i >= 0; i-- {
+		os.Remove(w.dirs[i])
+	}
+}
+
+// Creates dir and its missing parents, remembering them so that a rollback can remove them.
+func (w *fileWriter) mkdirs(dir string) error {
+	var missing []string
+	for current := dir; !osutils.FileExists(current); current = filepath.Dir(current) {
+		missing = append(missing, current)
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := os.Mkdir(missing[i], 0755); err != nil {
+			return err
+		}
+		w.dirs = append(w.dirs, missing[i])
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailedGenerationLeavesNoFiles(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-writer")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	writes := 0
+	writeStagedFile = func(filename string, data []byte, perm os.FileMode) error {
+		writes++
+		if writes > 1 {
+			return errors.New("disk full")
+		}
+		return ioutil.WriteFile(filename, data, perm)
+	}
+	defer func() { writeStagedFile = ioutil.WriteFile }()
+
+	opts := options.InitOptions{FunctionName: "myfunc", Input: "in", Protocol: "stdio", UserAccount: "me", Version: "0.0.1", Helm: true}
+	generator := ArtifactsGenerator{
+		GenerateFunction:   DefaultGenerateFunction,
+		GenerateDockerFile: func(options.InitOptions) (string, error) { return "FROM scratch\n", nil },
+	}
+
+	err = GenerateFunctionArtfacts(generator, dir, opts)
+	as.EqualError(err, "disk full")
+	files, err := ioutil.ReadDir(dir)
+	as.NoError(err)
+	as.Empty(files)
+}
+
+func TestFailedBackupKeepsExistingFiles(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-writer")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n"), 0644))
+
+	renameFile = func(from string, to string) error {
+		if strings.HasSuffix(to, backupSuffix) {
+			return errors.New("permission denied")
+		}
+		return os.Rename(from, to)
+	}
+	defer func() { renameFile = os.Rename }()
+
+	writer := fileWriter{opts: options.InitOptions{Force: true}}
+	as.NoError(writer.add(RoleFunctionResource, filepath.Join(dir, "myfunc-function.yaml"), "kind: Function\n"))
+	as.NoError(writer.add(RoleDockerfile, filepath.Join(dir, "Dockerfile"), "FROM scratch\n"))
+	as.EqualError(writer.commit(), "permission denied")
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "Dockerfile"))
+	as.NoError(err)
+	as.Equal("FROM alpine\n", string(content))
+	files, err := ioutil.ReadDir(dir)
+	as.NoError(err)
+	as.Len(files, 1, "the files renamed before the failure are removed")
+}
+
+func TestGeneratedFilesAreWritten(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-writer")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	opts := options.InitOptions{FunctionName: "myfunc", Input: "in", Protocol: "stdio", UserAccount: "me", Version: "0.0.1", Helm: true}
+	generator := ArtifactsGenerator{
+		GenerateFunction:   DefaultGenerateFunction,
+		GenerateDockerFile: func(options.InitOptions) (string, error) { return "FROM scratch\n", nil },
+	}
+
+	as.NoError(GenerateFunctionArtfacts(generator, dir, opts))
+	for _, file := range []string{"myfunc-topics.yaml", "myfunc-function.yaml", "Dockerfile", filepath.Join("chart", "templates", "function.yaml")} {
+		_, err := os.Stat(filepath.Join(dir, file))
+		as.NoError(err, file)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(dir, "*.riff-*"))
+	as.Empty(leftovers)
+}
+
+func TestOnlySelectedArtifactsAreWritten(t *testing.T) {
+	as := assert.New(t)
+	generator := ArtifactsGenerator{
+		GenerateFunction:   DefaultGenerateFunction,
+		GenerateDockerFile: func(options.InitOptions) (string, error) { return "FROM scratch\n", nil },
+	}
+
+	for only, expected := range map[string][]string{
+		options.OnlyDockerfile: {"Dockerfile"},
+		options.OnlyResources:  {"myfunc-function.yaml", "myfunc-topics.yaml"},
+	} {
+		dir, err := ioutil.TempDir("", "riff-writer")
+		as.NoError(err)
+		defer os.RemoveAll(dir)
+
+		opts := options.InitOptions{FunctionName: "myfunc", Input: "in", Protocol: "stdio", UserAccount: "me", Version: "0.0.1", Helm: true, Only: only}
+		as.NoError(GenerateFunctionArtfacts(generator, dir, opts))
+		written, err := ioutil.ReadDir(dir)
+		as.NoError(err)
+		var names []string
+		for _, file := range written {
+			names = append(names, file.Name())
+		}
+		as.Equal(expected, names, only)
+	}
+
+	opts := options.InitOptions{FunctionName: "myfunc", Only: "chart"}
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "unknown kind of artifacts chart for --only, use one of dockerfile, resources")
+}
+
+func TestFileModes(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-writer")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	writer := fileWriter{opts: options.InitOptions{}}
+	as.NoError(writer.add(RoleDockerfile, filepath.Join(dir, "Dockerfile"), "FROM scratch\n"))
+	as.NoError(writer.addExecutable(RoleScript, filepath.Join(dir, "square.sh"), "#!/bin/sh\ncat\n"))
+	as.NoError(writer.commit())
+
+	info, err := os.Stat(filepath.Join(dir, "Dockerfile"))
+	as.NoError(err)
+	as.Equal(os.FileMode(0644), info.Mode().Perm())
+	info, err = os.Stat(filepath.Join(dir, "square.sh"))
+	as.NoError(err)
+	as.Equal(os.FileMode(0755), info.Mode().Perm(), "a scaffolded shell stub is executable")
+}
+
+func TestExecutableMode(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-writer")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	writer := fileWriter{opts: options.InitOptions{ExecutableMode: "0700"}}
+	as.NoError(writer.addExecutable(RoleScript, filepath.Join(dir, "square.sh"), "#!/bin/sh\ncat\n"))
+	as.NoError(writer.commit())
+
+	info, err := os.Stat(filepath.Join(dir, "square.sh"))
+	as.NoError(err)
+	as.Equal(os.FileMode(0700), info.Mode().Perm())
+}
+
+func TestManifestListsWrittenFiles(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-writer")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	manifestPath := filepath.Join(dir, "riff-manifest.json")
+
+	opts := options.InitOptions{FunctionName: "myfunc", Input: "in", Protocol: "stdio", UserAccount: "me", Version: "0.0.1", Helm: true, ManifestOut: manifestPath}
+	generator := ArtifactsGenerator{
+		GenerateFunction:   DefaultGenerateFunction,
+		GenerateDockerFile: func(options.InitOptions) (string, error) { return "FROM scratch\n", nil },
+	}
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n"), 0644))
+	as.NoError(GenerateFunctionArtfacts(generator, dir, opts))
+
+	content, err := ioutil.ReadFile(manifestPath)
+	as.NoError(err)
+	var manifest Manifest
+	as.NoError(json.Unmarshal(content, &manifest))
+	as.Equal([]ManifestEntry{
+		{Path: "myfunc-topics.yaml", Role: RoleTopicResource},
+		{Path: "myfunc-function.yaml", Role: RoleFunctionResource},
+		{Path: "chart/Chart.yaml", Role: RoleHelmChart},
+		{Path: "chart/templates/function.yaml", Role: RoleHelmChart},
+		{Path: "chart/values.yaml", Role: RoleHelmChart},
+	}, manifest.Files, "the existing Dockerfile is skipped without --force")
+
+	opts.Force = true
+	opts.Helm = false
+	as.NoError(GenerateFunctionArtfacts(generator, dir, opts))
+	content, err = ioutil.ReadFile(manifestPath)
+	as.NoError(err)
+	as.NoError(json.Unmarshal(content, &manifest))
+	as.Equal([]ManifestEntry{
+		{Path: "myfunc-topics.yaml", Role: RoleTopicResource, Updated: true},
+		{Path: "myfunc-function.yaml", Role: RoleFunctionResource, Updated: true},
+		{Path: "Dockerfile", Role: RoleDockerfile, Updated: true},
+	}, manifest.Files)
+}
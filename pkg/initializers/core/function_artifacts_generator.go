@@ -17,41 +17,116 @@
 package core
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/projectriff/riff-cli/pkg/ioutils"
 	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
 	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
-	"github.com/projectriff/riff-cli/pkg/osutils"
-)
+	"time"
 
-const (
-	ApiVersion = "projectriff.io/v1"
+	"gopkg.in/yaml.v2"
 )
 
+
 type FunctionResources struct {
-	Topics     string
-	Function   string
-	DockerFile string
+	Topics          string
+	Function        string
+	DockerFile      string
+	Workflow        string
+	DevContainer    string
+	ScaledObject    string
+	Kustomization   string
+	TektonTask      string
+	ArgoApplication string
+	HelmChart       map[string]string
+	GitIgnore       string
+	EnvFile         string
 }
 
 type Function struct {
-	ApiVersion string
-	Name       string
-	Input      string
-	Output     string
-	Image      string
-	Protocol   string
+	ApiVersion  string
+	Name        string
+	Namespace   string
+	Input       []string
+	Output      []string
+	Image       string
+	Protocol    string
+	Secrets     []string
+	ConfigMaps  []string
+	Port        int
+	Requests    map[string]string
+	Limits      map[string]string
+	Timeout     string
+	ContentMode string
+	Annotations map[string]string
+	Readiness   *Probe
+	Liveness    *Probe
+	MinScale    int
+	MaxScale    int
+	Replicas    *int
+}
+
+// An HTTP probe of the function container, its timings in seconds being left to Kubernetes when 0.
+type Probe struct {
+	Path         string
+	Port         int
+	InitialDelay int
+	Period       int
+	Timeout      int
 }
 
+// The annotation recording the function invocation timeout.
+const TimeoutAnnotation = "projectriff.io/timeout"
+
+// The annotation recording how events are encoded, structured for functions speaking CloudEvents.
+const ContentModeAnnotation = "projectriff.io/content-mode"
+
 type ArtifactsGenerator struct {
+	Language           string
 	GenerateFunction   func(options.InitOptions) (string, error)
 	GenerateDockerFile func(options.InitOptions) (string, error)
+	// What the build ARGs the FUNCTION_URI references are, for --explain-uri.
+	FunctionUriArgs    map[string]string
 }
 
+// How long a --pre-hook or --post-hook command may run.
+const hookTimeout = 5 * time.Minute
+
 func GenerateFunctionArtfacts(generator ArtifactsGenerator, workdir string, opts options.InitOptions) error {
 	var functionResources FunctionResources
-	var err error
+	resolver, err := ResolveArtifact(opts.Artifact)
+	if err != nil {
+		return err
+	}
+	if opts.PreHook != "" && !opts.DryRun {
+		err = runHook("pre-hook", opts.PreHook, workdir, opts.Strict)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.LintSource {
+		err = lintSource(generator.Language, workdir, opts, resolver)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.VerifyHandler {
+		err = verifyHandler(generator.Language, workdir, opts, resolver)
+		if err != nil {
+			return err
+		}
+	}
 	functionResources.Topics, err = createTopics(opts)
 	if err != nil {
 		return err
@@ -64,51 +139,445 @@ func GenerateFunctionArtfacts(generator ArtifactsGenerator, workdir string, opts
 	if err != nil {
 		return err
 	}
+	// A JSON dry run only prints the report.
+	if opts.ExplainUri && opts.OutputFormat != options.OutputFormatJson {
+		explanation, err := ExplainFunctionUri(functionResources.DockerFile, generator.FunctionUriArgs)
+		if err != nil {
+			err = options.Warning(opts.Strict, fmt.Sprintf("cannot explain the FUNCTION_URI: %v", err))
+			if err != nil {
+				return err
+			}
+		} else {
+			ioutils.Infof("%s", explanation)
+		}
+	}
+	if opts.Keda {
+		functionResources.ScaledObject, err = generateKedaScaledObject(opts)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.Kustomize {
+		functionResources.Kustomization, err = generateKustomization(opts)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.Tekton {
+		functionResources.TektonTask, err = generateTektonTask(opts)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.ArgoCD {
+		functionResources.ArgoApplication, err = generateArgoApplication(opts)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.Helm {
+		functionResources.HelmChart, err = generateHelmChart(opts)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.GitIgnore {
+		functionResources.GitIgnore, err = mergeGitIgnore(filepath.Join(workdir, ".gitignore"), gitignorePatterns(generator.Language))
+		if err != nil {
+			return err
+		}
+	}
+	if opts.EnvFile {
+		functionResources.EnvFile, err = generateEnvFile(opts, functionResources.DockerFile)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.BaseImageAllowlist != "" {
+		err = verifyBaseImages(functionResources.DockerFile, opts)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.GitHubActions {
+		functionResources.Workflow, err = generateGitHubWorkflow(workdir, opts)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.DevContainer {
+		functionResources.DevContainer, err = generateDevContainer(generator.Language, opts)
+		if err != nil {
+			return err
+		}
+	}
+	err = checkGeneratedYaml(functionResources)
+	if err != nil {
+		return err
+	}
 
-	if opts.DryRun {
-		fmt.Println("Generated Topics:\n")
-		fmt.Printf("%s\n", functionResources.Topics)
-		fmt.Println("\nGenerated Function:\n")
-		fmt.Printf("%s\n", functionResources.Function)
-		fmt.Println("\nGenerated Dockerfile:\n")
-		fmt.Printf("%s\n", functionResources.DockerFile)
-	} else {
-		var err error
-		err = writeFile(
-			filepath.Join(workdir,
-				fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "topics")),
-			functionResources.Topics,
-			opts.Force)
+	// With --only, the other artifacts are still generated and checked, but neither shown nor written.
+	if opts.Only != "" {
+		opts.GitHubActions = false
+		opts.DevContainer = false
+		opts.Tekton = false
+		opts.ArgoCD = false
+		opts.EnvFile = false
+		functionResources.GitIgnore = ""
+		functionResources.HelmChart = nil
+	}
+
+	// The image reference is written even in dry-run mode, so CI can learn it without generating anything.
+	if opts.ImageRefOut != "" {
+		err = ioutil.WriteFile(opts.ImageRefOut, []byte(options.ImageName(opts)+"\n"), 0644)
 		if err != nil {
 			return err
 		}
+	}
 
-		err = writeFile(
-			filepath.Join(workdir,
-				fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "function")),
-			functionResources.Function,
-			opts.Force)
+	if opts.DryRun && opts.OutputFormat == options.OutputFormatJson {
+		return printDryRunJson(workdir, opts, functionResources)
+	} else if opts.DryRun {
+		if writes(opts, options.OnlyResources) {
+			fmt.Print("Generated Topics:\n\n")
+			fmt.Printf("%s\n", functionResources.Topics)
+			fmt.Print("\nGenerated Function:\n\n")
+			fmt.Printf("%s\n", functionResources.Function)
+			if opts.Keda {
+				fmt.Print("\nGenerated ScaledObject:\n\n")
+				fmt.Printf("%s\n", functionResources.ScaledObject)
+			}
+			if opts.Kustomize {
+				fmt.Printf("\nGenerated %s:\n\n", KustomizationFile)
+				fmt.Printf("%s\n", functionResources.Kustomization)
+			}
+		}
+		if writes(opts, options.OnlyDockerfile) {
+			fmt.Print("\nGenerated Dockerfile:\n\n")
+			fmt.Printf("%s\n", functionResources.DockerFile)
+		}
+		if opts.GitHubActions {
+			fmt.Print("\nGenerated GitHub Actions workflow:\n\n")
+			fmt.Printf("%s\n", functionResources.Workflow)
+		}
+		if opts.DevContainer {
+			fmt.Printf("\nGenerated %s:\n\n", filepath.Join(DevContainerDir, "devcontainer.json"))
+			fmt.Printf("%s\n", functionResources.DevContainer)
+		}
+		if opts.Tekton {
+			fmt.Printf("\nGenerated Tekton task %s:\n\n", filepath.Base(tektonTaskPath(workdir, opts)))
+			fmt.Printf("%s\n", functionResources.TektonTask)
+		}
+		if opts.ArgoCD {
+			fmt.Printf("\nGenerated ArgoCD Application %s:\n\n", filepath.Base(argoApplicationPath(workdir, opts)))
+			fmt.Printf("%s\n", functionResources.ArgoApplication)
+		}
+		if opts.EnvFile {
+			fmt.Printf("\nGenerated %s:\n\n", EnvFile)
+			fmt.Printf("%s\n", functionResources.EnvFile)
+		}
+		if functionResources.GitIgnore != "" {
+			fmt.Print("\nGenerated .gitignore:\n\n")
+			fmt.Printf("%s\n", functionResources.GitIgnore)
+		}
+		for _, path := range sortedKeys(functionResources.HelmChart) {
+			fmt.Printf("\nGenerated Helm chart %s:\n\n", filepath.Join(HelmChartDir, path))
+			fmt.Printf("%s\n", functionResources.HelmChart[path])
+		}
+	} else {
+		writer := fileWriter{opts: opts}
+		if writes(opts, options.OnlyResources) {
+			err = writer.add(RoleTopicResource,
+				filepath.Join(workdir,
+					fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "topics")),
+				functionResources.Topics)
+			if err != nil {
+				return err
+			}
+
+			err = writer.add(RoleFunctionResource,
+				filepath.Join(workdir,
+					fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "function")),
+				functionResources.Function)
+			if err != nil {
+				return err
+			}
+
+			if opts.Keda {
+				err = writer.add(RoleScaledObjectResource,
+					filepath.Join(workdir,
+						fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "scaledobject")),
+					functionResources.ScaledObject)
+				if err != nil {
+					return err
+				}
+			}
+
+			if opts.Kustomize {
+				err = writer.add(RoleKustomization, filepath.Join(workdir, KustomizationFile), functionResources.Kustomization)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if writes(opts, options.OnlyDockerfile) {
+			err = writer.add(RoleDockerfile,
+				filepath.Join(workdir, "Dockerfile"),
+				functionResources.DockerFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		if opts.GitHubActions {
+			workflowPath, err := gitHubWorkflowPath(workdir, opts)
+			if err != nil {
+				return err
+			}
+			err = writer.add(RoleGitHubWorkflow, workflowPath, functionResources.Workflow)
+			if err != nil {
+				return err
+			}
+		}
+
+		if opts.DevContainer {
+			err = writer.add(RoleDevContainer, devContainerPath(workdir), functionResources.DevContainer)
+			if err != nil {
+				return err
+			}
+		}
+
+		if opts.Tekton {
+			err = writer.add(RoleTektonTask, tektonTaskPath(workdir, opts), functionResources.TektonTask)
+			if err != nil {
+				return err
+			}
+		}
+
+		if opts.ArgoCD {
+			err = writer.add(RoleArgoApplication, argoApplicationPath(workdir, opts), functionResources.ArgoApplication)
+			if err != nil {
+				return err
+			}
+		}
+
+		if opts.EnvFile {
+			err = writer.add(RoleEnvFile, envFilePath(workdir), functionResources.EnvFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		// The .gitignore is merged with any existing one, so it is written even without --force.
+		if functionResources.GitIgnore != "" {
+			writer.replace(RoleGitIgnore, filepath.Join(workdir, ".gitignore"), functionResources.GitIgnore)
+		}
+
+		for _, path := range sortedKeys(functionResources.HelmChart) {
+			err = writer.add(RoleHelmChart, filepath.Join(workdir, HelmChartDir, path), functionResources.HelmChart[path])
+			if err != nil {
+				return err
+			}
+		}
+
+		err = writer.commit()
 		if err != nil {
 			return err
 		}
+		if opts.ManifestOut != "" {
+			err = writer.writeManifest(opts.ManifestOut, workdir)
+			if err != nil {
+				return err
+			}
+		}
+		if opts.PostHook != "" {
+			return runHook("post-hook", opts.PostHook, workdir, opts.Strict)
+		}
+	}
+	return nil
+}
+
+// The separator of the documents of a YAML stream.
+var yamlDocumentSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
 
-		err = writeFile(
-			filepath.Join(workdir, "Dockerfile"),
-			functionResources.DockerFile,
-			opts.Force)
+/*
+ * Parses the generated YAML, so that a template regression fails generation rather than applying the
+ * resources. The Helm chart templates are not checked, as Helm only makes them YAML when rendering them.
+ */
+func checkGeneratedYaml(functionResources FunctionResources) error {
+	generated := map[string]string{
+		"topics":                  functionResources.Topics,
+		"function":                functionResources.Function,
+		"scaled object":           functionResources.ScaledObject,
+		KustomizationFile:         functionResources.Kustomization,
+		"Tekton task":             functionResources.TektonTask,
+		"ArgoCD Application":      functionResources.ArgoApplication,
+		"GitHub Actions workflow": functionResources.Workflow,
+	}
+	for path, content := range functionResources.HelmChart {
+		if filepath.Dir(path) == "." {
+			generated[filepath.Join(HelmChartDir, path)] = content
+		}
+	}
+	for _, name := range sortedKeys(generated) {
+		for _, document := range yamlDocumentSeparator.Split(generated[name], -1) {
+			var parsed map[string]interface{}
+			if err := yaml.Unmarshal([]byte(document), &parsed); err != nil {
+				return errors.New(fmt.Sprintf("generated %s is not valid YAML: %v", name, err))
+			}
+		}
+	}
+	return nil
+}
+
+// What a JSON dry run prints: the detected language, when it was, and the generated files by path.
+type dryRunReport struct {
+	*options.DetectionReport
+	Files map[string]string `json:"files"`
+}
+
+/*
+ * Prints the artifacts a dry run shows as a single JSON object, for CI to read. The files are keyed by their
+ * path relative to workdir.
+ */
+func printDryRunJson(workdir string, opts options.InitOptions, functionResources FunctionResources) error {
+	files := map[string]string{}
+	if writes(opts, options.OnlyResources) {
+		files[fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "topics")] = functionResources.Topics
+		files[fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "function")] = functionResources.Function
+		if opts.Keda {
+			files[fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "scaledobject")] = functionResources.ScaledObject
+		}
+		if opts.Kustomize {
+			files[KustomizationFile] = functionResources.Kustomization
+		}
+	}
+	if writes(opts, options.OnlyDockerfile) {
+		files["Dockerfile"] = functionResources.DockerFile
+	}
+	if opts.GitHubActions {
+		workflowPath, err := gitHubWorkflowPath(workdir, opts)
 		if err != nil {
 			return err
 		}
+		if rel, err := filepath.Rel(workdir, workflowPath); err == nil {
+			workflowPath = rel
+		}
+		files[filepath.ToSlash(workflowPath)] = functionResources.Workflow
+	}
+	if opts.DevContainer {
+		files[path.Join(DevContainerDir, "devcontainer.json")] = functionResources.DevContainer
+	}
+	if opts.Tekton {
+		files[path.Join(TektonDir, filepath.Base(tektonTaskPath(workdir, opts)))] = functionResources.TektonTask
+	}
+	if opts.ArgoCD {
+		files[path.Join(ArgoCDDir, filepath.Base(argoApplicationPath(workdir, opts)))] = functionResources.ArgoApplication
+	}
+	if opts.EnvFile {
+		files[EnvFile] = functionResources.EnvFile
+	}
+	if functionResources.GitIgnore != "" {
+		files[".gitignore"] = functionResources.GitIgnore
+	}
+	for file, content := range functionResources.HelmChart {
+		files[path.Join(HelmChartDir, filepath.ToSlash(file))] = content
+	}
+	for file, content := range files {
+		files[file] = strings.TrimLeft(content, "\n")
 	}
+
+	report, err := json.MarshalIndent(dryRunReport{DetectionReport: opts.Detection, Files: files}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(report))
 	return nil
 }
 
-func writeFile(filename string, text string, overwrite bool) error {
-	if !overwrite && osutils.FileExists(filename) {
-		fmt.Printf("skipping existing file %s  - set --force to overwrite.\n", filename)
-		return nil
+// Whether the artifacts of the kind are written: all are, unless --only selects another kind.
+func writes(opts options.InitOptions, kind string) bool {
+	return opts.Only == "" || opts.Only == kind
+}
+
+/*
+ * Runs a hook command with the shell in dir, printing its output. What a successful hook writes to stderr is
+ * reported as a warning.
+ */
+func runHook(kind string, command string, dir string, strict bool) error {
+	ioutils.Debugf("running %s %s in %s\n", kind, command, dir)
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
 
-	} else {
-		return ioutil.WriteFile(filename, []byte(strings.TrimLeft(text, "\n")), 0644)
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	ioutils.Infof("%s", out)
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.New(fmt.Sprintf("%s '%s' timed out after %v", kind, command, hookTimeout))
+	}
+	if err != nil {
+		ioutils.Errorf("%s", stderr.String())
+		return errors.New(fmt.Sprintf("%s '%s' failed: %v", kind, command, err))
+	}
+	if stderr.Len() > 0 {
+		return options.Warning(strict, fmt.Sprintf("%s '%s': %s", kind, command, strings.TrimSpace(stderr.String())))
+	}
+	return nil
+}
+
+/*
+ * The generated artifacts of the function found in workdir: its resource definitions and kustomization,
+ * Dockerfile, .env file, Helm chart, devcontainer configuration, Tekton task, ArgoCD Application and GitHub
+ * Actions workflow.
+ */
+func GeneratedFiles(workdir string, opts options.InitOptions) ([]string, error) {
+	candidates := []string{
+		filepath.Join(workdir, fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "topics")),
+		filepath.Join(workdir, fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "function")),
+		filepath.Join(workdir, fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "scaledobject")),
+		filepath.Join(workdir, KustomizationFile),
+		filepath.Join(workdir, "Dockerfile"),
+		envFilePath(workdir),
+		devContainerPath(workdir),
+		tektonTaskPath(workdir, opts),
+		argoApplicationPath(workdir, opts),
+	}
+	workflowPath, err := gitHubWorkflowPath(workdir, opts)
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates, workflowPath)
+
+	var files []string
+	for _, file := range candidates {
+		if osutils.FileExists(file) {
+			files = append(files, file)
+		}
+	}
+	chartDir := filepath.Join(workdir, HelmChartDir)
+	if osutils.IsDirectory(chartDir) {
+		err = filepath.Walk(chartDir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && info.Mode().IsRegular() {
+				files = append(files, path)
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func sortedKeys(files map[string]string) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+	return keys
 }
@@ -19,40 +19,172 @@ package core
 import (
 	"github.com/projectriff/riff-cli/pkg/options"
 	"bytes"
+	"io/ioutil"
 	"text/template"
 )
 
-//TODO: Kludgy '-' used to supress blank line, {{else}} adds a new line.
+/*
+ * A single input or output topic is rendered as a plain name, several as a list. The scaling bounds are left
+ * to the function controller when 0, while a fixed replica count is rendered whenever given, even 0.
+ */
 var functionTemplate = `
 apiVersion: {{.ApiVersion}}
 kind: Function
 metadata:
   name: {{.Name}}
+{{- if .Namespace }}
+  namespace: {{.Namespace}}
+{{- end }}
+{{- if or .Timeout .ContentMode .Annotations }}
+  annotations:
+{{- if .Timeout }}
+    projectriff.io/timeout: "{{.Timeout}}"
+{{- end }}
+{{- if .ContentMode }}
+    projectriff.io/content-mode: {{.ContentMode}}
+{{- end }}
+{{- range $key, $value := .Annotations }}
+    {{$key}}: {{printf "%q" $value}}
+{{- end }}
+{{- end }}
 spec:
   protocol: {{.Protocol}}
-  input: {{.Input}}
-{{- if .Output}} 
-  output: {{.Output}}
-{{ else }}{{ end }}
+{{- if eq (len .Input) 1 }}
+  input: {{index .Input 0}}
+{{- else if .Input }}
+  input:
+{{- range .Input }}
+  - {{.}}
+{{- end }}
+{{- end }}
+{{- if eq (len .Output) 1 }}
+  output: {{index .Output 0}}
+{{- else if .Output }}
+  output:
+{{- range .Output }}
+  - {{.}}
+{{- end }}
+{{- end }}
+{{- if .MinScale }}
+  minReplicas: {{.MinScale}}
+{{- end }}
+{{- if .MaxScale }}
+  maxReplicas: {{.MaxScale}}
+{{- end }}
+{{- if .Replicas }}
+  replicas: {{.Replicas}}
+{{- end }}
   container:
     image: {{.Image}}
+{{- if .Port }}
+    ports:
+    - containerPort: {{.Port}}
+{{- end }}
+{{- if or .Requests .Limits }}
+    resources:
+{{- if .Requests }}
+      requests:
+{{- range $name, $quantity := .Requests }}
+        {{$name}}: {{$quantity}}
+{{- end }}
+{{- end }}
+{{- if .Limits }}
+      limits:
+{{- range $name, $quantity := .Limits }}
+        {{$name}}: {{$quantity}}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- with .Readiness }}
+    readinessProbe:
+{{- template "probe" . }}
+{{- end }}
+{{- with .Liveness }}
+    livenessProbe:
+{{- template "probe" . }}
+{{- end }}
+{{- if or .ConfigMaps .Secrets }}
+    envFrom:
+{{- range .ConfigMaps }}
+    - configMapRef:
+        name: {{.}}
+{{- end }}
+{{- range .Secrets }}
+    - secretRef:
+        name: {{.}}
+{{- end }}
+{{- end }}
 `
 
+// The readiness and liveness probes share their stanza, which a custom resource template may use too.
+var probeTemplate = `
+{{- define "probe" }}
+      httpGet:
+        path: {{.Path}}
+        port: {{.Port}}
+{{- if .InitialDelay }}
+      initialDelaySeconds: {{.InitialDelay}}
+{{- end }}
+{{- if .Period }}
+      periodSeconds: {{.Period}}
+{{- end }}
+{{- if .Timeout }}
+      timeoutSeconds: {{.Timeout}}
+{{- end }}
+{{- end }}`
+
 func DefaultGenerateFunction(opts options.InitOptions) (string, error) {
 	function := Function{
-		ApiVersion: ApiVersion,
-		Name:       opts.FunctionName,
-		Input:      opts.Input,
-		Output:     opts.Output,
-		Protocol:   opts.Protocol,
-		Image:      options.ImageName(opts),
+		ApiVersion:  options.ApiVersion(opts),
+		Name:        options.ResourceName(opts),
+		Namespace:   opts.Namespace,
+		Input:       options.Topics(opts.Input),
+		Output:      options.Topics(opts.Output),
+		Protocol:    opts.Protocol,
+		Image:       options.ImageName(opts),
+		Secrets:     opts.Secrets,
+		ConfigMaps:  opts.ConfigMaps,
+		Port:        options.FunctionPort(opts),
+		Requests:    resourceList(opts.CpuRequest, opts.MemoryRequest),
+		Limits:      resourceList(opts.CpuLimit, opts.MemoryLimit),
+		Timeout:     opts.Timeout,
+		Annotations: options.Annotations(opts),
+		Readiness:   probe(opts.ReadinessPath, opts),
+		Liveness:    probe(opts.LivenessPath, opts),
+		MinScale:    opts.MinScale,
+		MaxScale:    opts.MaxScale,
+		Replicas:    opts.Replicas,
+	}
+	if opts.CloudEvents {
+		function.ContentMode = "structured"
+	}
+	// The annotations set by their own flags are not repeated.
+	if function.Timeout != "" {
+		delete(function.Annotations, TimeoutAnnotation)
+	}
+	if function.ContentMode != "" {
+		delete(function.Annotations, ContentModeAnnotation)
 	}
 
 	var tmpl *template.Template
 	var err error
 	var buffer bytes.Buffer
 
-	tmpl, err = template.New("function").Parse(functionTemplate)
+	// A custom resource template is given the same Function as the built-in one.
+	text := functionTemplate
+	if opts.ResourceTemplate != "" {
+		content, err := ioutil.ReadFile(opts.ResourceTemplate)
+		if err != nil {
+			return "", err
+		}
+		text = string(content)
+	}
+
+	tmpl, err = template.New("function").Parse(probeTemplate)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err = tmpl.Parse(text)
 	if err != nil {
 		return "", err
 	}
@@ -63,3 +195,32 @@ func DefaultGenerateFunction(opts options.InitOptions) (string, error) {
 	return buffer.String(), nil
 }
 
+
+// The probe of the path, or nil when the path is not set.
+func probe(path string, opts options.InitOptions) *Probe {
+	if path == "" {
+		return nil
+	}
+	return &Probe{
+		Path:         path,
+		Port:         options.FunctionPort(opts),
+		InitialDelay: opts.ProbeInitialDelay,
+		Period:       opts.ProbePeriod,
+		Timeout:      opts.ProbeTimeout,
+	}
+}
+
+// The cpu and memory quantities that are set, or nil when neither is.
+func resourceList(cpu string, memory string) map[string]string {
+	if cpu == "" && memory == "" {
+		return nil
+	}
+	resources := map[string]string{}
+	if cpu != "" {
+		resources["cpu"] = cpu
+	}
+	if memory != "" {
+		resources["memory"] = memory
+	}
+	return resources
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// The default value of a build ARG of a Dockerfile.
+var argRegexp = regexp.MustCompile(`^ARG\s+(\w+)=(.*)$`)
+
+// The FUNCTION_URI the invoker loads the function from.
+var functionUriRegexp = regexp.MustCompile(`^ENV\s+FUNCTION_URI[\s=]+(.*)$`)
+
+// A reference to a build ARG, as ${NAME} or $NAME.
+var argReferenceRegexp = regexp.MustCompile(`\$\{(\w+)\}|\$(\w+)`)
+
+/*
+ * Explains how the FUNCTION_URI of the Dockerfile is assembled: its value once the build ARGs it references
+ * are replaced by their defaults, broken down into scheme, path and query parameters. Each ARG is described
+ * by the description the language gives it, if any.
+ */
+func ExplainFunctionUri(dockerfile string, argDescriptions map[string]string) (string, error) {
+	args := map[string]string{}
+	uri := ""
+	for _, line := range strings.Split(dockerfile, "\n") {
+		line = strings.TrimSpace(line)
+		if match := argRegexp.FindStringSubmatch(line); match != nil {
+			args[match[1]] = unquote(match[2])
+		}
+		if match := functionUriRegexp.FindStringSubmatch(line); match != nil {
+			uri = unquote(match[1])
+		}
+	}
+	if uri == "" {
+		return "", errors.New("the Dockerfile sets no FUNCTION_URI")
+	}
+
+	var referenced []string
+	value := argReferenceRegexp.ReplaceAllStringFunc(uri, func(reference string) string {
+		match := argReferenceRegexp.FindStringSubmatch(reference)
+		name := match[1] + match[2]
+		arg, ok := args[name]
+		if !ok {
+			return reference
+		}
+		referenced = append(referenced, name)
+		return arg
+	})
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("invalid FUNCTION_URI %s: %v", value, err))
+	}
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "FUNCTION_URI %s\n", uri)
+	if value != uri {
+		fmt.Fprintf(&buffer, "  is %s\n", value)
+	}
+	if parsed.Scheme != "" {
+		fmt.Fprintf(&buffer, "  scheme: %s\n", parsed.Scheme)
+	} else {
+		fmt.Fprintf(&buffer, "  scheme: none, the path being a file of the image\n")
+	}
+	fmt.Fprintf(&buffer, "  path:   %s\n", parsed.Path)
+	if parsed.RawQuery != "" {
+		fmt.Fprintf(&buffer, "  query:\n")
+		for _, param := range strings.Split(parsed.RawQuery, "&") {
+			if unescaped, err := url.QueryUnescape(param); err == nil {
+				param = unescaped
+			}
+			fmt.Fprintf(&buffer, "    %s\n", param)
+		}
+	}
+	if len(referenced) > 0 {
+		fmt.Fprintf(&buffer, "  where the build args are\n")
+		for _, name := range referenced {
+			fmt.Fprintf(&buffer, "    %s=%s", name, args[name])
+			if description, ok := argDescriptions[name]; ok {
+				fmt.Fprintf(&buffer, ", %s", description)
+			}
+			fmt.Fprintf(&buffer, "\n")
+		}
+	}
+	return buffer.String(), nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainFunctionUriWithoutScheme(t *testing.T) {
+	as := assert.New(t)
+
+	explanation, err := ExplainFunctionUri("FROM projectriff/node-function-invoker:0.0.6\nENV FUNCTION_URI /functions/square.js\n", nil)
+	as.NoError(err)
+	as.Equal("FUNCTION_URI /functions/square.js\n  scheme: none, the path being a file of the image\n  path:   /functions/square.js\n", explanation)
+}
+
+func TestExplainFunctionUriWithQuotedArg(t *testing.T) {
+	as := assert.New(t)
+
+	explanation, err := ExplainFunctionUri("ARG FUNCTION_URI=\"/square.sh?interpreter=bash\"\nENV FUNCTION_URI $FUNCTION_URI\n", map[string]string{"FUNCTION_URI": "the script"})
+	as.NoError(err)
+	as.Contains(explanation, "  is /square.sh?interpreter=bash\n")
+	as.Contains(explanation, "  query:\n    interpreter=bash\n")
+	as.Contains(explanation, "    FUNCTION_URI=/square.sh?interpreter=bash, the script\n")
+}
+
+func TestExplainFunctionUriMissing(t *testing.T) {
+	as := assert.New(t)
+
+	_, err := ExplainFunctionUri("FROM alpine\n", nil)
+	as.EqualError(err, "the Dockerfile sets no FUNCTION_URI")
+}
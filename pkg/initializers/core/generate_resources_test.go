@@ -17,9 +17,14 @@
 package core
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"github.com/stretchr/testify/assert"
 	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
 	"gopkg.in/yaml.v2"
 )
 
@@ -38,8 +43,57 @@ func TestTopics(t *testing.T) {
 	as.Contains(topic, "name: out")
 }
 
+func TestMultipleTopics(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in1, in2",
+		Output:       "out1,out2,in1",
+	}
+	topics, err := createTopics(opts)
+	as.NoError(err)
+
+	var names []string
+	for _, doc := range strings.Split(topics, "---") {
+		topic := struct{ Metadata struct{ Name string } }{}
+		as.NoError(yaml.Unmarshal([]byte(doc), &topic))
+		names = append(names, topic.Metadata.Name)
+	}
+	as.Equal([]string{"in1", "in2", "out1", "out2"}, names)
+}
+
+type YMultiTopicFunction struct {
+	Spec struct {
+		Input  []string
+		Output []string
+	}
+}
+
+func TestFunctionWithMultipleTopics(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in1,in2",
+		Output:       "out1,out2",
+		Protocol:     "http",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+
+	yf := YMultiTopicFunction{}
+	err = yaml.Unmarshal([]byte(f), &yf)
+	as.NoError(err)
+	as.Equal([]string{"in1", "in2"}, yf.Spec.Input)
+	as.Equal([]string{"out1", "out2"}, yf.Spec.Output)
+}
+
 type YFunction struct {
-	ApiVersion string
+	ApiVersion string `yaml:"apiVersion"`
 	Kind       string
 	Metadata struct {
 		Name string
@@ -83,3 +137,604 @@ func TestFunction(t *testing.T) {
 	as.Equal("myfunc", yf.Metadata.Name)
 	as.Equal("me/myfunc:0.0.1", yf.Spec.Container.Image)
 }
+
+type YEnvFromFunction struct {
+	Spec struct {
+		Container struct {
+			EnvFrom []struct {
+				ConfigMapRef struct {
+					Name string
+				} `yaml:"configMapRef"`
+				SecretRef struct {
+					Name string
+				} `yaml:"secretRef"`
+			} `yaml:"envFrom"`
+		}
+	}
+}
+
+func TestFunctionWithSecretAndConfigMap(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in",
+		Protocol:     "http",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+		Secrets:      []string{"db-credentials"},
+		ConfigMaps:   []string{"app-settings"},
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+
+	yf := YEnvFromFunction{}
+	err = yaml.Unmarshal([]byte(f), &yf)
+	as.NoError(err)
+	if as.Len(yf.Spec.Container.EnvFrom, 2) {
+		as.Equal("app-settings", yf.Spec.Container.EnvFrom[0].ConfigMapRef.Name)
+		as.Equal("db-credentials", yf.Spec.Container.EnvFrom[1].SecretRef.Name)
+	}
+
+	opts.Secrets = nil
+	opts.ConfigMaps = nil
+	f, err = DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.NotContains(f, "envFrom")
+}
+
+type YPortsFunction struct {
+	Spec struct {
+		Container struct {
+			Ports []struct {
+				ContainerPort int `yaml:"containerPort"`
+			}
+		}
+	}
+}
+
+func TestFunctionWithPort(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in",
+		Protocol:     "grpc",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+		Port:         9090,
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+
+	yf := YPortsFunction{}
+	err = yaml.Unmarshal([]byte(f), &yf)
+	as.NoError(err)
+	if as.Len(yf.Spec.Container.Ports, 1) {
+		as.Equal(9090, yf.Spec.Container.Ports[0].ContainerPort)
+	}
+
+	opts.Port = 0
+	opts.Protocol = "stdio"
+	f, err = DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.NotContains(f, "ports:")
+}
+
+type YProbe struct {
+	HttpGet struct {
+		Path string
+		Port int
+	} `yaml:"httpGet"`
+	InitialDelaySeconds int `yaml:"initialDelaySeconds"`
+	PeriodSeconds       int `yaml:"periodSeconds"`
+	TimeoutSeconds      int `yaml:"timeoutSeconds"`
+}
+
+type YProbesFunction struct {
+	Spec struct {
+		Container struct {
+			ReadinessProbe *YProbe `yaml:"readinessProbe"`
+			LivenessProbe  *YProbe `yaml:"livenessProbe"`
+		}
+	}
+}
+
+func TestFunctionWithProbes(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in",
+		Protocol:     "http",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.NotContains(f, "Probe")
+
+	opts.ReadinessPath = "/ready"
+	opts.LivenessPath = "/healthz"
+	opts.ProbeInitialDelay = 5
+	opts.ProbePeriod = 15
+	f, err = DefaultGenerateFunction(opts)
+	as.NoError(err)
+
+	yf := YProbesFunction{}
+	as.NoError(yaml.Unmarshal([]byte(f), &yf))
+	if as.NotNil(yf.Spec.Container.ReadinessProbe) {
+		as.Equal("/ready", yf.Spec.Container.ReadinessProbe.HttpGet.Path)
+		as.Equal(8080, yf.Spec.Container.ReadinessProbe.HttpGet.Port)
+		as.Equal(5, yf.Spec.Container.ReadinessProbe.InitialDelaySeconds)
+		as.Equal(15, yf.Spec.Container.ReadinessProbe.PeriodSeconds)
+	}
+	if as.NotNil(yf.Spec.Container.LivenessProbe) {
+		as.Equal("/healthz", yf.Spec.Container.LivenessProbe.HttpGet.Path)
+	}
+	as.NotContains(f, "timeoutSeconds")
+
+	opts.FunctionPath = "../../../test_data/node/square"
+	opts.Protocol = "stdio"
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "probes need the function to listen on a port, which stdio functions do not unless --port is set")
+}
+
+type YResourcesFunction struct {
+	Spec struct {
+		Container struct {
+			Resources struct {
+				Requests map[string]string
+				Limits   map[string]string
+			}
+		}
+	}
+}
+
+func TestFunctionWithResources(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName:  "myfunc",
+		Input:         "in",
+		Protocol:      "stdio",
+		UserAccount:   "me",
+		Version:       "0.0.1",
+		CpuRequest:    "100m",
+		MemoryRequest: "128Mi",
+		MemoryLimit:   "256Mi",
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+
+	yf := YResourcesFunction{}
+	err = yaml.Unmarshal([]byte(f), &yf)
+	as.NoError(err)
+	as.Equal(map[string]string{"cpu": "100m", "memory": "128Mi"}, yf.Spec.Container.Resources.Requests)
+	as.Equal(map[string]string{"memory": "256Mi"}, yf.Spec.Container.Resources.Limits)
+
+	opts.CpuRequest = ""
+	opts.MemoryRequest = ""
+	opts.MemoryLimit = ""
+	f, err = DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.NotContains(f, "resources:")
+}
+
+func TestImageRefOut(t *testing.T) {
+	as := assert.New(t)
+
+	out, err := ioutil.TempFile("", "image-ref")
+	as.NoError(err)
+	out.Close()
+	defer os.Remove(out.Name())
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in",
+		Protocol:     "http",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+		Registry:     "gcr.io",
+		DryRun:       true,
+		ImageRefOut:  out.Name(),
+	}
+	generator := ArtifactsGenerator{
+		GenerateFunction:   DefaultGenerateFunction,
+		GenerateDockerFile: func(options.InitOptions) (string, error) { return "FROM scratch\n", nil },
+	}
+
+	as.NoError(GenerateFunctionArtfacts(generator, ".", opts))
+	content, err := ioutil.ReadFile(out.Name())
+	as.NoError(err)
+	as.Equal("gcr.io/me/myfunc:0.0.1\n", string(content))
+}
+
+type YAnnotatedFunction struct {
+	Metadata struct {
+		Annotations map[string]string
+	}
+}
+
+func TestFunctionWithTimeout(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in",
+		Protocol:     "http",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+		Timeout:      "30s",
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+
+	yf := YAnnotatedFunction{}
+	err = yaml.Unmarshal([]byte(f), &yf)
+	as.NoError(err)
+	as.Equal("30s", yf.Metadata.Annotations[TimeoutAnnotation])
+
+	opts.Timeout = ""
+	f, err = DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.NotContains(f, "annotations:")
+}
+
+func TestResourcesWithApiVersion(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in",
+		Protocol:     "http",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+		ApiVersion:   "projectriff.io/v1alpha1",
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+	yf := YFunction{}
+	as.NoError(yaml.Unmarshal([]byte(f), &yf))
+	as.Equal("projectriff.io/v1alpha1", yf.ApiVersion)
+
+	topics, err := createTopics(opts)
+	as.NoError(err)
+	as.Contains(topics, "apiVersion : projectriff.io/v1alpha1")
+
+	opts.ApiVersion = ""
+	f, err = DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.NoError(yaml.Unmarshal([]byte(f), &yf))
+	as.Equal("projectriff.io/v1", yf.ApiVersion)
+}
+
+func TestFunctionFromResourceTemplate(t *testing.T) {
+	as := assert.New(t)
+	file, err := ioutil.TempFile("", "resource-template")
+	as.NoError(err)
+	defer os.Remove(file.Name())
+	file.WriteString(`apiVersion: {{.ApiVersion}}
+kind: Function
+metadata:
+  name: {{.Name}}
+  annotations:
+    platform.example.com/team: payments
+spec:
+  protocol: {{.Protocol}}
+  input: {{index .Input 0}}
+  container:
+    image: {{.Image}}
+  sidecars:
+  - name: audit
+    image: example/audit:1.0
+`)
+	file.Close()
+
+	opts := options.InitOptions{
+		FunctionName:     "myfunc",
+		Input:            "in",
+		Protocol:         "http",
+		UserAccount:      "me",
+		Version:          "0.0.1",
+		ResourceTemplate: file.Name(),
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.Contains(f, "platform.example.com/team: payments")
+	as.Contains(f, "- name: audit")
+
+	yf := YFunction{}
+	as.NoError(yaml.Unmarshal([]byte(f), &yf))
+	as.Equal("myfunc", yf.Metadata.Name)
+	as.Equal("in", yf.Spec.Input)
+	as.Equal("me/myfunc:0.0.1", yf.Spec.Container.Image)
+}
+
+func TestBrokenResourceTemplateFailsGeneration(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-yaml")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	template := filepath.Join(dir, "function.tmpl")
+	as.NoError(ioutil.WriteFile(template, []byte("kind: Function\nmetadata:\n  name: [{{.Name}}\n"), 0644))
+
+	opts := options.InitOptions{
+		FunctionName:     "myfunc",
+		Input:            "in",
+		Protocol:         "http",
+		UserAccount:      "me",
+		Version:          "0.0.1",
+		ResourceTemplate: template,
+	}
+	generator := ArtifactsGenerator{
+		GenerateFunction:   DefaultGenerateFunction,
+		GenerateDockerFile: func(options.InitOptions) (string, error) { return "FROM scratch\n", nil },
+	}
+
+	err = GenerateFunctionArtfacts(generator, dir, opts)
+	as.Error(err)
+	as.Contains(err.Error(), "generated function is not valid YAML: yaml: ")
+	as.False(osutils.FileExists(filepath.Join(dir, "myfunc-function.yaml")))
+}
+
+func TestFunctionWithResourceName(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		ResourceName: "team-a-myfunc",
+		Input:        "in",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+
+	yf := YFunction{}
+	as.NoError(yaml.Unmarshal([]byte(f), &yf))
+	as.Equal("team-a-myfunc", yf.Metadata.Name)
+	as.Equal("me/myfunc:0.0.1", yf.Spec.Container.Image)
+
+	opts.ResourceName = "Team_A"
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "resource name Team_A is not a valid DNS-1123 subdomain")
+}
+
+func TestTopicsWithProvider(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in",
+		Output:       "out",
+	}
+	topics, err := createTopics(opts)
+	as.NoError(err)
+	as.NotContains(topics, "provider")
+
+	opts.TopicProvider = "kafka-east"
+	topics, err = createTopics(opts)
+	as.NoError(err)
+	for _, doc := range strings.Split(topics, "---") {
+		topic := struct {
+			Spec struct {
+				Partitions int
+				Provider   string
+			}
+		}{}
+		as.NoError(yaml.Unmarshal([]byte(doc), &topic))
+		as.Equal(1, topic.Spec.Partitions)
+		as.Equal("kafka-east", topic.Spec.Provider)
+	}
+
+	opts.TopicProvider = "Kafka East"
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "topic provider Kafka East is not a valid DNS-1123 subdomain")
+}
+
+func TestResourcesWithNamespace(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+	}
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.NotContains(f, "namespace")
+	topics, err := createTopics(opts)
+	as.NoError(err)
+	as.NotContains(topics, "namespace")
+
+	opts.Namespace = "team-a"
+	f, err = DefaultGenerateFunction(opts)
+	as.NoError(err)
+	topics, err = createTopics(opts)
+	as.NoError(err)
+	for _, doc := range []string{f, topics} {
+		resource := struct{ Metadata struct{ Name, Namespace string } }{}
+		as.NoError(yaml.Unmarshal([]byte(doc), &resource))
+		as.Equal("team-a", resource.Metadata.Namespace)
+	}
+
+	opts.Namespace = "team.a"
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "namespace team.a is not a valid DNS-1123 label")
+}
+
+func TestFunctionWithCloudEvents(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in",
+		Protocol:     "http",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+		Timeout:      "30s",
+		CloudEvents:  true,
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+
+	yf := YAnnotatedFunction{}
+	err = yaml.Unmarshal([]byte(f), &yf)
+	as.NoError(err)
+	as.Equal("structured", yf.Metadata.Annotations[ContentModeAnnotation])
+	as.Equal("30s", yf.Metadata.Annotations[TimeoutAnnotation])
+
+	opts.Timeout = ""
+	f, err = DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.Contains(f, "  annotations:\n    projectriff.io/content-mode: structured\n")
+}
+
+func TestResourcesWithAnnotations(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in",
+		Protocol:     "http",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+		Timeout:      "30s",
+		Annotations:  []string{"prometheus.io/scrape=false", "team=squares", "prometheus.io/scrape=true", TimeoutAnnotation + "=1m"},
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+	yf := YAnnotatedFunction{}
+	as.NoError(yaml.Unmarshal([]byte(f), &yf))
+	as.Equal(map[string]string{"prometheus.io/scrape": "true", "team": "squares", TimeoutAnnotation: "30s"}, yf.Metadata.Annotations)
+
+	topics, err := createTopics(opts)
+	as.NoError(err)
+	yt := YAnnotatedFunction{}
+	as.NoError(yaml.Unmarshal([]byte(topics), &yt))
+	as.Equal("true", yt.Metadata.Annotations["prometheus.io/scrape"])
+
+	opts.Annotations = []string{"prometheus.io/scrape"}
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "annotation prometheus.io/scrape must be of the form key=value, with a valid annotation key")
+}
+
+type YScaledFunction struct {
+	Spec struct {
+		MinReplicas int `yaml:"minReplicas"`
+		MaxReplicas int `yaml:"maxReplicas"`
+	}
+}
+
+func TestFunctionWithScaleBounds(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in",
+		Protocol:     "http",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.NotContains(f, "Replicas")
+
+	opts.MinScale = 1
+	opts.MaxScale = 5
+	f, err = DefaultGenerateFunction(opts)
+	as.NoError(err)
+	yf := YScaledFunction{}
+	as.NoError(yaml.Unmarshal([]byte(f), &yf))
+	as.Equal(1, yf.Spec.MinReplicas)
+	as.Equal(5, yf.Spec.MaxReplicas)
+
+	opts.FunctionPath = "../../../test_data/node/square"
+	opts.MinScale = 6
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "--min-scale 6 must not be more than --max-scale 5")
+	opts.MinScale = -1
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "scale bounds must be 0 or more replicas")
+}
+
+func TestFunctionWithFixedReplicas(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "in",
+		Protocol:     "http",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+	}
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.NotContains(f, "replicas:")
+
+	replicas := 3
+	opts.Replicas = &replicas
+	f, err = DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.Contains(f, "\n  replicas: 3\n")
+
+	replicas = 0
+	f, err = DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.Contains(f, "\n  replicas: 0\n")
+
+	opts.FunctionPath = "../../../test_data/node/square"
+	replicas = -1
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "--replicas -1 must be 0 or more replicas")
+}
+
+func TestKeyedTopics(t *testing.T) {
+	as := assert.New(t)
+
+	keyedTopics := func(opts options.InitOptions) map[string]bool {
+		topics, err := createTopics(opts)
+		as.NoError(err)
+		keyed := map[string]bool{}
+		for _, doc := range strings.Split(topics, "---") {
+			topic := struct {
+				Metadata struct {
+					Name string
+				}
+				Spec struct {
+					Keyed bool
+				}
+			}{}
+			as.NoError(yaml.Unmarshal([]byte(doc), &topic))
+			keyed[topic.Metadata.Name] = topic.Spec.Keyed
+		}
+		return keyed
+	}
+
+	opts := options.InitOptions{
+		FunctionName: "myfunc",
+		Input:        "orders:keyed,clicks",
+		Output:       "out",
+		Protocol:     "http",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+	}
+	as.Equal(map[string]bool{"orders": true, "clicks": false, "out": false}, keyedTopics(opts))
+
+	f, err := DefaultGenerateFunction(opts)
+	as.NoError(err)
+	as.Contains(f, "  input:\n  - orders\n  - clicks\n")
+
+	opts.Keyed = true
+	as.Equal(map[string]bool{"orders": true, "clicks": true, "out": true}, keyedTopics(opts))
+
+	opts.FunctionPath = "../../../test_data/node/square"
+	opts.Input = "orders:ordered"
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "topic orders:ordered has the unknown option ordered, use orders:keyed for a keyed topic")
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"text/template"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+)
+
+type GitHubWorkflow struct {
+	Name       string
+	Registry   string
+	Image      string
+	Dockerfile string
+	Context    string
+}
+
+// GitHub expressions are emitted as literals so they are not evaluated as template actions.
+var gitHubWorkflowTemplate = `
+name: {{.Name}}
+on: [push]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+    - uses: actions/checkout@v1
+    - name: Log in to the registry
+      run: echo "{{"${{ secrets.REGISTRY_PASSWORD }}"}}" | docker login {{.Registry}} -u "{{"${{ secrets.REGISTRY_USERNAME }}"}}" --password-stdin
+    - name: Build the function image
+      run: docker build -t {{.Image}} -f {{.Dockerfile}} {{.Context}}
+    - name: Push the function image
+      run: docker push {{.Image}}
+`
+
+/*
+ * The workflow lives in the enclosing git repository, so the Dockerfile and build context are
+ * expressed relative to the repository root. The build context is the function directory unless given.
+ */
+func generateGitHubWorkflow(workdir string, opts options.InitOptions) (string, error) {
+	workdir, err := filepath.Abs(workdir)
+	if err != nil {
+		return "", err
+	}
+	root := repositoryRoot(workdir)
+	functionDir, err := filepath.Rel(root, workdir)
+	if err != nil {
+		return "", err
+	}
+	context := functionDir
+	if opts.Context != "" {
+		absContext, err := filepath.Abs(opts.Context)
+		if err != nil {
+			return "", err
+		}
+		context, err = filepath.Rel(root, absContext)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	workflow := GitHubWorkflow{
+		Name:       opts.FunctionName,
+		Registry:   opts.Registry,
+		Image:      options.ImageName(opts),
+		Dockerfile: filepath.ToSlash(filepath.Join(functionDir, "Dockerfile")),
+		Context:    filepath.ToSlash(context),
+	}
+
+	tmpl, err := template.New("github-workflow").Parse(gitHubWorkflowTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, workflow)
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func gitHubWorkflowPath(workdir string, opts options.InitOptions) (string, error) {
+	workdir, err := filepath.Abs(workdir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(repositoryRoot(workdir), ".github", "workflows", fmt.Sprintf("%s.yml", opts.FunctionName)), nil
+}
+
+// The closest enclosing directory containing .git, or dir itself when not in a repository.
+func repositoryRoot(dir string) string {
+	for current := dir; ; current = filepath.Dir(current) {
+		if osutils.FileExists(filepath.Join(current, ".git")) {
+			return current
+		}
+		if filepath.Dir(current) == current {
+			return dir
+		}
+	}
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubWorkflow(t *testing.T) {
+	as := assert.New(t)
+
+	root, err := ioutil.TempDir("", "riff-workflow")
+	as.NoError(err)
+	defer os.RemoveAll(root)
+	as.NoError(os.Mkdir(filepath.Join(root, ".git"), 0755))
+	workdir := filepath.Join(root, "functions", "square")
+	as.NoError(os.MkdirAll(workdir, 0755))
+
+	opts := options.InitOptions{
+		FunctionName: "square",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+		Registry:     "gcr.io",
+	}
+
+	workflow, err := generateGitHubWorkflow(workdir, opts)
+	as.NoError(err)
+	as.Contains(workflow, "docker build -t gcr.io/me/square:0.0.1 -f functions/square/Dockerfile functions/square")
+	as.Contains(workflow, "docker push gcr.io/me/square:0.0.1")
+	as.Contains(workflow, "${{ secrets.REGISTRY_PASSWORD }}")
+
+	path, err := gitHubWorkflowPath(workdir, opts)
+	as.NoError(err)
+	as.Equal(filepath.Join(root, ".github", "workflows", "square.yml"), path)
+}
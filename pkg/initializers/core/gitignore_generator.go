@@ -0,0 +1,71 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/projectriff/riff-cli/pkg/osutils"
+)
+
+// The build output and caches of each language that should not be committed.
+func gitignorePatterns(language string) []string {
+	switch language {
+	case "node":
+		return []string{"node_modules/"}
+	case "python":
+		return []string{"__pycache__/", "*.pyc"}
+	case "java":
+		return []string{"target/"}
+	}
+	return nil
+}
+
+/*
+ * Returns the content of the .gitignore at filename with the missing patterns appended, or "" when it already
+ * has them all. Existing lines are kept as they are.
+ */
+func mergeGitIgnore(filename string, patterns []string) (string, error) {
+	var existing string
+	if osutils.FileExists(filename) {
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return "", err
+		}
+		existing = string(content)
+	}
+
+	present := map[string]bool{}
+	for _, line := range strings.Split(existing, "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+	var missing []string
+	for _, pattern := range patterns {
+		if !present[pattern] {
+			missing = append(missing, pattern)
+		}
+	}
+	if len(missing) == 0 {
+		return "", nil
+	}
+
+	if existing != "" && !strings.HasSuffix(existing, "\n") {
+		existing += "\n"
+	}
+	return existing + strings.Join(missing, "\n") + "\n", nil
+}
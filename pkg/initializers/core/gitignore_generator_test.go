@@ -0,0 +1,65 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitIgnoreCreated(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-gitignore")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	content, err := mergeGitIgnore(filepath.Join(dir, ".gitignore"), gitignorePatterns("python"))
+	as.NoError(err)
+	as.Equal("__pycache__/\n*.pyc\n", content)
+}
+
+func TestGitIgnoreMerged(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-gitignore")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, ".gitignore"), []byte(".idea/\n*.pyc"), 0644))
+
+	content, err := mergeGitIgnore(filepath.Join(dir, ".gitignore"), gitignorePatterns("python"))
+	as.NoError(err)
+	as.Equal(".idea/\n*.pyc\n__pycache__/\n", content)
+}
+
+func TestGitIgnoreUnchanged(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-gitignore")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, ".gitignore"), []byte("target/\n"), 0644))
+
+	content, err := mergeGitIgnore(filepath.Join(dir, ".gitignore"), gitignorePatterns("java"))
+	as.NoError(err)
+	as.Equal("", content)
+
+	content, err = mergeGitIgnore(filepath.Join(dir, ".gitignore"), gitignorePatterns("shell"))
+	as.NoError(err)
+	as.Equal("", content)
+}
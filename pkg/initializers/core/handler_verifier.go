@@ -0,0 +1,141 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+)
+
+// Verifies that the function artifact defines its handler, so that a missing one fails before the build.
+type HandlerVerifier interface {
+	// Whether the artifact, relative to workdir, defines the handler. An error means it could not tell.
+	Defines(workdir string, artifact string, handler string) (bool, error)
+}
+
+// The verifiers of the handler of each language, replaced in tests by fake verifiers.
+var handlerVerifiers = map[string]HandlerVerifier{
+	"java":   javaHandlerVerifier{},
+	"python": pythonHandlerVerifier{},
+	"node":   nodeHandlerVerifier{},
+	"deno":   nodeHandlerVerifier{},
+}
+
+/*
+ * Fails when the function does not define its handler, which the invoker would only find out at runtime.
+ * Verification is skipped with a warning for the languages without handler, and for remote artifacts.
+ */
+func verifyHandler(language string, workdir string, opts options.InitOptions, resolver ArtifactResolver) error {
+	verifier, ok := handlerVerifiers[language]
+	if !ok {
+		return options.Warning(opts.Strict, fmt.Sprintf("%s functions have no handler to verify, skipping --verify-handler", language))
+	}
+	if resolver.Remote() {
+		return options.Warning(opts.Strict, fmt.Sprintf("remote artifact %s cannot be verified, skipping --verify-handler", opts.Artifact))
+	}
+	defined, err := verifier.Defines(workdir, opts.Artifact, opts.Handler)
+	if err != nil {
+		return errors.New(fmt.Sprintf("cannot verify the handler of %s: %v", opts.Artifact, err))
+	}
+	if !defined {
+		handler := opts.Handler
+		if handler == "" {
+			handler = "default export"
+		}
+		return errors.New(fmt.Sprintf("handler %s is not defined in %s, the function would fail at runtime", handler, opts.Artifact))
+	}
+	return nil
+}
+
+// The directories maven and gradle compile the classes of a java function to.
+var javaClassesDirs = []string{
+	filepath.Join("target", "classes"),
+	filepath.Join("build", "classes", "java", "main"),
+}
+
+/*
+ * Looks for the handler class in the jar, also in the classes of a Spring Boot jar, or in the compiled
+ * classes of the function when its jar is not built yet.
+ */
+type javaHandlerVerifier struct{}
+
+func (javaHandlerVerifier) Defines(workdir string, artifact string, handler string) (bool, error) {
+	class := strings.Replace(handler, ".", "/", -1) + ".class"
+	jar := filepath.Join(workdir, artifact)
+	if osutils.FileExists(jar) {
+		reader, err := zip.OpenReader(jar)
+		if err != nil {
+			return false, err
+		}
+		defer reader.Close()
+		for _, file := range reader.File {
+			if file.Name == class || file.Name == "BOOT-INF/classes/"+class {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	for _, dir := range javaClassesDirs {
+		if osutils.IsDirectory(filepath.Join(workdir, dir)) {
+			return osutils.FileExists(filepath.Join(workdir, dir, filepath.FromSlash(class))), nil
+		}
+	}
+	return false, errors.New("neither the jar nor the compiled classes are found, build the function first")
+}
+
+// Looks for a top level def of the handler in the module, or in the __init__.py of a package.
+type pythonHandlerVerifier struct{}
+
+func (pythonHandlerVerifier) Defines(workdir string, artifact string, handler string) (bool, error) {
+	source := filepath.Join(workdir, artifact)
+	if osutils.IsDirectory(source) {
+		source = filepath.Join(source, "__init__.py")
+	}
+	content, err := ioutil.ReadFile(source)
+	if err != nil {
+		return false, err
+	}
+	def := regexp.MustCompile(`(?m)^def\s+` + regexp.QuoteMeta(handler) + `\s*\(`)
+	return def.Match(content), nil
+}
+
+// A default export, the handler of a JavaScript function given none.
+var defaultExportRegexp = regexp.MustCompile(`\bmodule\.exports\s*=|\bexport\s+default\b`)
+
+// Looks for the export of the handler in the source, or for its default export.
+type nodeHandlerVerifier struct{}
+
+func (nodeHandlerVerifier) Defines(workdir string, artifact string, handler string) (bool, error) {
+	content, err := ioutil.ReadFile(filepath.Join(workdir, artifact))
+	if err != nil {
+		return false, err
+	}
+	if handler == "" {
+		return defaultExportRegexp.Match(content), nil
+	}
+	name := regexp.QuoteMeta(handler)
+	export := regexp.MustCompile(`\bexports\.` + name + `\s*=|\bexport\s+(async\s+)?(function\*?|const|let|var|class)\s+` + name + `\b|\bexport\s*\{[^}]*\b` + name + `\b[^}]*\}`)
+	return export.Match(content), nil
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/stretchr/testify/assert"
+)
+
+// Reports whether the handler is defined, recording what it was asked to verify.
+type fakeHandlerVerifier struct {
+	defined  bool
+	verified []string
+}
+
+func (f *fakeHandlerVerifier) Defines(workdir string, artifact string, handler string) (bool, error) {
+	f.verified = append(f.verified, artifact+":"+handler)
+	return f.defined, nil
+}
+
+func withHandlerVerifier(language string, verifier HandlerVerifier) func() {
+	original := handlerVerifiers[language]
+	handlerVerifiers[language] = verifier
+	return func() { handlerVerifiers[language] = original }
+}
+
+func verifiedFunction(as *assert.Assertions) (string, options.InitOptions, ArtifactsGenerator) {
+	dir, err := ioutil.TempDir("", "riff-handler")
+	as.NoError(err)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "demo.py"), []byte("def process(input):\n    return input\n"), 0644))
+	opts := options.InitOptions{FunctionName: "demo", Artifact: "demo.py", Handler: "proces", Input: "in", Protocol: "http", UserAccount: "me", Version: "0.0.1", VerifyHandler: true}
+	generator := ArtifactsGenerator{
+		Language:           "python",
+		GenerateFunction:   DefaultGenerateFunction,
+		GenerateDockerFile: func(options.InitOptions) (string, error) { return "FROM scratch\n", nil },
+	}
+	return dir, opts, generator
+}
+
+func TestMissingHandlerAbortsGeneration(t *testing.T) {
+	as := assert.New(t)
+	verifier := &fakeHandlerVerifier{defined: false}
+	defer withHandlerVerifier("python", verifier)()
+	dir, opts, generator := verifiedFunction(as)
+	defer os.RemoveAll(dir)
+
+	err := GenerateFunctionArtfacts(generator, dir, opts)
+	as.EqualError(err, "handler proces is not defined in demo.py, the function would fail at runtime")
+	as.Equal([]string{"demo.py:proces"}, verifier.verified)
+	as.False(osutils.FileExists(filepath.Join(dir, "Dockerfile")))
+}
+
+func TestDefinedHandlerGenerates(t *testing.T) {
+	as := assert.New(t)
+	defer withHandlerVerifier("python", &fakeHandlerVerifier{defined: true})()
+	dir, opts, generator := verifiedFunction(as)
+	defer os.RemoveAll(dir)
+
+	as.NoError(GenerateFunctionArtfacts(generator, dir, opts))
+	as.True(osutils.FileExists(filepath.Join(dir, "Dockerfile")))
+}
+
+func TestPythonHandlerVerifier(t *testing.T) {
+	as := assert.New(t)
+	dir, _, _ := verifiedFunction(as)
+	defer os.RemoveAll(dir)
+
+	defined, err := pythonHandlerVerifier{}.Defines(dir, "demo.py", "process")
+	as.NoError(err)
+	as.True(defined)
+	defined, err = pythonHandlerVerifier{}.Defines(dir, "demo.py", "proces")
+	as.NoError(err)
+	as.False(defined)
+}
+
+func TestNodeHandlerVerifier(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-handler")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square.js"), []byte("exports.square = x => x ** 2;\n"), 0644))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "greet.ts"), []byte("export default async function greet(name: string) {}\n"), 0644))
+
+	defined, _ := nodeHandlerVerifier{}.Defines(dir, "square.js", "square")
+	as.True(defined)
+	defined, _ = nodeHandlerVerifier{}.Defines(dir, "square.js", "")
+	as.False(defined)
+	defined, _ = nodeHandlerVerifier{}.Defines(dir, "greet.ts", "")
+	as.True(defined)
+}
+
+func TestJavaHandlerVerifier(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-handler")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	jar, err := os.Create(filepath.Join(dir, "greeter.jar"))
+	as.NoError(err)
+	archive := zip.NewWriter(jar)
+	_, err = archive.Create("BOOT-INF/classes/functions/Greeter.class")
+	as.NoError(err)
+	as.NoError(archive.Close())
+	as.NoError(jar.Close())
+
+	defined, err := javaHandlerVerifier{}.Defines(dir, "greeter.jar", "functions.Greeter")
+	as.NoError(err)
+	as.True(defined)
+	defined, err = javaHandlerVerifier{}.Defines(dir, "greeter.jar", "functions.Greeting")
+	as.NoError(err)
+	as.False(defined)
+
+	_, err = javaHandlerVerifier{}.Defines(dir, "missing.jar", "functions.Greeter")
+	as.EqualError(err, "neither the jar nor the compiled classes are found, build the function first")
+	as.NoError(os.MkdirAll(filepath.Join(dir, "target", "classes", "functions"), 0755))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "target", "classes", "functions", "Greeter.class"), nil, 0644))
+	defined, err = javaHandlerVerifier{}.Defines(dir, "missing.jar", "functions.Greeter")
+	as.NoError(err)
+	as.True(defined)
+}
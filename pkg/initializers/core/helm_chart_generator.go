@@ -0,0 +1,142 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"path/filepath"
+	"text/template"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+)
+
+// The directory, relative to the function, the Helm chart is generated in.
+const HelmChartDir = "chart"
+
+type HelmChart struct {
+	ApiVersion    string
+	Name          string
+	Version       string
+	Image         string
+	Protocol      string
+	Input         []string
+	Output        []string
+	TopicProvider string
+}
+
+var helmChartTemplate = `
+apiVersion: v1
+name: [[.Name]]
+version: [[.Version]]
+description: A Helm chart for the [[.Name]] riff function
+`
+
+var helmValuesTemplate = `
+image: [[.Image]]
+protocol: [[.Protocol]]
+topics:
+  input:
+[[- range .Input ]]
+  - [[.]]
+[[- end ]]
+  output:[[ if not .Output ]] [][[ end ]]
+[[- range .Output ]]
+  - [[.]]
+[[- end ]]
+`
+
+// Helm actions are left in place, only the [[ ]] actions are expanded when generating the chart.
+var helmFunctionTemplate = `
+{{- range .Values.topics.input }}
+apiVersion: [[.ApiVersion]]
+kind: Topic
+metadata:
+  name: {{ . }}
+spec:
+  partitions: 1
+[[- if .TopicProvider ]]
+  provider: [[.TopicProvider]]
+[[- end ]]
+---
+{{- end }}
+{{- range .Values.topics.output }}
+apiVersion: [[.ApiVersion]]
+kind: Topic
+metadata:
+  name: {{ . }}
+spec:
+  partitions: 1
+[[- if .TopicProvider ]]
+  provider: [[.TopicProvider]]
+[[- end ]]
+---
+{{- end }}
+apiVersion: [[.ApiVersion]]
+kind: Function
+metadata:
+  name: {{ .Chart.Name }}
+spec:
+  protocol: {{ .Values.protocol }}
+  input:
+{{- range .Values.topics.input }}
+  - {{ . }}
+{{- end }}
+{{- if .Values.topics.output }}
+  output:
+{{- range .Values.topics.output }}
+  - {{ . }}
+{{- end }}
+{{- end }}
+  container:
+    image: {{ .Values.image }}
+`
+
+/*
+ * Generates a minimal Helm chart deploying the function and its topics. The returned contents are keyed
+ * by their path relative to the chart directory.
+ */
+func generateHelmChart(opts options.InitOptions) (map[string]string, error) {
+	chart := HelmChart{
+		ApiVersion:    options.ApiVersion(opts),
+		Name:          options.ResourceName(opts),
+		Version:       opts.Version,
+		Image:         options.ImageName(opts),
+		Protocol:      opts.Protocol,
+		Input:         options.Topics(opts.Input),
+		Output:        options.Topics(opts.Output),
+		TopicProvider: opts.TopicProvider,
+	}
+
+	files := map[string]string{}
+	for path, tmpl := range map[string]string{
+		"Chart.yaml":                                helmChartTemplate,
+		"values.yaml":                               helmValuesTemplate,
+		filepath.Join("templates", "function.yaml"): helmFunctionTemplate,
+	} {
+		t, err := template.New(path).Delims("[[", "]]").Parse(tmpl)
+		if err != nil {
+			return nil, err
+		}
+		var buffer bytes.Buffer
+		err = t.Execute(&buffer, chart)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = buffer.String()
+	}
+	return files, nil
+}
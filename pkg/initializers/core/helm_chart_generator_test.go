@@ -0,0 +1,65 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+type YHelmValues struct {
+	Image  string
+	Topics struct {
+		Input  []string
+		Output []string
+	}
+}
+
+func TestHelmChart(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "square",
+		Input:        "numbers",
+		Output:       "squares",
+		Protocol:     "http",
+		UserAccount:  "me",
+		Version:      "0.0.1",
+	}
+
+	chart, err := generateHelmChart(opts)
+	as.NoError(err)
+
+	values := YHelmValues{}
+	err = yaml.Unmarshal([]byte(chart["values.yaml"]), &values)
+	as.NoError(err)
+	as.Equal("me/square:0.0.1", values.Image)
+	as.Equal([]string{"numbers"}, values.Topics.Input)
+	as.Equal([]string{"squares"}, values.Topics.Output)
+
+	as.Contains(chart["Chart.yaml"], "name: square")
+	if as.Contains(chart, filepath.Join("templates", "function.yaml")) {
+		function := chart[filepath.Join("templates", "function.yaml")]
+		as.Contains(function, "kind: Function")
+		as.Contains(function, "image: {{ .Values.image }}")
+		as.Contains(function, "apiVersion: projectriff.io/v1")
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/stretchr/testify/assert"
+)
+
+var hookTestGenerator = ArtifactsGenerator{
+	GenerateFunction:   DefaultGenerateFunction,
+	GenerateDockerFile: func(options.InitOptions) (string, error) { return "FROM scratch\n", nil },
+}
+
+func TestFailingPreHookAbortsGeneration(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-hooks")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	opts := options.InitOptions{FunctionName: "myfunc", Input: "in", UserAccount: "me", Version: "0.0.1",
+		PreHook: "touch pre-hook-ran && exit 3", PostHook: "touch post-hook-ran"}
+	err = GenerateFunctionArtfacts(hookTestGenerator, dir, opts)
+	as.EqualError(err, "pre-hook 'touch pre-hook-ran && exit 3' failed: exit status 3")
+
+	as.True(osutils.FileExists(filepath.Join(dir, "pre-hook-ran")))
+	as.False(osutils.FileExists(filepath.Join(dir, "Dockerfile")))
+	as.False(osutils.FileExists(filepath.Join(dir, "post-hook-ran")))
+}
+
+func TestPostHookRunsAfterGeneration(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-hooks")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	opts := options.InitOptions{FunctionName: "myfunc", Input: "in", UserAccount: "me", Version: "0.0.1",
+		PreHook: "test ! -f Dockerfile", PostHook: "cp Dockerfile post-hook-copy"}
+	as.NoError(GenerateFunctionArtfacts(hookTestGenerator, dir, opts))
+
+	copied, err := ioutil.ReadFile(filepath.Join(dir, "post-hook-copy"))
+	as.NoError(err)
+	as.Equal("FROM scratch\n", string(copied))
+}
+
+func TestHookWritingToStderrFailsUnderStrict(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-hooks")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	opts := options.InitOptions{FunctionName: "myfunc", Input: "in", UserAccount: "me", Version: "0.0.1",
+		PreHook: "echo deprecated >&2"}
+	as.NoError(GenerateFunctionArtfacts(hookTestGenerator, dir, opts))
+
+	opts.Strict = true
+	opts.Force = true
+	err = GenerateFunctionArtfacts(hookTestGenerator, dir, opts)
+	as.EqualError(err, "pre-hook 'echo deprecated >&2': deprecated")
+}
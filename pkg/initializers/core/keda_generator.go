@@ -0,0 +1,88 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+)
+
+// The Kafka brokers of a riff installation, from which KEDA reads the lag of the input topics.
+const kedaBootstrapServers = "projectriff-kafka.riff-system:9092"
+
+// The lag of an input topic, in messages, above which KEDA adds a replica.
+const kedaLagThreshold = 10
+
+type ScaledObject struct {
+	Name             string
+	Namespace        string
+	Topics           []string
+	MinReplicas      int
+	MaxReplicas      int
+	BootstrapServers string
+	LagThreshold     int
+}
+
+// The function deployment is named after the function, and its consumer group too, with a trigger per input topic.
+var scaledObjectTemplate = `
+apiVersion: keda.sh/v1alpha1
+kind: ScaledObject
+metadata:
+  name: {{.Name}}
+{{- if .Namespace }}
+  namespace: {{.Namespace}}
+{{- end }}
+spec:
+  scaleTargetRef:
+    name: {{.Name}}
+  minReplicaCount: {{.MinReplicas}}
+  maxReplicaCount: {{.MaxReplicas}}
+  triggers:
+{{- range .Topics }}
+  - type: kafka
+    metadata:
+      bootstrapServers: {{$.BootstrapServers}}
+      consumerGroup: {{$.Name}}
+      topic: {{.}}
+      lagThreshold: "{{$.LagThreshold}}"
+{{- end }}
+`
+
+func generateKedaScaledObject(opts options.InitOptions) (string, error) {
+	scaledObject := ScaledObject{
+		Name:             options.ResourceName(opts),
+		Namespace:        opts.Namespace,
+		Topics:           options.Topics(opts.Input),
+		MinReplicas:      opts.MinReplicas,
+		MaxReplicas:      options.MaxReplicas(opts),
+		BootstrapServers: kedaBootstrapServers,
+		LagThreshold:     kedaLagThreshold,
+	}
+
+	tmpl, err := template.New("scaledobject").Parse(scaledObjectTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, scaledObject)
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+type YScaledObject struct {
+	Kind     string
+	Metadata struct {
+		Name string
+	}
+	Spec struct {
+		ScaleTargetRef struct {
+			Name string
+		} `yaml:"scaleTargetRef"`
+		MinReplicaCount int `yaml:"minReplicaCount"`
+		MaxReplicaCount int `yaml:"maxReplicaCount"`
+		Triggers        []struct {
+			Type     string
+			Metadata map[string]string
+		}
+	}
+}
+
+func TestKedaScaledObject(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "square",
+		Input:        "numbers",
+		MinReplicas:  1,
+		MaxReplicas:  5,
+	}
+
+	content, err := generateKedaScaledObject(opts)
+	as.NoError(err)
+
+	scaledObject := YScaledObject{}
+	as.NoError(yaml.Unmarshal([]byte(content), &scaledObject))
+	as.Equal("ScaledObject", scaledObject.Kind)
+	as.Equal("square", scaledObject.Metadata.Name)
+	as.Equal("square", scaledObject.Spec.ScaleTargetRef.Name)
+	as.Equal(1, scaledObject.Spec.MinReplicaCount)
+	as.Equal(5, scaledObject.Spec.MaxReplicaCount)
+	if as.Len(scaledObject.Spec.Triggers, 1) {
+		as.Equal("kafka", scaledObject.Spec.Triggers[0].Type)
+		as.Equal("numbers", scaledObject.Spec.Triggers[0].Metadata["topic"])
+		as.Equal("square", scaledObject.Spec.Triggers[0].Metadata["consumerGroup"])
+	}
+}
+
+func TestKedaScaledObjectDefaultsMaxReplicas(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{FunctionName: "square", Input: "numbers,letters"}
+
+	content, err := generateKedaScaledObject(opts)
+	as.NoError(err)
+
+	scaledObject := YScaledObject{}
+	as.NoError(yaml.Unmarshal([]byte(content), &scaledObject))
+	as.Equal(0, scaledObject.Spec.MinReplicaCount)
+	as.Equal(options.DefaultMaxReplicas, scaledObject.Spec.MaxReplicaCount)
+	as.Len(scaledObject.Spec.Triggers, 2)
+}
+
+func TestReplicaBoundsValidatedWithKeda(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{FunctionPath: "../../../test_data/node/square", Keda: true, MinReplicas: 6, MaxReplicas: 5}
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "replicas must range from 0 or more to at least as many, not from 6 to 5")
+
+	opts = options.InitOptions{FunctionPath: "../../../test_data/node/square", MinReplicas: 6, MaxReplicas: 5}
+	as.NoError(options.ValidateAndCleanInitOptions(&opts))
+}
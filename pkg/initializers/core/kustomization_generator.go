@@ -0,0 +1,70 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+)
+
+// The file kustomize reads, next to the resources it lists.
+const KustomizationFile = "kustomization.yaml"
+
+type Kustomization struct {
+	Namespace string
+	Resources []string
+}
+
+// The resources are listed in the order they apply, topics first.
+var kustomizationTemplate = `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+{{- if .Namespace }}
+namespace: {{.Namespace}}
+{{- end }}
+resources:
+{{- range .Resources }}
+- {{.}}
+{{- end }}
+`
+
+// A kustomize base of the generated resource files, for overlays to build on.
+func generateKustomization(opts options.InitOptions) (string, error) {
+	var kustomization Kustomization
+	kustomization.Namespace = opts.Namespace
+	if len(options.Topics(opts.Input+","+opts.Output)) > 0 {
+		kustomization.Resources = append(kustomization.Resources, fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "topics"))
+	}
+	kustomization.Resources = append(kustomization.Resources, fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "function"))
+	if opts.Keda {
+		kustomization.Resources = append(kustomization.Resources, fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "scaledobject"))
+	}
+
+	tmpl, err := template.New("kustomization").Parse(kustomizationTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, kustomization)
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+type YKustomization struct {
+	Kind      string
+	Namespace string
+	Resources []string
+}
+
+func TestKustomizationListsResources(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "square",
+		Input:        "numbers",
+		Output:       "squares",
+		Namespace:    "team-a",
+	}
+
+	content, err := generateKustomization(opts)
+	as.NoError(err)
+
+	kustomization := YKustomization{}
+	as.NoError(yaml.Unmarshal([]byte(content), &kustomization))
+	as.Equal("Kustomization", kustomization.Kind)
+	as.Equal("team-a", kustomization.Namespace)
+	as.Equal([]string{"square-topics.yaml", "square-function.yaml"}, kustomization.Resources)
+
+	opts.Keda = true
+	content, err = generateKustomization(opts)
+	as.NoError(err)
+	as.Contains(content, "\n- square-scaledobject.yaml\n")
+}
+
+func TestKustomizationIsWritten(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-kustomize")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	opts := options.InitOptions{FunctionName: "square", Input: "numbers", Protocol: "stdio", UserAccount: "me", Version: "0.0.1", Kustomize: true}
+	generator := ArtifactsGenerator{
+		GenerateFunction:   DefaultGenerateFunction,
+		GenerateDockerFile: func(options.InitOptions) (string, error) { return "FROM scratch\n", nil },
+	}
+	as.NoError(GenerateFunctionArtfacts(generator, dir, opts))
+
+	files, err := GeneratedFiles(dir, opts)
+	as.NoError(err)
+	as.Contains(files, filepath.Join(dir, KustomizationFile))
+}
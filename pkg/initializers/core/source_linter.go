@@ -0,0 +1,102 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+)
+
+// How long linting the function source may take.
+const lintTimeout = 2 * time.Minute
+
+// Finds and runs the source linters.
+type LintRunner interface {
+	LookPath(command string) (string, error)
+	Run(dir string, command string, args []string) ([]byte, error)
+}
+
+type execLintRunner struct{}
+
+func (execLintRunner) LookPath(command string) (string, error) {
+	return exec.LookPath(command)
+}
+
+func (execLintRunner) Run(dir string, command string, args []string) ([]byte, error) {
+	return osutils.ExecInDir(dir, command, args, lintTimeout)
+}
+
+// Runs the linters of --lint-source, replaced in tests by a fake runner.
+var lintRunner LintRunner = execLintRunner{}
+
+// A linter of the sources of a language, run in the function directory with the source to lint last.
+type sourceLinter struct {
+	command string
+	args    []string
+}
+
+var sourceLinters = map[string]sourceLinter{
+	"node":   {command: "eslint"},
+	"python": {command: "flake8"},
+	"java":   {command: "checkstyle", args: []string{"-c", "/google_checks.xml"}},
+	"shell":  {command: "shellcheck"},
+	"deno":   {command: "deno", args: []string{"lint"}},
+}
+
+/*
+ * Lints the function source in workdir with the linter of the language, failing on lint errors. The source is
+ * the artifact, except for java functions whose artifact is a jar, where it is the source directory. Linting
+ * is skipped with a warning when the linter is not installed.
+ */
+func lintSource(language string, workdir string, opts options.InitOptions, resolver ArtifactResolver) error {
+	linter, ok := sourceLinters[language]
+	if !ok {
+		return options.Warning(opts.Strict, fmt.Sprintf("no source linter for %s functions, skipping --lint-source", language))
+	}
+	if resolver.Remote() {
+		return options.Warning(opts.Strict, fmt.Sprintf("remote artifact %s cannot be linted, skipping --lint-source", opts.Artifact))
+	}
+	command, err := lintRunner.LookPath(linter.command)
+	if err != nil {
+		return options.Warning(opts.Strict, fmt.Sprintf("%s not found, skipping --lint-source", linter.command))
+	}
+
+	source := opts.Artifact
+	if language == "java" {
+		source = "."
+		if osutils.IsDirectory(filepath.Join(workdir, "src", "main", "java")) {
+			source = filepath.Join("src", "main", "java")
+		}
+	}
+	args := append(append([]string{}, linter.args...), source)
+	ioutils.Debugf("linting %s with %s\n", source, linter.command)
+	out, err := lintRunner.Run(workdir, command, args)
+	if len(out) > 0 {
+		ioutils.Infof("%s", out)
+	}
+	if err != nil {
+		return errors.New(fmt.Sprintf("%s reported lint errors in %s, fix them or initialize without --lint-source", linter.command, source))
+	}
+	return nil
+}
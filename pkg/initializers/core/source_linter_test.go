@@ -0,0 +1,102 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/stretchr/testify/assert"
+)
+
+// Finds the installed linters, which fail when failing is set, recording how they were run.
+type fakeLintRunner struct {
+	installed map[string]bool
+	failing   bool
+	runs      [][]string
+}
+
+func (f *fakeLintRunner) LookPath(command string) (string, error) {
+	if !f.installed[command] {
+		return "", errors.New("executable file not found in $PATH")
+	}
+	return "/usr/bin/" + command, nil
+}
+
+func (f *fakeLintRunner) Run(dir string, command string, args []string) ([]byte, error) {
+	f.runs = append(f.runs, append([]string{command}, args...))
+	if f.failing {
+		return []byte("square.js:1:1: error  Unexpected var  no-var\n"), errors.New("exit status 1")
+	}
+	return nil, nil
+}
+
+func withLintRunner(runner LintRunner) func() {
+	original := lintRunner
+	lintRunner = runner
+	return func() { lintRunner = original }
+}
+
+func lintedFunction(as *assert.Assertions) (string, options.InitOptions, ArtifactsGenerator) {
+	dir, err := ioutil.TempDir("", "riff-lint")
+	as.NoError(err)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square.js"), []byte("var square = x => x ** 2;\n"), 0644))
+	opts := options.InitOptions{FunctionName: "square", Artifact: "square.js", Input: "in", Protocol: "http", UserAccount: "me", Version: "0.0.1", LintSource: true}
+	generator := ArtifactsGenerator{
+		Language:           "node",
+		GenerateFunction:   DefaultGenerateFunction,
+		GenerateDockerFile: func(options.InitOptions) (string, error) { return "FROM scratch\n", nil },
+	}
+	return dir, opts, generator
+}
+
+func TestLintErrorsAbortGeneration(t *testing.T) {
+	as := assert.New(t)
+	runner := &fakeLintRunner{installed: map[string]bool{"eslint": true}, failing: true}
+	defer withLintRunner(runner)()
+	dir, opts, generator := lintedFunction(as)
+	defer os.RemoveAll(dir)
+
+	err := GenerateFunctionArtfacts(generator, dir, opts)
+	as.EqualError(err, "eslint reported lint errors in square.js, fix them or initialize without --lint-source")
+	as.Equal([][]string{{"/usr/bin/eslint", "square.js"}}, runner.runs)
+	as.False(osutils.FileExists(filepath.Join(dir, "Dockerfile")))
+	as.False(osutils.FileExists(filepath.Join(dir, "square-function.yaml")))
+}
+
+func TestLintSourceSkippedWithoutLinter(t *testing.T) {
+	as := assert.New(t)
+	var stderr bytes.Buffer
+	ioutils.Stderr = &stderr
+	defer func() { ioutils.Stderr = os.Stderr }()
+	runner := &fakeLintRunner{failing: true}
+	defer withLintRunner(runner)()
+	dir, opts, generator := lintedFunction(as)
+	defer os.RemoveAll(dir)
+
+	as.NoError(GenerateFunctionArtfacts(generator, dir, opts))
+	as.Empty(runner.runs)
+	as.Contains(stderr.String(), "eslint not found, skipping --lint-source")
+	as.True(osutils.FileExists(filepath.Join(dir, "Dockerfile")))
+}
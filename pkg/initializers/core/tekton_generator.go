@@ -0,0 +1,96 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"text/template"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+)
+
+// The directory of the generated Tekton task, relative to the function directory, where riff apply does not look.
+const TektonDir = ".tekton"
+
+// The kaniko executor building the image in-cluster, pinned for reproducible builds.
+const kanikoImage = "gcr.io/kaniko-project/executor:v1.9.1"
+
+type TektonTask struct {
+	Name       string
+	Namespace  string
+	Image      string
+	Dockerfile string
+	Builder    string
+}
+
+/*
+ * The source workspace holds the build context, the function directory unless --context is given, and
+ * the image is pushed with the credentials of the service account the TaskRun uses.
+ */
+var tektonTaskTemplate = `
+apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: {{.Name}}-build
+{{- if .Namespace }}
+  namespace: {{.Namespace}}
+{{- end }}
+spec:
+  workspaces:
+  - name: source
+    description: the build context of the function
+  params:
+  - name: image
+    description: the image to build and push
+    default: {{.Image}}
+  steps:
+  - name: build-and-push
+    image: {{.Builder}}
+    workingDir: $(workspaces.source.path)
+    args:
+    - --dockerfile=$(workspaces.source.path)/{{.Dockerfile}}
+    - --context=$(workspaces.source.path)
+    - --destination=$(params.image)
+`
+
+func generateTektonTask(opts options.InitOptions) (string, error) {
+	task := TektonTask{
+		Name:       options.ResourceName(opts),
+		Namespace:  opts.Namespace,
+		Image:      options.ImageName(opts),
+		Dockerfile: path.Join(options.FunctionDirInContext(opts), "Dockerfile"),
+		Builder:    kanikoImage,
+	}
+
+	tmpl, err := template.New("tekton").Parse(tektonTaskTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, task)
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func tektonTaskPath(workdir string, opts options.InitOptions) string {
+	return filepath.Join(workdir, TektonDir, fmt.Sprintf("%s-%s.yaml", opts.FunctionName, "build"))
+}
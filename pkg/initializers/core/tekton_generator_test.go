@@ -0,0 +1,86 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+type YTektonTask struct {
+	Kind     string
+	Metadata struct {
+		Name string
+	}
+	Spec struct {
+		Params []struct {
+			Name    string
+			Default string
+		}
+		Steps []struct {
+			Image string
+			Args  []string
+		}
+	}
+}
+
+func TestTektonTask(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		FunctionName: "square",
+		UserAccount:  "me",
+		Registry:     "registry.example.com",
+		Version:      "0.0.1",
+	}
+
+	content, err := generateTektonTask(opts)
+	as.NoError(err)
+
+	task := YTektonTask{}
+	as.NoError(yaml.Unmarshal([]byte(content), &task))
+	as.Equal("Task", task.Kind)
+	as.Equal("square-build", task.Metadata.Name)
+	if as.Len(task.Spec.Params, 1) {
+		as.Equal("registry.example.com/me/square:0.0.1", task.Spec.Params[0].Default)
+	}
+	if as.Len(task.Spec.Steps, 1) {
+		as.Equal(kanikoImage, task.Spec.Steps[0].Image)
+		as.Contains(task.Spec.Steps[0].Args, "--dockerfile=$(workspaces.source.path)/Dockerfile")
+		as.Contains(task.Spec.Steps[0].Args, "--destination=$(params.image)")
+	}
+}
+
+func TestTektonTaskWithContext(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-tekton")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	functionDir := filepath.Join(dir, "functions", "square")
+	as.NoError(os.MkdirAll(functionDir, 0755))
+
+	opts := options.InitOptions{FunctionName: "square", FunctionPath: functionDir, Context: dir, UserAccount: "me", Version: "0.0.1"}
+	content, err := generateTektonTask(opts)
+	as.NoError(err)
+	as.Contains(content, "- --dockerfile=$(workspaces.source.path)/functions/square/Dockerfile\n")
+}
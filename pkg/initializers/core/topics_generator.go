@@ -23,9 +23,13 @@ import (
 )
 
 type Topic struct {
-	ApiVersion string
-	Name       string
-	Partitions int
+	ApiVersion  string
+	Name        string
+	Namespace   string
+	Partitions  int
+	Provider    string
+	Keyed       bool
+	Annotations map[string]string
 }
 
 //TODO: Flag for number of partitions?
@@ -34,10 +38,25 @@ func createTopics(opts options.InitOptions) (string, error) {
 	var topicTemplate string = `
 apiVersion : {{.ApiVersion}}
 kind: Topic
-metadata:	
+metadata:
   name: {{.Name}}
+{{- if .Namespace }}
+  namespace: {{.Namespace}}
+{{- end }}
+{{- if .Annotations }}
+  annotations:
+{{- range $key, $value := .Annotations }}
+    {{$key}}: {{printf "%q" $value}}
+{{- end }}
+{{- end }}
 spec:
   partitions: {{.Partitions}}
+{{- if .Provider }}
+  provider: {{.Provider}}
+{{- end }}
+{{- if .Keyed }}
+  keyed: true
+{{- end }}
 `
 	tmpl, err := template.New("topic").Parse(topicTemplate)
 	if err != nil {
@@ -46,14 +65,14 @@ spec:
 
 	var buffer bytes.Buffer
 
-	input := Topic{ApiVersion: ApiVersion, Name: opts.Input, Partitions: 1}
-	err = tmpl.Execute(&buffer, input)
-	if err != nil {
-		return "", err
-	}
-	if opts.Output != "" {
-		output := Topic{ApiVersion: ApiVersion, Name: opts.Output, Partitions: 1}
-		err = tmpl.Execute(&buffer, output)
+	// Each topic is generated once, even when it is both an input and an output.
+	topics := options.Topics(opts.Input + "," + opts.Output)
+	keyed := options.KeyedTopics(opts)
+	for i, name := range topics {
+		if i > 0 {
+			buffer.WriteString("---")
+		}
+		err = tmpl.Execute(&buffer, Topic{ApiVersion: options.ApiVersion(opts), Name: name, Namespace: opts.Namespace, Partitions: 1, Provider: opts.TopicProvider, Keyed: keyed[name], Annotations: options.Annotations(opts)})
 		if err != nil {
 			return "", err
 		}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package deno
+
+import (
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/initializers/core"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"path/filepath"
+)
+
+type DenoDockerFileTokens struct {
+	core.DockerFileTokens
+	DenoJsonExists bool
+}
+
+// The handler is optional, the invoker then calls the entrypoint's default export.
+var denoFunctionDockerfileTemplate = `
+FROM {{if .BaseImage}}{{.BaseImage}}{{else}}projectriff/deno-function-invoker:{{.RiffVersion}}{{.Variant}}{{end}}
+ENV FUNCTION_URI {{.ImageFunctionDir}}/{{.ArtifactBase}}{{with .UriQuery .Handler}}?{{.}}{{end}}
+{{.AddInstruction}} {{.Artifact}} {{.ImageFunctionDir}}/{{.ArtifactBase}}
+{{- if .DenoJsonExists }}
+{{.AddInstruction}} {{.Source "deno.json"}} {{.ImageFunctionDir}}/deno.json
+{{- end }}
+{{- if .CloudEvents }}
+ENV FUNCTION_CONTENT_MODE structured
+{{- end }}
+{{- if .Port }}
+EXPOSE {{.Port}}
+{{- end }}
+{{- if .RunAsUser }}
+USER {{.RunAsUser}}
+{{- end }}
+{{- if .Entrypoint }}
+ENTRYPOINT {{.Entrypoint}}
+{{- end }}
+{{- if .Cmd }}
+CMD {{.Cmd}}
+{{- end }}
+`
+
+func generateDenoFunctionDockerFile(opts options.InitOptions) (string, error) {
+	resolver, err := core.ResolveArtifact(opts.Artifact)
+	if err != nil {
+		return "", err
+	}
+	dockerFileTokens := DenoDockerFileTokens{DockerFileTokens: core.NewDockerFileTokens(opts, resolver)}
+	dockerFileTokens.DenoJsonExists = osutils.FileExists(filepath.Join(options.FunctionDir(opts), "deno.json"))
+	return core.GenerateFunctionDockerFileContents(denoFunctionDockerfileTemplate, "docker-deno", opts.TemplateDir, dockerFileTokens)
+}
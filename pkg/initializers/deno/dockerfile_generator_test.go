@@ -0,0 +1,64 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package deno
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDenoDockerfile(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "square.ts",
+		RiffVersion: "0.0.5",
+	}
+
+	docker, err := generateDenoFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, fmt.Sprintf("FROM projectriff/deno-function-invoker:%s", opts.RiffVersion))
+	as.Contains(docker, "ENV FUNCTION_URI /functions/square.ts\n")
+	as.Contains(docker, "ADD square.ts /functions/square.ts")
+	as.NotContains(docker, "deno.json")
+}
+
+func TestDenoDockerfileWithHandlerAndConfig(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-deno")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "deno.json"), []byte("{}\n"), 0644))
+
+	opts := options.InitOptions{
+		FunctionPath: dir,
+		Artifact:     "square.js",
+		RiffVersion:  "0.0.5",
+		Handler:      "square",
+	}
+
+	docker, err := generateDenoFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ENV FUNCTION_URI /functions/square.js?handler=square\n")
+	as.Contains(docker, "ADD deno.json /functions/deno.json")
+}
@@ -20,19 +20,26 @@ import (
 	"path/filepath"
 	"fmt"
 	"errors"
+	"io/ioutil"
+	"regexp"
+	"sort"
 
 	"github.com/projectriff/riff-cli/pkg/options"
 	"github.com/projectriff/riff-cli/pkg/initializers/java"
 	"github.com/projectriff/riff-cli/pkg/initializers/python"
 	"github.com/projectriff/riff-cli/pkg/initializers/node"
 	"github.com/projectriff/riff-cli/pkg/initializers/shell"
+	"github.com/projectriff/riff-cli/pkg/initializers/deno"
 	"github.com/projectriff/riff-cli/pkg/initializers/utils"
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/osutils"
 )
 
-var supportedExtensions = []string{"js", "java", "py", "sh"}
+var supportedExtensions = []string{"js", "java", "py", "sh", "ts"}
 
 type Initializer struct {
-	Initialize func(options.InitOptions) error
+	Initialize         func(options.InitOptions) error
+	GenerateDockerFile func(options.InitOptions) (string, error)
 }
 
 var languageForFileExtension = map[string]string{
@@ -40,54 +47,204 @@ var languageForFileExtension = map[string]string{
 	"java": "java",
 	"js":   "node",
 	"py":   "python",
+	"ts":   "deno",
+}
+
+// Imports of remote, jsr: or npm: modules, which only Deno resolves.
+var denoImportRegexp = regexp.MustCompile(`(?m)^\s*(import|export)\b.*\bfrom\s+["'](https?://|jsr:|npm:)`)
+
+// The registered languages and their initializers, each language having a 'riff init' subcommand.
+var languages = map[string]func() Initializer{
+	"java":   Java,
+	"node":   Node,
+	"python": Python,
+	"shell":  Shell,
+	"deno":   Deno,
+}
+
+// The names of the registered languages, sorted.
+func Languages() []string {
+	names := make([]string, 0, len(languages))
+	for name := range languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func Java() Initializer {
 	return Initializer{
-		Initialize: java.Initialize,
+		Initialize:         checkLanguage("java", requireHandler("java", "java", java.Initialize)),
+		GenerateDockerFile: java.GenerateDockerFile,
 	}
 }
 
 func Python() Initializer {
 	return Initializer{
-		Initialize: python.Initialize,
+		Initialize:         checkLanguage("python", requireHandler("python", "py", python.Initialize)),
+		GenerateDockerFile: python.GenerateDockerFile,
 	}
 }
 func Node() Initializer {
 	return Initializer{
-		Initialize: node.Initialize,
+		Initialize:         checkLanguage("node", node.Initialize),
+		GenerateDockerFile: node.GenerateDockerFile,
+	}
+}
+func Deno() Initializer {
+	return Initializer{
+		Initialize:         checkLanguage("deno", deno.Initialize),
+		GenerateDockerFile: deno.GenerateDockerFile,
 	}
 }
 func Shell() Initializer {
 	return Initializer{
-		Initialize: shell.Initialize,
+		Initialize:         checkLanguage("shell", shell.Initialize),
+		GenerateDockerFile: shell.GenerateDockerFile,
 	}
 }
 
+/*
+ * Warns, or fails under --strict, when the function files are confidently detected as another language than
+ * the one requested. Detection is only confident when it finds a single function file. With
+ * --explain-detection, the score of each language is printed. The invoker version is then taken from
+ * riff.lock, if any.
+ */
+func checkLanguage(language string, initialize func(options.InitOptions) error) func(options.InitOptions) error {
+	return func(opts options.InitOptions) error {
+		detection, err := ScoreLanguages(opts)
+		// A JSON dry run reports the detection itself.
+		if err == nil && opts.ExplainDetection && opts.OutputFormat != options.OutputFormatJson {
+			ioutils.Infof("%s", detection.Explain())
+		}
+		if detected := detection.Language; err == nil && detected != "" && detected != language {
+			err = options.Warning(opts.Strict, fmt.Sprintf("%s function requested but a %s function was detected, did you mean 'riff init %s'?", language, detected, detected))
+			if err != nil {
+				return err
+			}
+		}
+		err = LockRiffVersion(&opts, language)
+		if err != nil {
+			return err
+		}
+		return initialize(opts)
+	}
+}
+
+// What the handler is for the languages requiring one, with an example.
+var handlerHelp = map[string]string{
+	"java":   "the fully qualified name of the function class, e.g. 'riff init java --handler=functions.Greeter'",
+	"python": "the name of the function in the module, e.g. 'riff init python --handler=process' for 'def process(input):'",
+}
+
+/*
+ * Fails with an explanation of the handler when a language requiring one is given none, either with --handler
+ * or in the function source.
+ */
+func requireHandler(language string, extension string, initialize func(options.InitOptions) error) func(options.InitOptions) error {
+	return func(opts options.InitOptions) error {
+		if opts.Handler == "" && requiresHandler(language) {
+			functionFile, err := utils.ResolveFunctionFile(opts, language, extension)
+			if err != nil {
+				return err
+			}
+			opts.Handler, _ = utils.HandlerFromSource(functionFile, language)
+			if opts.Handler == "" {
+				return errors.New(fmt.Sprintf("handler required for %s functions, set --handler to %s", language, handlerHelp[language]))
+			}
+		}
+		return initialize(opts)
+	}
+}
+
+/*
+ * Detects the function language, mostly from the extension of the function file. A JavaScript function is a
+ * Deno one when its directory has a deno.json or it imports modules the way only Deno can.
+ */
+func DetectLanguage(opts options.InitOptions) (string, error) {
+	detection, err := ScoreLanguages(opts)
+	if err != nil {
+		return "", err
+	}
+	if winning, ok := detection.WinningSignal(); ok {
+		ioutils.Debugf("Detected %s function from %s\n", detection.Language, winning.Reason)
+	}
+	return detection.Language, nil
+}
+
+func isDeno(functionPath string) bool {
+	if osutils.FileExists(filepath.Join(filepath.Dir(functionPath), "deno.json")) {
+		return true
+	}
+	content, err := ioutil.ReadFile(functionPath)
+	return err == nil && denoImportRegexp.Match(content)
+}
+
 func Initialize(opts options.InitOptions) error {
-	functionPath, err := utils.ResolveFunctionFile(opts, "","")
+	detection, err := ScoreLanguages(opts)
 	if err != nil {
 		return err
 	}
+	language := detection.Language
+	if opts.DryRun && opts.OutputFormat == options.OutputFormatJson {
+		opts.Detection = detection.Report()
+	}
 
-	language := languageForFileExtension[filepath.Ext(functionPath)[1:]]
-
-	switch language {
-	case "shell":
-		Shell().Initialize(opts)
-	case "node":
-		Node().Initialize(opts)
-	case "java":
-		fmt.Println("Java resources detected. Use 'riff init java' to specify additional required options")
-		return nil
-	case "python":
-		fmt.Println("Python resources detected. Use 'riff init python' to specify additional required options")
-		return nil
-	default:
+	initializer, ok := languages[language]
+	if !ok {
 		//TODO: Should never get here
 		return errors.New(fmt.Sprintf("unsupported language %s\n", language))
 	}
-	return nil
+	if opts.Minimal && language != "java" {
+		return errors.New(fmt.Sprintf("--minimal is not supported for %s functions, only for java functions", language))
+	}
+	if opts.BuildTool != "" && language != "java" {
+		return errors.New(fmt.Sprintf("--build-tool is not supported for %s functions, only for java functions", language))
+	}
+	if opts.Shell != "" && language != "shell" {
+		return errors.New(fmt.Sprintf("--shell is not supported for %s functions, only for shell functions", language))
+	}
+	if opts.Inline && !supportsInline(language) {
+		return errors.New(fmt.Sprintf("--inline is not supported for %s functions, only for single file shell and node functions", language))
+	}
+	if opts.InstallCmd != "" && (!supportsInstallCmd(language) || opts.Minimal) {
+		return errors.New(fmt.Sprintf("--install-cmd is not supported for %s functions, only for node, java and python functions without --minimal", language))
+	}
+	if opts.QuietDeps && language != "node" && language != "python" {
+		return errors.New(fmt.Sprintf("--quiet-deps is not supported for %s functions, only for node and python functions", language))
+	}
+	return initializer().Initialize(opts)
+}
+
+// Generates the Dockerfile 'riff init' would now write for the function, detecting its language.
+func GenerateDockerFile(opts options.InitOptions) (string, error) {
+	language, err := DetectLanguage(opts)
+	if err != nil {
+		return "", err
+	}
+	initializer, ok := languages[language]
+	if !ok {
+		return "", errors.New(fmt.Sprintf("cannot detect the language of the function in %s", opts.FunctionPath))
+	}
+	err = LockRiffVersion(&opts, language)
+	if err != nil {
+		return "", err
+	}
+	return initializer().GenerateDockerFile(opts)
+}
+
+// Whether functions of the language name their handler explicitly, as their Dockerfile needs it.
+func requiresHandler(language string) bool {
+	_, ok := handlerHelp[language]
+	return ok
 }
 
+// Whether the Dockerfile of the language can install the function dependencies.
+func supportsInstallCmd(language string) bool {
+	return language == "node" || language == "java" || language == "python"
+}
 
+// Whether the function source of the language is a single file that can be written into its Dockerfile.
+func supportsInline(language string) bool {
+	return language == "shell" || language == "node"
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package initializers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/initializers/java"
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPythonChosenButNodeDetected(t *testing.T) {
+	as := assert.New(t)
+	var stderr bytes.Buffer
+	ioutils.Stderr = &stderr
+	defer func() { ioutils.Stderr = os.Stderr }()
+
+	opts := options.InitOptions{FunctionPath: osutils.Path("../../test_data/node/square"), FunctionName: "square", DryRun: true}
+	Python().Initialize(opts)
+	as.Contains(stderr.String(), "python function requested but a node function was detected")
+}
+
+func TestPythonChosenButNodeDetectedWhenStrict(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{FunctionPath: osutils.Path("../../test_data/node/square"), FunctionName: "square", DryRun: true, Strict: true}
+	err := Python().Initialize(opts)
+	as.Error(err)
+	as.Contains(err.Error(), "did you mean 'riff init node'?")
+}
+
+func TestMatchingLanguageDetected(t *testing.T) {
+	as := assert.New(t)
+	var stderr bytes.Buffer
+	ioutils.Stderr = &stderr
+	defer func() { ioutils.Stderr = os.Stderr }()
+
+	opts := options.InitOptions{FunctionPath: osutils.Path("../../test_data/node/square"), FunctionName: "square", Version: "0.0.1", DryRun: true, Strict: true}
+	as.NoError(Node().Initialize(opts))
+	as.Empty(stderr.String())
+}
+
+func TestDetectedJavaWithoutHandler(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-java")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square.java"), []byte("public class Square {}\n"), 0644))
+
+	opts := options.InitOptions{FunctionPath: dir, FunctionName: "square", DryRun: true}
+	err = Initialize(opts)
+	as.Error(err)
+	as.Contains(err.Error(), "handler required for java")
+}
+
+func TestJavaWithoutHandlerExplainsIt(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-java")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "greeter.java"), []byte("public class Greeter {}\n"), 0644))
+
+	opts := options.InitOptions{FunctionPath: dir, FunctionName: "greeter", DryRun: true}
+	err = Java().Initialize(opts)
+	as.Error(err)
+	as.Equal("handler required for java functions, set --handler to the fully qualified name of the function class, e.g. 'riff init java --handler=functions.Greeter'", err.Error())
+}
+
+func TestJavaJarArtifactWithoutHandlerExplainsIt(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-java")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(os.Mkdir(filepath.Join(dir, "target"), 0755))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "target", "greeter-1.0.0.jar"), []byte("PK"), 0644))
+
+	opts := options.InitOptions{FunctionPath: dir, FunctionName: "greeter", Artifact: "target/greeter-1.0.0.jar", Version: "0.0.1", DryRun: true}
+	err = Java().Initialize(opts)
+	as.Error(err)
+	as.Contains(err.Error(), "handler required for java")
+
+	opts.Handler = "functions.Greeter"
+	as.NoError(Java().Initialize(opts))
+}
+
+func TestJavaJarBuiltInImageFromCleanCheckout(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-java")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "pom.xml"), []byte("<project/>\n"), 0644))
+
+	opts := options.InitOptions{FunctionPath: dir, FunctionName: "greeter", Artifact: "target/greeter-1.0.0.jar", Handler: "functions.Greeter", Version: "0.0.1", DryRun: true}
+	as.NoError(options.ValidateAndCleanInitOptions(&opts))
+	as.NoError(Java().Initialize(opts))
+	dockerfile, err := java.GenerateDockerFile(opts)
+	as.NoError(err)
+	as.Contains(dockerfile, "COPY --from=build /workspace/target/greeter-1.0.0.jar $FUNCTION_JAR\n")
+
+	// Without a build stage, the jar must be built beforehand.
+	opts.BuildTool = options.BuildToolNone
+	err = options.ValidateAndCleanInitOptions(&opts)
+	as.Error(err)
+	as.Contains(err.Error(), "does not exist")
+}
+
+func TestPythonArtifactHandlerFromSource(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-python")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "foo.py"), []byte("# riff:handler=process\ndef process(input):\n    return input\n"), 0644))
+
+	opts := options.InitOptions{FunctionPath: dir, FunctionName: "foo", Artifact: "foo.py", Version: "0.0.1", DryRun: true}
+	as.NoError(Python().Initialize(opts))
+
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "foo.py"), []byte("def process(input):\n    return input\n"), 0644))
+	err = Python().Initialize(opts)
+	as.Error(err)
+	as.Contains(err.Error(), "handler required for python")
+}
+
+func TestDetectedPythonWithoutHandler(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{FunctionPath: osutils.Path("../../test_data/python/demo"), FunctionName: "demo", DryRun: true}
+	err := Initialize(opts)
+	as.Error(err)
+	as.Contains(err.Error(), "handler required for python")
+}
+
+func TestDetectedLanguageWithoutRequiredHandler(t *testing.T) {
+	as := assert.New(t)
+	as.True(requiresHandler("java"))
+	as.True(requiresHandler("python"))
+	as.False(requiresHandler("node"))
+	as.False(requiresHandler("shell"))
+}
+
+func TestDenoDetectedFromDenoJson(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-deno")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square.js"), []byte("export default x => x ** 2\n"), 0644))
+
+	opts := options.InitOptions{FunctionPath: dir, FunctionName: "square"}
+	language, err := DetectLanguage(opts)
+	as.NoError(err)
+	as.Equal("node", language)
+
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "deno.json"), []byte("{}\n"), 0644))
+	language, err = DetectLanguage(opts)
+	as.NoError(err)
+	as.Equal("deno", language)
+}
+
+func TestDenoDetectedFromImports(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-deno")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square.js"), []byte("import { assert } from \"jsr:@std/assert\";\nexport default x => x ** 2\n"), 0644))
+
+	language, err := DetectLanguage(options.InitOptions{FunctionPath: dir, FunctionName: "square"})
+	as.NoError(err)
+	as.Equal("deno", language)
+}
+
+func TestDenoDetectedFromTypeScript(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-deno")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square.ts"), []byte("export default (x: number) => x ** 2\n"), 0644))
+
+	language, err := DetectLanguage(options.InitOptions{FunctionPath: dir, FunctionName: "square"})
+	as.NoError(err)
+	as.Equal("deno", language)
+}
+
+func TestExplainDetectionOfNodeFunction(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-detection")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square.js"), []byte("module.exports = x => x ** 2\n"), 0644))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "helpers.js"), []byte("module.exports = {}\n"), 0644))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "package.json"), []byte("{}\n"), 0644))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("requests\n"), 0644))
+
+	detection, err := ScoreLanguages(options.InitOptions{FunctionPath: dir, FunctionName: "square"})
+	as.NoError(err)
+	as.Equal("node", detection.Language)
+	as.Equal(map[string]int{"node": 14, "python": 3}, detection.Scores)
+
+	explanation := detection.Explain()
+	as.Contains(explanation, "  node     14  function file square.js (+10), package.json (+3), 1 other .js source file(s) (+1)\n")
+	as.Contains(explanation, "  python    3  requirements.txt (+3)\n")
+	as.True(strings.Index(explanation, "node") < strings.Index(explanation, "python"))
+	as.Contains(explanation, "Detected node, chiefly from function file square.js\n")
+}
+
+func TestJsonDryRunReportsDetectedLanguage(t *testing.T) {
+	as := assert.New(t)
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	as.NoError(err)
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	opts := options.InitOptions{
+		FunctionPath: osutils.Path("../../test_data/python/demo"),
+		FunctionName: "demo",
+		Handler:      "process",
+		Version:      "0.0.1",
+		DryRun:       true,
+		OutputFormat: options.OutputFormatJson,
+	}
+	err = Initialize(opts)
+	w.Close()
+	os.Stdout = stdout
+	as.NoError(err)
+	out, err := ioutil.ReadAll(r)
+	as.NoError(err)
+
+	var report struct {
+		DetectedLanguage string
+		Signals          []options.DetectionSignal
+		Files            map[string]string
+	}
+	as.NoError(json.Unmarshal(out, &report))
+	as.Equal("python", report.DetectedLanguage)
+	as.Contains(report.Signals, options.DetectionSignal{Language: "python", Weight: 10, Reason: "function file demo.py"})
+	as.Contains(report.Files["Dockerfile"], "python2-function-invoker")
+	as.Contains(report.Files, "demo-function.yaml")
+}
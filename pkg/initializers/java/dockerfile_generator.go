@@ -17,25 +17,146 @@
 package java
 
 import (
+	"path"
 	"path/filepath"
+
 	"github.com/projectriff/riff-cli/pkg/options"
 	"github.com/projectriff/riff-cli/pkg/initializers/core"
 )
 
+type JavaDockerFileTokens struct {
+	core.DockerFileTokens
+	Build *BuildStage
+}
+
+// A build stage compiling the function jar with a build tool, for the invoker stage to copy.
+type BuildStage struct {
+	Image     string
+	Workspace string
+	Chown     string
+	Command   string
+	Jar       string
+	outputDir string
+}
+
+// The image, commands and output directory conventions of each build tool.
+var buildStages = map[string]BuildStage{
+	options.BuildToolMaven: {
+		Image:     "maven:3-jdk-8",
+		Workspace: "/workspace",
+		Command:   "mvn -B package -DskipTests",
+		outputDir: "target",
+	},
+	options.BuildToolGradle: {
+		// The gradle image builds as the unprivileged gradle user.
+		Image:     "gradle:4.10-jdk8",
+		Workspace: "/home/gradle/workspace",
+		Chown:     "gradle:gradle",
+		Command:   "gradle build -x test --no-daemon",
+		outputDir: "build/libs",
+	},
+}
+
+// The build files each build tool is detected from, in order of precedence.
+var buildStageTemplate = `
+{{- define "build" }}
+{{- with .Build }}FROM {{.Image}} AS build
+WORKDIR {{.Workspace}}
+COPY{{if .Chown}} --chown={{.Chown}}{{end}} {{$.FunctionDir}} .
+RUN {{.Command}}
+
+{{ end }}
+{{- end }}`
+
 var dockerfileTemplate = `
-FROM projectriff/java-function-invoker:{{.RiffVersion}}
-ARG FUNCTION_JAR=/functions/{{.ArtifactBase}}
+{{template "build" .}}FROM {{if .BaseImage}}{{.BaseImage}}{{else}}projectriff/java-function-invoker:{{.RiffVersion}}{{.Variant}}{{end}}
+ARG FUNCTION_JAR={{.ImageFunctionDir}}/{{.ArtifactBase}}
+ARG FUNCTION_CLASS={{.Handler}}
+{{- with .Build }}
+COPY --from=build {{.Workspace}}/{{.Jar}} $FUNCTION_JAR
+{{- else }}
+{{.AddInstruction}} {{.Artifact}} $FUNCTION_JAR
+{{- end }}
+{{- if .InstallCmd }}
+RUN {{.InstallCmd}}
+{{- end }}
+ENV FUNCTION_URI file://${FUNCTION_JAR}?{{.UriQuery "${FUNCTION_CLASS}"}}
+{{- if .CloudEvents }}
+ENV FUNCTION_CONTENT_MODE structured
+{{- end }}
+{{- if .Port }}
+EXPOSE {{.Port}}
+{{- end }}
+{{- if .RunAsUser }}
+USER {{.RunAsUser}}
+{{- end }}
+{{- if .Entrypoint }}
+ENTRYPOINT {{.Entrypoint}}
+{{- end }}
+{{- if .Cmd }}
+CMD {{.Cmd}}
+{{- end }}
+`
+
+/*
+ * With --minimal, the invoker image is only a build stage: its jar is copied with the function jar onto a
+ * distroless java image, which has no shell or package manager.
+ */
+var minimalDockerfileTemplate = `
+{{template "build" .}}FROM {{if .BaseImage}}{{.BaseImage}}{{else}}projectriff/java-function-invoker:{{.RiffVersion}}{{.Variant}}{{end}} AS invoker
+
+FROM gcr.io/distroless/java:8
+COPY --from=invoker /java-function-invoker.jar /java-function-invoker.jar
+ARG FUNCTION_JAR={{.ImageFunctionDir}}/{{.ArtifactBase}}
 ARG FUNCTION_CLASS={{.Handler}}
-ADD target/{{.ArtifactBase}} $FUNCTION_JAR
-ENV FUNCTION_URI file://${FUNCTION_JAR}?handler=${FUNCTION_CLASS}
+COPY {{with .Build}}--from=build {{.Workspace}}/{{.Jar}}{{else}}{{.Artifact}}{{end}} $FUNCTION_JAR
+ENV FUNCTION_URI file://${FUNCTION_JAR}?{{.UriQuery "${FUNCTION_CLASS}"}}
+{{- if .CloudEvents }}
+ENV FUNCTION_CONTENT_MODE structured
+{{- end }}
+{{- if .Port }}
+EXPOSE {{.Port}}
+{{- end }}
+{{- if .RunAsUser }}
+USER {{.RunAsUser}}
+{{- end }}
+ENTRYPOINT {{if .Entrypoint}}{{.Entrypoint}}{{else}}["/usr/bin/java", "-jar", "/java-function-invoker.jar"]{{end}}
+{{- if .Cmd }}
+CMD {{.Cmd}}
+{{- end }}
 `
 
+// What the build ARGs of the java FUNCTION_URI are.
+var javaFunctionUriArgs = map[string]string{
+	"FUNCTION_JAR":   "the path of the function jar in the image",
+	"FUNCTION_CLASS": "the function class, given with --handler",
+}
+
 func generateJavaFunctionDockerFile(opts options.InitOptions) (string, error) {
-	dockerFileTokens := core.DockerFileTokens{
-		Artifact:     opts.Artifact,
-		ArtifactBase: filepath.Base(opts.Artifact),
-		RiffVersion:  opts.RiffVersion,
-		Handler:      opts.Handler,
+	resolver, err := core.ResolveArtifact(opts.Artifact)
+	if err != nil {
+		return "", err
+	}
+	dockerFileTokens := JavaDockerFileTokens{
+		DockerFileTokens: core.NewDockerFileTokens(opts, resolver),
+		Build:            buildStage(opts),
+	}
+	if opts.Minimal {
+		return core.GenerateFunctionDockerFileContents(buildStageTemplate+minimalDockerfileTemplate, "docker-java", opts.TemplateDir, dockerFileTokens)
+	}
+	return core.GenerateFunctionDockerFileContents(buildStageTemplate+dockerfileTemplate, "docker-java", opts.TemplateDir, dockerFileTokens)
+}
+
+/*
+ * The stage building the function jar, or nil when it is built beforehand. The jar named by the artifact is
+ * copied from where the build tool outputs jars, target/ for maven and build/libs/ for gradle, whatever the
+ * directory of the artifact.
+ */
+func buildStage(opts options.InitOptions) *BuildStage {
+	stage, ok := buildStages[options.JarBuildTool(opts)]
+	if !ok {
+		return nil
 	}
-	return core.GenerateFunctionDockerFileContents(dockerfileTemplate, "docker-java", dockerFileTokens)
+	stage.Jar = path.Join(stage.outputDir, path.Base(filepath.ToSlash(opts.Artifact)))
+	return &stage
 }
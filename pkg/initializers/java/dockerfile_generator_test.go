@@ -17,8 +17,13 @@
 package java
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/initializers/core"
 	"fmt"
+	"strings"
 	"testing"
 	"github.com/stretchr/testify/assert"
 )
@@ -39,3 +44,245 @@ func TestJavaDockerfile(t *testing.T) {
 	as.Contains(docker, fmt.Sprintf("ARG FUNCTION_CLASS=%s", opts.Handler))
 	as.Contains(docker, fmt.Sprintf("ADD %s $FUNCTION_JAR", opts.Artifact))
 }
+
+func TestJavaDockerfileWithArtifactBase(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "target/greeter-1.0.0.jar",
+		ArtifactBase: "greeter.jar",
+		RiffVersion:  "0.0.2",
+		Handler:      "functions.Greeter",
+	}
+
+	docker, err := generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ARG FUNCTION_JAR=/functions/greeter.jar")
+	as.Contains(docker, "ADD target/greeter-1.0.0.jar $FUNCTION_JAR")
+}
+
+func TestJavaDockerfileWithUriParams(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "target/greeter-1.0.0.jar",
+		RiffVersion: "0.0.2",
+		Handler:     "functions.Greeter",
+		UriParams:   []string{"timeout=5s", "greeting=hi there"},
+	}
+
+	docker, err := generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ENV FUNCTION_URI file://${FUNCTION_JAR}?handler=${FUNCTION_CLASS}&timeout=5s&greeting=hi+there")
+}
+
+func TestJavaDockerfileWithBaseImage(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "target/greeter-1.0.0.jar",
+		RiffVersion: "0.0.2",
+		Handler:     "functions.Greeter",
+		BaseImage:   "myregistry/custom-invoker@sha256:4a1c4b21597c1b4415bdbecb28a3296c6b5e23ca4f9feeb599860a1dac6a0108",
+	}
+
+	docker, err := generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "FROM myregistry/custom-invoker@sha256:4a1c4b21597c1b4415bdbecb28a3296c6b5e23ca4f9feeb599860a1dac6a0108\n")
+	as.NotContains(docker, "projectriff/java-function-invoker")
+}
+
+func TestMinimalJavaDockerfile(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "target/greeter-1.0.0.jar",
+		RiffVersion: "0.0.2",
+		Handler:     "functions.Greeter",
+		Minimal:     true,
+	}
+
+	docker, err := generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "FROM projectriff/java-function-invoker:0.0.2 AS invoker\n")
+	finalStage := docker[strings.LastIndex(docker, "FROM "):]
+	as.True(strings.HasPrefix(finalStage, "FROM gcr.io/distroless/java:8\n"))
+	as.Contains(finalStage, "COPY --from=invoker /java-function-invoker.jar /java-function-invoker.jar\n")
+	as.Contains(finalStage, "COPY target/greeter-1.0.0.jar $FUNCTION_JAR\n")
+	as.Contains(finalStage, "ENV FUNCTION_URI file://${FUNCTION_JAR}?handler=${FUNCTION_CLASS}\n")
+	as.Contains(finalStage, "ENTRYPOINT [\"/usr/bin/java\", \"-jar\", \"/java-function-invoker.jar\"]\n")
+	as.Equal(1, strings.Count(finalStage, "ENTRYPOINT "))
+}
+
+func TestJavaDockerfileWithInstallCmd(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "target/greeter-1.0.0.jar",
+		RiffVersion: "0.0.2",
+		Handler:     "functions.Greeter",
+		InstallCmd:  "./mvnw dependency:go-offline",
+	}
+
+	docker, err := generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ADD target/greeter-1.0.0.jar $FUNCTION_JAR\nRUN ./mvnw dependency:go-offline\nENV FUNCTION_URI")
+}
+
+func TestJavaDockerfileWithImageVariant(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "target/greeter-1.0.0.jar",
+		RiffVersion:  "1.0.0",
+		Handler:      "functions.Greeter",
+		ImageVariant: "-jre11",
+	}
+
+	docker, err := generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "FROM projectriff/java-function-invoker:1.0.0-jre11\n")
+
+	opts.Minimal = true
+	docker, err = generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "FROM projectriff/java-function-invoker:1.0.0-jre11 AS invoker\n")
+
+	opts.Minimal = false
+	opts.BaseImage = "example.com/java-invoker:1.0.0"
+	docker, err = generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "FROM example.com/java-invoker:1.0.0\n")
+}
+
+func TestJavaDockerfileWithRemoteArtifact(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "https://repo.example.com/maven2/functions/greeter/1.0.0/greeter-1.0.0.jar",
+		RiffVersion: "0.0.2",
+		Handler:     "functions.Greeter",
+		UseCopy:     true,
+	}
+
+	docker, err := generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ARG FUNCTION_JAR=/functions/greeter-1.0.0.jar")
+	as.Contains(docker, "ADD https://repo.example.com/maven2/functions/greeter/1.0.0/greeter-1.0.0.jar $FUNCTION_JAR")
+
+	opts.Artifact = "s3://acme-functions/greeter-1.0.0.jar"
+	docker, err = generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ADD https://acme-functions.s3.amazonaws.com/greeter-1.0.0.jar $FUNCTION_JAR")
+}
+
+func TestMinimalJavaDockerfileWithEntrypoint(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "target/greeter-1.0.0.jar",
+		RiffVersion: "0.0.2",
+		Handler:     "functions.Greeter",
+		Minimal:     true,
+		Entrypoint:  `["/usr/bin/java", "-Xmx256m", "-jar", "/java-function-invoker.jar"]`,
+	}
+
+	docker, err := generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	// The override replaces the ENTRYPOINT of the template.
+	as.True(strings.HasSuffix(docker, "\nENTRYPOINT [\"/usr/bin/java\", \"-Xmx256m\", \"-jar\", \"/java-function-invoker.jar\"]\n"))
+	as.Equal(1, strings.Count(docker, "ENTRYPOINT "))
+}
+
+func TestExplainJavaFunctionUri(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "target/greeter-1.0.0.jar",
+		RiffVersion: "0.0.2",
+		Handler:     "functions.Greeter",
+	}
+
+	docker, err := generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	explanation, err := core.ExplainFunctionUri(docker, javaFunctionUriArgs)
+	as.NoError(err)
+	as.Equal(`FUNCTION_URI file://${FUNCTION_JAR}?handler=${FUNCTION_CLASS}
+  is file:///functions/greeter-1.0.0.jar?handler=functions.Greeter
+  scheme: file
+  path:   /functions/greeter-1.0.0.jar
+  query:
+    handler=functions.Greeter
+  where the build args are
+    FUNCTION_JAR=/functions/greeter-1.0.0.jar, the path of the function jar in the image
+    FUNCTION_CLASS=functions.Greeter, the function class, given with --handler
+`, explanation)
+}
+
+func TestJavaDockerfileBuiltWithMaven(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "target/greeter-1.0.0.jar",
+		RiffVersion: "0.0.2",
+		Handler:     "functions.Greeter",
+		BuildTool:   options.BuildToolMaven,
+	}
+
+	docker, err := generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.True(strings.HasPrefix(docker, "\nFROM maven:3-jdk-8 AS build\nWORKDIR /workspace\nCOPY . .\nRUN mvn -B package -DskipTests\n\nFROM projectriff/java-function-invoker:0.0.2\n"))
+	as.Contains(docker, "COPY --from=build /workspace/target/greeter-1.0.0.jar $FUNCTION_JAR\n")
+	as.NotContains(docker, "ADD")
+}
+
+func TestJavaDockerfileBuiltWithGradle(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "greeter-1.0.0.jar",
+		RiffVersion: "0.0.2",
+		Handler:     "functions.Greeter",
+		BuildTool:   options.BuildToolGradle,
+		Minimal:     true,
+	}
+
+	docker, err := generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.True(strings.HasPrefix(docker, "\nFROM gradle:4.10-jdk8 AS build\nWORKDIR /home/gradle/workspace\nCOPY --chown=gradle:gradle . .\nRUN gradle build -x test --no-daemon\n\nFROM projectriff/java-function-invoker:0.0.2 AS invoker\n"))
+	as.Contains(docker, "COPY --from=build /home/gradle/workspace/build/libs/greeter-1.0.0.jar $FUNCTION_JAR\n")
+
+	// The jar is copied from where gradle outputs it, whatever the directory of the artifact.
+	opts.Artifact = "target/greeter-1.0.0.jar"
+	docker, err = generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "COPY --from=build /home/gradle/workspace/build/libs/greeter-1.0.0.jar $FUNCTION_JAR\n")
+	as.NotContains(docker, "/target/")
+}
+
+func TestJavaBuildToolDetected(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-java")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	opts := options.InitOptions{FunctionPath: dir, Artifact: "greeter-1.0.0.jar", RiffVersion: "0.0.2", Handler: "functions.Greeter"}
+	docker, err := generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.True(strings.HasPrefix(docker, "\nFROM projectriff/java-function-invoker:0.0.2\n"))
+
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "build.gradle"), []byte("apply plugin: 'java'\n"), 0644))
+	docker, err = generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "FROM gradle:4.10-jdk8 AS build\n")
+
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "pom.xml"), []byte("<project/>\n"), 0644))
+	docker, err = generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "FROM maven:3-jdk-8 AS build\n")
+
+	opts.BuildTool = options.BuildToolNone
+	docker, err = generateJavaFunctionDockerFile(opts)
+	as.NoError(err)
+	as.NotContains(docker, "AS build")
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package initializers
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/projectriff/riff-cli/pkg/initializers/utils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+)
+
+/*
+ * The weights of the signals found when detecting the function language. The function file outweighs all
+ * other signals together, which only decide when its extension names no language, as for a jar.
+ */
+const (
+	functionFileWeight   = 10
+	manifestWeight       = 3
+	maxSourceFilesWeight = 3
+)
+
+// The files that declare a project of the language, such as its dependencies.
+var languageManifests = map[string]string{
+	"package.json":     "node",
+	"pom.xml":          "java",
+	"build.gradle":     "java",
+	"requirements.txt": "python",
+	"setup.py":         "python",
+	"deno.json":        "deno",
+}
+
+// A clue to the function language found in its directory.
+type Signal struct {
+	Language string
+	Weight   int
+	Reason   string
+}
+
+// The outcome of language detection: the language scoring highest, the score of each candidate and their signals.
+type Detection struct {
+	Language string
+	Scores   map[string]int
+	Signals  []Signal
+}
+
+/*
+ * Scores each language by the signals found for it: the extension of the function file, the manifests next
+ * to it and the other source files of the directory.
+ */
+func ScoreLanguages(opts options.InitOptions) (Detection, error) {
+	detection := Detection{Scores: map[string]int{}}
+	functionPath, err := utils.ResolveFunctionFile(opts, "", "")
+	if err != nil {
+		return detection, err
+	}
+	dir := filepath.Dir(functionPath)
+	name := filepath.Base(functionPath)
+
+	language := languageForFileExtension[strings.TrimPrefix(filepath.Ext(functionPath), ".")]
+	switch {
+	case language == "node" && osutils.FileExists(filepath.Join(dir, "deno.json")):
+		detection.add("deno", functionFileWeight, fmt.Sprintf("function file %s is next to deno.json", name))
+	case language == "node" && isDeno(functionPath):
+		detection.add("deno", functionFileWeight, fmt.Sprintf("function file %s imports modules only Deno resolves", name))
+	case language != "":
+		detection.add(language, functionFileWeight, fmt.Sprintf("function file %s", name))
+	}
+
+	manifests := make([]string, 0, len(languageManifests))
+	for manifest := range languageManifests {
+		manifests = append(manifests, manifest)
+	}
+	sort.Strings(manifests)
+	for _, manifest := range manifests {
+		if osutils.FileExists(filepath.Join(dir, manifest)) {
+			detection.add(languageManifests[manifest], manifestWeight, manifest)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		return detection, err
+	}
+	sourceFiles := map[string]int{}
+	for _, file := range files {
+		ext := strings.TrimPrefix(filepath.Ext(file), ".")
+		if file != functionPath && languageForFileExtension[ext] != "" && !osutils.IsDirectory(file) {
+			sourceFiles[ext]++
+		}
+	}
+	for _, ext := range supportedExtensions {
+		if count := sourceFiles[ext]; count > 0 {
+			weight := count
+			if weight > maxSourceFilesWeight {
+				weight = maxSourceFilesWeight
+			}
+			detection.add(languageForFileExtension[ext], weight, fmt.Sprintf("%d other .%s source file(s)", count, ext))
+		}
+	}
+
+	for _, candidate := range detection.candidates() {
+		if detection.Language == "" || detection.Scores[candidate] > detection.Scores[detection.Language] {
+			detection.Language = candidate
+		}
+	}
+	return detection, nil
+}
+
+func (detection *Detection) add(language string, weight int, reason string) {
+	detection.Signals = append(detection.Signals, Signal{Language: language, Weight: weight, Reason: reason})
+	detection.Scores[language] += weight
+}
+
+// The languages with a score, the highest first, then by name.
+func (detection Detection) candidates() []string {
+	var languages []string
+	for language := range detection.Scores {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if detection.Scores[languages[i]] != detection.Scores[languages[j]] {
+			return detection.Scores[languages[i]] > detection.Scores[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+	return languages
+}
+
+// The signal weighing most in favour of the detected language.
+func (detection Detection) WinningSignal() (Signal, bool) {
+	var winning Signal
+	found := false
+	for _, signal := range detection.Signals {
+		if signal.Language == detection.Language && (!found || signal.Weight > winning.Weight) {
+			winning = signal
+			found = true
+		}
+	}
+	return winning, found
+}
+
+// The detection as reported by a JSON dry run.
+func (detection Detection) Report() *options.DetectionReport {
+	report := &options.DetectionReport{DetectedLanguage: detection.Language, Scores: detection.Scores}
+	for _, signal := range detection.Signals {
+		report.Signals = append(report.Signals, options.DetectionSignal{Language: signal.Language, Weight: signal.Weight, Reason: signal.Reason})
+	}
+	return report
+}
+
+// Describes the score of each candidate language with its signals, and the signal the detected language won by.
+func (detection Detection) Explain() string {
+	var explanation bytes.Buffer
+	explanation.WriteString("Language detection scores:\n")
+	for _, language := range detection.candidates() {
+		var reasons []string
+		for _, signal := range detection.Signals {
+			if signal.Language == language {
+				reasons = append(reasons, fmt.Sprintf("%s (+%d)", signal.Reason, signal.Weight))
+			}
+		}
+		fmt.Fprintf(&explanation, "  %-7s %3d  %s\n", language, detection.Scores[language], strings.Join(reasons, ", "))
+	}
+	if winning, ok := detection.WinningSignal(); ok {
+		fmt.Fprintf(&explanation, "Detected %s, chiefly from %s\n", detection.Language, winning.Reason)
+	} else {
+		explanation.WriteString("No language detected\n")
+	}
+	return explanation.String()
+}
@@ -18,22 +18,54 @@ package node
 
 import (
 	"github.com/projectriff/riff-cli/pkg/options"
-	"path/filepath"
 	"github.com/projectriff/riff-cli/pkg/initializers/core"
 )
 
 var nodeFunctionDockerfileTemplate = `
-FROM projectriff/node-function-invoker:{{.RiffVersion}}
-ENV FUNCTION_URI /functions/{{.Artifact}}
-ADD {{.ArtifactBase}} ${FUNCTION_URI}
+FROM {{if .BaseImage}}{{.BaseImage}}{{else}}projectriff/node-function-invoker:{{.RiffVersion}}{{.Variant}}{{end}}
+ENV FUNCTION_URI {{.ImageFunctionDir}}/{{.ArtifactBase}}
+{{- if .Inline }}
+RUN mkdir -p {{.ImageFunctionDir}} && printf '%s\n' \
+    {{.Inline}} \
+    > {{.ImageFunctionDir}}/{{.ArtifactBase}}
+{{- else }}
+{{.AddInstruction}} {{.Artifact}} ${FUNCTION_URI}
+{{- end }}
+{{- if .QuietDeps }}
+ARG NPM_CONFIG_LOGLEVEL=silent
+{{- end }}
+{{- if .InstallCmd }}
+RUN {{.InstallCmd}}
+{{- end }}
+{{- if .CloudEvents }}
+ENV FUNCTION_CONTENT_MODE structured
+{{- end }}
+{{- if .Port }}
+EXPOSE {{.Port}}
+{{- end }}
+{{- if .RunAsUser }}
+USER {{.RunAsUser}}
+{{- end }}
+{{- if .Entrypoint }}
+ENTRYPOINT {{.Entrypoint}}
+{{- end }}
+{{- if .Cmd }}
+CMD {{.Cmd}}
+{{- end }}
 `
 
 
 func generateNodeFunctionDockerFile(opts options.InitOptions) (string, error) {
-	dockerFileTokens := core.DockerFileTokens{
-		Artifact:     opts.Artifact,
-		ArtifactBase: filepath.Base(opts.Artifact),
-		RiffVersion:  opts.RiffVersion,
+	resolver, err := core.ResolveArtifact(opts.Artifact)
+	if err != nil {
+		return "", err
 	}
-	return core.GenerateFunctionDockerFileContents(nodeFunctionDockerfileTemplate, "docker-node", dockerFileTokens)
+	dockerFileTokens := core.NewDockerFileTokens(opts, resolver)
+	if opts.Inline {
+		dockerFileTokens.Inline, err = core.InlineSource(opts)
+		if err != nil {
+			return "", err
+		}
+	}
+	return core.GenerateFunctionDockerFileContents(nodeFunctionDockerfileTemplate, "docker-node", opts.TemplateDir, dockerFileTokens)
 }
@@ -17,8 +17,12 @@
 package node
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"github.com/projectriff/riff-cli/pkg/options"
 	"fmt"
+	"strings"
 	"testing"
 	"github.com/stretchr/testify/assert"
 )
@@ -37,4 +41,186 @@ func TestNodeDockerfile(t *testing.T) {
 	as.Contains(docker, fmt.Sprintf("FROM projectriff/node-function-invoker:%s", opts.RiffVersion))
 	as.Contains(docker, fmt.Sprintf("ENV FUNCTION_URI /functions/%s", opts.Artifact))
 	as.Contains(docker, fmt.Sprintf("ADD %s ${FUNCTION_URI}", opts.Artifact))
+	as.NotContains(docker, "USER")
+}
+
+func TestNodeDockerfileWithArtifactBase(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "lib/square.js",
+		ArtifactBase: "index.js",
+		RiffVersion:  "0.0.3",
+	}
+
+	docker, err := generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ENV FUNCTION_URI /functions/index.js")
+	as.Contains(docker, "ADD lib/square.js ${FUNCTION_URI}")
+}
+
+func TestNodeDockerfileExposesPort(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "square.js",
+		RiffVersion: "0.0.3",
+		Protocol:    "http",
+	}
+
+	docker, err := generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "EXPOSE 8080")
+
+	opts.Port = 9000
+	docker, err = generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "EXPOSE 9000")
+
+	opts.Port = 0
+	opts.Protocol = "stdio"
+	docker, err = generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.NotContains(docker, "EXPOSE")
+}
+
+func TestNodeDockerfileWithContext(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "square.js",
+		RiffVersion:  "0.0.3",
+		FunctionPath: "../../../test_data/node/square",
+		Context:      "../../../test_data",
+	}
+
+	docker, err := generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ENV FUNCTION_URI /functions/square.js")
+	as.Contains(docker, "ADD node/square/square.js ${FUNCTION_URI}")
+}
+
+func TestNodeDockerfileInline(t *testing.T) {
+	as := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "riff-inline")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "square.js"), []byte("module.exports = (x) => x ** 2;\n"), 0644))
+
+	opts := options.InitOptions{
+		FunctionPath: dir,
+		Artifact:     "square.js",
+		ArtifactBase: "square.js",
+		RiffVersion:  "0.0.3",
+		Inline:       true,
+	}
+
+	docker, err := generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.NotContains(docker, "ADD ")
+	as.Contains(docker, "ENV FUNCTION_URI /functions/square.js\n")
+	as.Contains(docker, "RUN mkdir -p /functions && printf '%s\\n' \\\n    'module.exports = (x) => x ** 2;' \\\n    > /functions/square.js\n")
+}
+
+func TestNodeDockerfileWithInstallCmd(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "square.js",
+		RiffVersion: "0.0.3",
+	}
+
+	docker, err := generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.NotContains(docker, "RUN")
+
+	opts.InstallCmd = "pnpm install --prod"
+	docker, err = generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ADD square.js ${FUNCTION_URI}\nRUN pnpm install --prod\n")
+}
+
+func TestNodeDockerfileWithCloudEvents(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "square.js",
+		RiffVersion: "0.0.3",
+	}
+
+	docker, err := generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.NotContains(docker, "FUNCTION_CONTENT_MODE")
+
+	opts.CloudEvents = true
+	docker, err = generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "\nENV FUNCTION_CONTENT_MODE structured\n")
+}
+
+func TestNodeDockerfileWithQuietDeps(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "square.js",
+		RiffVersion: "0.0.3",
+		InstallCmd:  "npm install --production",
+	}
+
+	docker, err := generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.NotContains(docker, "NPM_CONFIG_LOGLEVEL")
+
+	opts.QuietDeps = true
+	docker, err = generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ARG NPM_CONFIG_LOGLEVEL=silent\nRUN npm install --production\n")
+}
+
+func TestNodeDockerfileWithFunctionDir(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:         "square.js",
+		RiffVersion:      "0.0.3",
+		ImageFunctionDir: "/opt/functions/",
+	}
+
+	docker, err := generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ENV FUNCTION_URI /opt/functions/square.js\n")
+	as.Contains(docker, "ADD square.js ${FUNCTION_URI}\n")
+
+	opts.FunctionPath = "../../../test_data/node/square"
+	opts.ImageFunctionDir = "opt/functions"
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "function dir opt/functions must be an absolute path in the image")
+}
+
+func TestNodeDockerfileWithEntrypointAndCmd(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "square.js",
+		RiffVersion: "0.0.3",
+	}
+
+	docker, err := generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.NotContains(docker, "ENTRYPOINT")
+	as.NotContains(docker, "CMD")
+
+	opts.Entrypoint = "node --inspect"
+	opts.Cmd = `["/functions/square.js", "a \"quoted\" & spaced word"]`
+	docker, err = generateNodeFunctionDockerFile(opts)
+	as.NoError(err)
+	as.True(strings.HasSuffix(docker, "\nENTRYPOINT [\"node\", \"--inspect\"]\nCMD [\"/functions/square.js\", \"a \\\"quoted\\\" & spaced word\"]\n"))
+
+	opts.FunctionPath = "../../../test_data/node/square"
+	opts.Cmd = " "
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "--cmd must not be empty")
+	opts.Cmd = "[]"
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "--cmd must not be empty")
+	opts.Cmd = `["node", 1]`
+	as.Error(options.ValidateAndCleanInitOptions(&opts))
 }
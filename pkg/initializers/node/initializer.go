@@ -38,9 +38,20 @@ func Initialize(opts options.InitOptions) error {
 	workdir := filepath.Dir(functionfile)
 
 	generator := core.ArtifactsGenerator{
+		Language:           language,
 		GenerateFunction:   core.DefaultGenerateFunction,
 		GenerateDockerFile: generateNodeFunctionDockerFile,
 	}
 
 	return core.GenerateFunctionArtfacts(generator, workdir, opts)
 }
+
+// Generates the Dockerfile the function would be initialized with, without writing anything.
+func GenerateDockerFile(opts options.InitOptions) (string, error) {
+	functionfile, err := utils.ResolveFunctionFile(opts, language, extension)
+	if err != nil {
+		return "", err
+	}
+	utils.ResolveOptions(functionfile, language, &opts)
+	return generateNodeFunctionDockerFile(opts)
+}
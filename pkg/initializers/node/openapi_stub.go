@@ -0,0 +1,85 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package node
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/projectriff/riff-cli/pkg/openapi"
+)
+
+var openApiStubTemplate = `{{range .Types}}/**
+ * @typedef {Object} {{.Name}}
+{{- range .Properties}}
+ * @property { {{- jsType .Type -}} } {{if .Required}}{{.Name}}{{else}}[{{.Name}}]{{end}}
+{{- end}}
+ */
+
+{{end}}/**
+ * {{.Id}}: {{.Method}} {{.Path}}
+{{- if .Summary}}
+ * {{.Summary}}
+{{- end}}
+ *
+{{- if .RequestType}}
+ * @param { {{- jsType .RequestType -}} } input the request body
+{{- else}}
+ * @param {Object} input the parameters
+{{- range .Parameters}}
+ * @param { {{- jsType .Type -}} } {{if .Required}}input.{{.Name}}{{else}}[input.{{.Name}}]{{end}} the {{.In}} parameter
+{{- end}}
+{{- end}}
+{{- if .ResponseType}}
+ * @returns { {{- jsType .ResponseType -}} }
+{{- end}}
+ */
+module.exports = (input) => {
+    // TODO: implement {{.Id}}
+    return {};
+};
+`
+
+var jsTypes = map[string]string{
+	"integer": "number",
+	"object":  "Object",
+}
+
+// The JSDoc type of an OpenAPI type.
+func jsType(openApiType string) string {
+	if strings.HasSuffix(openApiType, "[]") {
+		return jsType(strings.TrimSuffix(openApiType, "[]")) + "[]"
+	}
+	if t, ok := jsTypes[openApiType]; ok {
+		return t
+	}
+	return openApiType
+}
+
+// Generates a function stub for an OpenAPI operation, documenting its types with JSDoc.
+func GenerateOpenApiStub(op openapi.Operation) (string, error) {
+	tmpl, err := template.New("openapi-node").Funcs(template.FuncMap{"jsType": jsType}).Parse(openApiStubTemplate)
+	if err != nil {
+		return "", err
+	}
+	var stub bytes.Buffer
+	if err = tmpl.Execute(&stub, op); err != nil {
+		return "", err
+	}
+	return stub.String(), nil
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package initializers
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/projectriff/riff-cli/pkg/initializers/core"
+	"github.com/projectriff/riff-cli/pkg/initializers/node"
+	"github.com/projectriff/riff-cli/pkg/initializers/python"
+	"github.com/projectriff/riff-cli/pkg/initializers/shell"
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/openapi"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+)
+
+// The languages a function can be scaffolded in from an OpenAPI operation, with the extension of their stub and
+// whether it is an executable script.
+var openApiStubs = map[string]struct {
+	extension  string
+	generate   func(openapi.Operation) (string, error)
+	executable bool
+}{
+	"node":   {"js", node.GenerateOpenApiStub, false},
+	"python": {"py", python.GenerateOpenApiStub, false},
+	"shell":  {"sh", shell.GenerateOpenApiStub, true},
+}
+
+/*
+ * Writes a stub of the function handling the --operation of the --from-openapi spec in the function directory,
+ * named after the function, keeping an existing function file unless --force is set.
+ */
+func ScaffoldFromOpenApi(language string, opts options.InitOptions) error {
+	stubs, ok := openApiStubs[language]
+	if !ok {
+		return errors.New(fmt.Sprintf("--from-openapi is not supported for %s functions, only for node, python and shell functions", language))
+	}
+	op, err := openapi.FindOperation(opts.FromOpenApi, opts.Operation)
+	if err != nil {
+		return err
+	}
+	stubFile := filepath.Join(options.FunctionDir(opts), fmt.Sprintf("%s.%s", opts.FunctionName, stubs.extension))
+	if osutils.FileExists(stubFile) && !opts.Force {
+		return options.Warning(opts.Strict, fmt.Sprintf("skipping existing file %s  - set --force to overwrite.", stubFile))
+	}
+	stub, err := stubs.generate(op)
+	if err != nil {
+		return err
+	}
+	if err = core.WriteScaffold(opts, stubFile, stub, stubs.executable); err != nil {
+		return err
+	}
+	ioutils.Infof("created %s for operation %s\n", stubFile, op.Id)
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package initializers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/initializers/utils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+const usersSpec = "../../test_data/openapi/users.yaml"
+
+func TestScaffoldNodeFunctionFromOpenApi(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-openapi")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	opts := options.InitOptions{FunctionPath: dir, FunctionName: "users", FromOpenApi: usersSpec, Operation: "getUser", RiffVersion: "0.0.6"}
+	as.NoError(ScaffoldFromOpenApi("node", opts))
+
+	stub, err := ioutil.ReadFile(filepath.Join(dir, "users.js"))
+	as.NoError(err)
+	as.Contains(string(stub), " * @typedef {Object} User\n * @property {string} [email]\n * @property {number} id\n")
+	as.Contains(string(stub), " * getUser: GET /users/{id}\n * Returns a user by id\n")
+	as.Contains(string(stub), " * @param {number} input.id the path parameter\n * @param {string} [input.fields] the query parameter\n")
+	as.Contains(string(stub), " * @returns {User}\n */\nmodule.exports = (input) => {\n    // TODO: implement getUser\n")
+
+	dockerfile, err := Node().GenerateDockerFile(opts)
+	as.NoError(err)
+	as.Contains(dockerfile, "ADD users.js ${FUNCTION_URI}")
+}
+
+func TestScaffoldPythonFunctionFromOpenApi(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-openapi")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	opts := options.InitOptions{FunctionPath: dir, FunctionName: "users", FromOpenApi: usersSpec, Operation: "createUser"}
+	as.NoError(ScaffoldFromOpenApi("python", opts))
+
+	stubFile := filepath.Join(dir, "users.py")
+	stub, err := ioutil.ReadFile(stubFile)
+	as.NoError(err)
+	as.Contains(string(stub), "# NewUser:\n#   name (str, required)\n#   tags (list of str)\n")
+	as.Contains(string(stub), "def createUser(input):\n")
+	as.Contains(string(stub), ":param input: the request body, a NewUser\n    :return: a User\n")
+	handler, ok := utils.HandlerFromSource(stubFile, "python")
+	as.True(ok)
+	as.Equal("createUser", handler)
+}
+
+func TestScaffoldShellFunctionFromOpenApi(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-openapi")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+
+	opts := options.InitOptions{FunctionPath: dir, FunctionName: "users", FromOpenApi: usersSpec, Operation: "getUser"}
+	as.NoError(ScaffoldFromOpenApi("shell", opts))
+
+	stubFile := filepath.Join(dir, "users.sh")
+	stub, err := ioutil.ReadFile(stubFile)
+	as.NoError(err)
+	as.Contains(string(stub), "#!/bin/sh\n#\n# getUser: GET /users/{id}\n")
+	as.Contains(string(stub), "# Reads the parameters from stdin:\n#   id (integer, path, required)\n")
+	info, err := os.Stat(stubFile)
+	as.NoError(err)
+	as.Equal(os.FileMode(0755), info.Mode().Perm(), "a scaffolded shell stub is executable")
+
+	opts.Force = true
+	opts.ExecutableMode = "0700"
+	as.NoError(ScaffoldFromOpenApi("shell", opts))
+	info, err = os.Stat(stubFile)
+	as.NoError(err)
+	as.Equal(os.FileMode(0700), info.Mode().Perm())
+
+	opts.Operation = "createUser"
+	as.NoError(ScaffoldFromOpenApi("node", opts))
+	info, err = os.Stat(filepath.Join(dir, "users.js"))
+	as.NoError(err)
+	as.Equal(os.FileMode(0644), info.Mode().Perm())
+}
+
+func TestScaffoldFromOpenApiKeepsExistingFunction(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-openapi")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	functionFile := filepath.Join(dir, "users.js")
+	as.NoError(ioutil.WriteFile(functionFile, []byte("module.exports = x => x;\n"), 0644))
+
+	opts := options.InitOptions{FunctionPath: dir, FunctionName: "users", FromOpenApi: usersSpec, Operation: "getUser"}
+	as.NoError(ScaffoldFromOpenApi("node", opts))
+	content, err := ioutil.ReadFile(functionFile)
+	as.NoError(err)
+	as.Equal("module.exports = x => x;\n", string(content))
+
+	opts.Strict = true
+	as.Error(ScaffoldFromOpenApi("node", opts))
+}
+
+func TestScaffoldFromOpenApiUnsupportedLanguage(t *testing.T) {
+	as := assert.New(t)
+
+	err := ScaffoldFromOpenApi("java", options.InitOptions{FromOpenApi: usersSpec, Operation: "getUser"})
+	as.EqualError(err, "--from-openapi is not supported for java functions, only for node, python and shell functions")
+}
+
+func TestFromOpenApiRequiresOperation(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{FunctionPath: "../../test_data/node/square", FromOpenApi: usersSpec}
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "--from-openapi requires the --operation to scaffold the function from")
+
+	opts = options.InitOptions{FunctionPath: "../../test_data/node/square", Operation: "getUser"}
+	as.EqualError(options.ValidateAndCleanInitOptions(&opts), "--operation is only supported with --from-openapi")
+}
@@ -17,38 +17,80 @@
 package python
 
 import (
+	"errors"
+	"fmt"
 	"github.com/projectriff/riff-cli/pkg/initializers/core"
 	"github.com/projectriff/riff-cli/pkg/options"
 	"path/filepath"
+	"strings"
 	"github.com/projectriff/riff-cli/pkg/osutils"
 )
 
 type PythonDockerFileTokens struct {
 	core.DockerFileTokens
 	RequirementsTextExists bool
+	Package                bool
+	PipIndexUrl            string
 }
 
 var pythonFunctionDockerfileTemplate = `
-FROM projectriff/python2-function-invoker:{{.RiffVersion}}
+FROM {{if .BaseImage}}{{.BaseImage}}{{else}}projectriff/python2-function-invoker:{{.RiffVersion}}{{.Variant}}{{end}}
 ARG FUNCTION_MODULE={{.ArtifactBase}}
-ARG FUNCTION_HANDLER={{.Handler}}
-ADD ./{{.ArtifactBase}} /
-{{- if .RequirementsTextExists }}
-ADD ./requirements.txt /
-RUN  pip install --upgrade pip && pip install -r /requirements.txt
-{{ end -}}
-ENV FUNCTION_URI file:///${FUNCTION_MODULE}?handler=${FUNCTION_HANDLER}
+ARG FUNCTION_HANDLER={{if .Package}}{{.ArtifactBase}}.{{end}}{{.Handler}}
+{{.AddInstruction}} ./{{.Artifact}} /{{.ArtifactBase}}
+{{- if and .RequirementsTextExists (not .NoInstall) }}
+{{.AddInstruction}} ./{{.Source "requirements.txt"}} /
+{{- end }}
+{{- if and (or .RequirementsTextExists .InstallCmd) (not .NoInstall) }}
+ARG CACHE_BUST
+{{- if .InstallCmd }}
+RUN  {{.InstallCmd}}
+{{- else if .PipIndexUrl }}
+ARG PIP_INDEX_CREDENTIALS
+RUN  pip install{{if .QuietDeps}} -q{{end}} --upgrade pip && pip install{{if .QuietDeps}} -q{{end}} --index-url "{{.PipIndexUrl}}" -r /requirements.txt
+{{- else }}
+RUN  pip install{{if .QuietDeps}} -q{{end}} --upgrade pip && pip install{{if .QuietDeps}} -q{{end}} -r /requirements.txt
+{{- end }}
+{{- end }}
+ENV FUNCTION_URI file:///${FUNCTION_MODULE}?{{.UriQuery "${FUNCTION_HANDLER}"}}
+{{- if .CloudEvents }}
+ENV FUNCTION_CONTENT_MODE structured
+{{- end }}
+{{- if .Port }}
+EXPOSE {{.Port}}
+{{- end }}
+{{- if .RunAsUser }}
+USER {{.RunAsUser}}
+{{- end }}
+{{- if .Entrypoint }}
+ENTRYPOINT {{.Entrypoint}}
+{{- end }}
+{{- if .Cmd }}
+CMD {{.Cmd}}
+{{- end }}
 `
 
+// What the build ARGs of the python FUNCTION_URI are.
+var pythonFunctionUriArgs = map[string]string{
+	"FUNCTION_MODULE":  "the function module, added to the root directory of the image",
+	"FUNCTION_HANDLER": "the function of the module, given with --handler",
+}
+
 func generatePythonFunctionDockerFile(opts options.InitOptions) (string, error) {
-	dockerFileTokens := PythonDockerFileTokens{}
-	dockerFileTokens.Artifact = opts.Artifact
-	dockerFileTokens.ArtifactBase = filepath.Base(opts.Artifact)
-	dockerFileTokens.RiffVersion = opts.RiffVersion
-	dockerFileTokens.Handler = opts.Handler
+	resolver, err := core.ResolveArtifact(opts.Artifact)
+	if err != nil {
+		return "", err
+	}
+	// The artifact may be a package directory, which cannot be fetched.
+	if resolver.Remote() {
+		return "", errors.New(fmt.Sprintf("remote artifact %s is not supported for python functions", opts.Artifact))
+	}
+	dockerFileTokens := PythonDockerFileTokens{DockerFileTokens: core.NewDockerFileTokens(opts, resolver)}
 	dockerFileTokens.RequirementsTextExists = requirementTextExists(opts.FunctionPath)
+	dockerFileTokens.Package = isPackage(opts.FunctionPath, opts.Artifact)
+	dockerFileTokens.PipIndexUrl = pipIndexUrl(opts.PipIndexUrl)
 
-	return core.GenerateFunctionDockerFileContents(pythonFunctionDockerfileTemplate, "docker-python", dockerFileTokens)
+	return core.GenerateFunctionDockerFileContents(pythonFunctionDockerfileTemplate, "docker-python", opts.TemplateDir, dockerFileTokens)
 }
 
 func requirementTextExists(functionPath string) bool {
@@ -56,4 +98,23 @@ func requirementTextExists(functionPath string) bool {
 		functionPath = filepath.Dir(functionPath)
 	}
 	return osutils.FileExists(filepath.Join(functionPath, "requirements.txt"))
-}
\ No newline at end of file
+}
+/*
+ * The index URL with the credentials of the PIP_INDEX_CREDENTIALS build arg, as user:password, if it is set.
+ * The shell running pip expands the build arg, so the credentials are never written to the Dockerfile.
+ */
+func pipIndexUrl(indexUrl string) string {
+	if indexUrl == "" {
+		return ""
+	}
+	scheme := indexUrl[:strings.Index(indexUrl, "://")+3]
+	return scheme + "${PIP_INDEX_CREDENTIALS:+$PIP_INDEX_CREDENTIALS@}" + strings.TrimPrefix(indexUrl, scheme)
+}
+
+// A package artifact is added as a whole directory and its handler is qualified by the package name.
+func isPackage(functionPath string, artifact string) bool {
+	if !osutils.IsDirectory(functionPath) {
+		functionPath = filepath.Dir(functionPath)
+	}
+	return osutils.IsDirectory(filepath.Join(functionPath, artifact))
+}
@@ -19,6 +19,7 @@ package python
 import (
 	"github.com/projectriff/riff-cli/pkg/options"
 	"fmt"
+	"strings"
 	"testing"
 	"github.com/stretchr/testify/assert"
 )
@@ -42,3 +43,170 @@ func TestPythonDockerfile(t *testing.T) {
 	as.NotContains(docker, "requirements.txt")
 	as.NotContains(docker, "pip")
 }
+
+func TestPythonDockerfileWithRequirements(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "demo.py",
+		RiffVersion:  "0.0.3",
+		FunctionPath: "../../../test_data/python/demo_with_deps",
+		Handler:      "process",
+	}
+
+	docker, err := generatePythonFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ADD ./requirements.txt /")
+	as.Contains(docker, "ARG CACHE_BUST\nRUN  pip install")
+}
+
+func TestPythonDockerfileForPackage(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "greeter",
+		RiffVersion:  "0.0.3",
+		FunctionPath: "../../../test_data/python/package",
+		Handler:      "greet",
+	}
+
+	docker, err := generatePythonFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ARG FUNCTION_MODULE=greeter")
+	as.Contains(docker, "ARG FUNCTION_HANDLER=greeter.greet")
+	as.Contains(docker, "ADD ./greeter /greeter")
+	as.Contains(docker, "ENV FUNCTION_URI file:///${FUNCTION_MODULE}?handler=${FUNCTION_HANDLER}")
+}
+
+func TestPythonDockerfileWithContext(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "demo.py",
+		RiffVersion:  "0.0.3",
+		FunctionPath: "../../../test_data/python/demo_with_deps",
+		Context:      "../../../test_data",
+		Handler:      "process",
+	}
+
+	docker, err := generatePythonFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ADD ./python/demo_with_deps/demo.py /demo.py")
+	as.Contains(docker, "ADD ./python/demo_with_deps/requirements.txt /")
+}
+
+func TestPythonDockerfileRunsAsNonRootAfterInstallingRequirements(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "demo.py",
+		RiffVersion:  "0.0.3",
+		FunctionPath: "../../../test_data/python/demo_with_deps",
+		Handler:      "process",
+		NonRoot:      true,
+	}
+
+	docker, err := generatePythonFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "\nUSER 65534\n")
+	as.True(strings.Index(docker, "USER 65534") > strings.LastIndex(docker, "RUN "), "USER must follow the RUN steps")
+
+	opts.RunAsUser = "1000"
+	docker, err = generatePythonFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "\nUSER 1000\n")
+}
+
+func TestPythonDockerfileWithPipIndexUrl(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "demo.py",
+		RiffVersion:  "0.0.3",
+		FunctionPath: "../../../test_data/python/demo_with_deps",
+		Handler:      "process",
+		PipIndexUrl:  "https://pypi.example.com/simple",
+	}
+
+	docker, err := generatePythonFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ARG PIP_INDEX_CREDENTIALS\n")
+	as.Contains(docker, `pip install --index-url "https://${PIP_INDEX_CREDENTIALS:+$PIP_INDEX_CREDENTIALS@}pypi.example.com/simple" -r /requirements.txt`)
+}
+
+func TestPythonDockerfileWithInstallCmd(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "demo.py",
+		RiffVersion:  "0.0.3",
+		FunctionPath: "../../../test_data/python/demo_with_deps",
+		Handler:      "process",
+		InstallCmd:   "pip-proxy install -r /requirements.txt",
+	}
+
+	docker, err := generatePythonFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ADD ./requirements.txt /\nARG CACHE_BUST\nRUN  pip-proxy install -r /requirements.txt\nENV")
+	as.NotContains(docker, "pip install")
+}
+
+func TestPythonDockerfileWithNoInstall(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "demo.py",
+		RiffVersion:  "0.0.3",
+		FunctionPath: "../../../test_data/python/demo_with_deps",
+		Handler:      "process",
+		NoInstall:    true,
+	}
+
+	docker, err := generatePythonFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ADD ./demo.py /demo.py\nENV FUNCTION_URI")
+	as.NotContains(docker, "RUN")
+	as.NotContains(docker, "requirements.txt")
+}
+
+func TestPythonDockerfileWithCopy(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "demo.py",
+		RiffVersion:  "0.0.3",
+		FunctionPath: "../../../test_data/python/demo_with_deps",
+		Handler:      "process",
+	}
+
+	docker, err := generatePythonFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "\nADD ./demo.py /demo.py\nADD ./requirements.txt /\n")
+	as.NotContains(docker, "COPY")
+
+	opts.UseCopy = true
+	docker, err = generatePythonFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "\nCOPY ./demo.py /demo.py\nCOPY ./requirements.txt /\n")
+	as.NotContains(docker, "ADD")
+}
+
+func TestPythonDockerfileWithQuietDeps(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:     "demo.py",
+		RiffVersion:  "0.0.3",
+		FunctionPath: "../../../test_data/python/demo_with_deps",
+		Handler:      "process",
+	}
+
+	docker, err := generatePythonFunctionDockerFile(opts)
+	as.NoError(err)
+	as.NotContains(docker, " -q ")
+
+	opts.QuietDeps = true
+	docker, err = generatePythonFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "RUN  pip install -q --upgrade pip && pip install -q -r /requirements.txt")
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package python
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/projectriff/riff-cli/pkg/openapi"
+)
+
+var openApiStubTemplate = `
+# riff:handler={{handler .}}
+#
+# {{.Id}}: {{.Method}} {{.Path}}
+{{- if .Summary}}
+# {{.Summary}}
+{{- end}}
+{{- range .Types}}
+#
+# {{.Name}}:
+{{- range .Properties}}
+#   {{.Name}} ({{pyType .Type}}{{if .Required}}, required{{end}})
+{{- end}}
+{{- end}}
+
+
+def {{handler .}}(input):
+    """Handles the {{.Id}} operation.
+{{if .RequestType}}
+    :param input: the request body, a {{pyType .RequestType}}
+{{- else}}
+    :param input: the parameters
+{{- range .Parameters}}
+        {{.Name}} ({{pyType .Type}}, {{.In}}{{if .Required}}, required{{end}})
+{{- end}}
+{{- end}}
+{{- if .ResponseType}}
+    :return: a {{pyType .ResponseType}}
+{{- end}}
+    """
+    # TODO: implement {{.Id}}
+    return {}
+`
+
+var pythonTypes = map[string]string{
+	"string":  "str",
+	"integer": "int",
+	"number":  "float",
+	"boolean": "bool",
+	"object":  "dict",
+}
+
+var nonIdentifierRegexp = regexp.MustCompile(`\W`)
+
+// The python type of an OpenAPI type, a list for arrays.
+func pyType(openApiType string) string {
+	if strings.HasSuffix(openApiType, "[]") {
+		return "list of " + pyType(strings.TrimSuffix(openApiType, "[]"))
+	}
+	if t, ok := pythonTypes[openApiType]; ok {
+		return t
+	}
+	return openApiType
+}
+
+// The name of the function handling an OpenAPI operation, its operationId as a python identifier.
+func openApiHandler(op openapi.Operation) string {
+	handler := nonIdentifierRegexp.ReplaceAllString(op.Id, "_")
+	if handler == "" || handler[0] >= '0' && handler[0] <= '9' {
+		handler = "_" + handler
+	}
+	return handler
+}
+
+// Generates a function stub for an OpenAPI operation, annotated with its handler and documenting its types in comments.
+func GenerateOpenApiStub(op openapi.Operation) (string, error) {
+	funcs := template.FuncMap{"pyType": pyType, "handler": openApiHandler}
+	tmpl, err := template.New("openapi-python").Funcs(funcs).Parse(openApiStubTemplate)
+	if err != nil {
+		return "", err
+	}
+	var stub bytes.Buffer
+	if err = tmpl.Execute(&stub, op); err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(stub.String(), "\n"), nil
+}
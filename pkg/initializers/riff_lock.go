@@ -0,0 +1,103 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *  
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *  
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package initializers
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/projectriff/riff-cli/pkg/ioutils"
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"gopkg.in/yaml.v2"
+)
+
+const RiffLockFile = "riff.lock"
+
+/*
+ * Pins the invoker version of each language for reproducible builds of the functions below it, e.g.
+ *
+ *   invokers:
+ *     node: 0.0.6
+ *     java: 0.0.5
+ */
+type RiffLock struct {
+	Invokers map[string]string `yaml:"invokers"`
+}
+
+/*
+ * Finds the riff.lock of the function, looking up from the function directory to the root of its git
+ * repository, or of the file system.
+ */
+func findRiffLock(opts options.InitOptions) (string, bool) {
+	dir, err := filepath.Abs(options.FunctionDir(opts))
+	if err != nil {
+		return "", false
+	}
+	for {
+		lockFile := filepath.Join(dir, RiffLockFile)
+		if osutils.FileExists(lockFile) {
+			return lockFile, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir || osutils.IsDirectory(filepath.Join(dir, ".git")) {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func readRiffLock(lockFile string) (RiffLock, error) {
+	lock := RiffLock{}
+	content, err := ioutil.ReadFile(lockFile)
+	if err != nil {
+		return lock, err
+	}
+	err = yaml.Unmarshal(content, &lock)
+	if err != nil {
+		return lock, errors.New(fmt.Sprintf("invalid %s: %v", lockFile, err))
+	}
+	return lock, nil
+}
+
+/*
+ * Uses the invoker version pinned for the language in riff.lock, unless --riff-version is given. Without a
+ * lockfile, or a version for the language, the default riff version is kept.
+ */
+func LockRiffVersion(opts *options.InitOptions, language string) error {
+	if opts.RiffVersionPinned {
+		return nil
+	}
+	lockFile, ok := findRiffLock(*opts)
+	if !ok {
+		return nil
+	}
+	lock, err := readRiffLock(lockFile)
+	if err != nil {
+		return err
+	}
+	if version, ok := lock.Invokers[language]; ok {
+		if version == "" {
+			return errors.New(fmt.Sprintf("invalid %s: empty %s invoker version", lockFile, language))
+		}
+		ioutils.Debugf("Using %s invoker version %s from %s\n", language, version, lockFile)
+		opts.RiffVersion = version
+	}
+	return nil
+}
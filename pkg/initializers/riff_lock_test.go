@@ -0,0 +1,71 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package initializers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+// A repository with a riff.lock at its root and a node function below it.
+func lockedRepository(as *assert.Assertions) (string, string) {
+	dir, err := ioutil.TempDir("", "riff-lock")
+	as.NoError(err)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, RiffLockFile), []byte("invokers:\n  node: 0.0.6\n  java: 0.0.5\n"), 0644))
+	functionDir := filepath.Join(dir, "functions", "square")
+	as.NoError(os.MkdirAll(functionDir, 0755))
+	as.NoError(ioutil.WriteFile(filepath.Join(functionDir, "square.js"), []byte("module.exports = x => x ** 2;\n"), 0644))
+	return dir, functionDir
+}
+
+func TestRiffLockVersionUsedForDetectedLanguage(t *testing.T) {
+	as := assert.New(t)
+	dir, functionDir := lockedRepository(as)
+	defer os.RemoveAll(dir)
+
+	opts := options.InitOptions{FunctionPath: functionDir, FunctionName: "square", RiffVersion: "0.0.4"}
+	dockerfile, err := GenerateDockerFile(opts)
+	as.NoError(err)
+	as.Contains(dockerfile, "FROM projectriff/node-function-invoker:0.0.6\n")
+}
+
+func TestRiffVersionFlagOverridesRiffLock(t *testing.T) {
+	as := assert.New(t)
+	dir, functionDir := lockedRepository(as)
+	defer os.RemoveAll(dir)
+
+	opts := options.InitOptions{FunctionPath: functionDir, FunctionName: "square", RiffVersion: "0.0.4", RiffVersionPinned: true}
+	dockerfile, err := GenerateDockerFile(opts)
+	as.NoError(err)
+	as.Contains(dockerfile, "FROM projectriff/node-function-invoker:0.0.4\n")
+}
+
+func TestRiffLockWithoutVersionForLanguage(t *testing.T) {
+	as := assert.New(t)
+	dir, functionDir := lockedRepository(as)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, RiffLockFile), []byte("invokers:\n  java: 0.0.5\n"), 0644))
+
+	opts := options.InitOptions{FunctionPath: functionDir, FunctionName: "square", RiffVersion: "0.0.4"}
+	as.NoError(LockRiffVersion(&opts, "node"))
+	as.Equal("0.0.4", opts.RiffVersion)
+}
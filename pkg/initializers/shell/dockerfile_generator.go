@@ -18,22 +18,68 @@ package shell
 
 import (
 	"github.com/projectriff/riff-cli/pkg/options"
-	"path/filepath"
 	"github.com/projectriff/riff-cli/pkg/initializers/core"
 )
 
+type ShellDockerFileTokens struct {
+	core.DockerFileTokens
+	Interpreter string
+}
+
+// The FUNCTION_URI query string, naming first the interpreter the invoker runs the script with, if given.
+func (tokens ShellDockerFileTokens) UriQuery(handler string) string {
+	params := tokens.UriParams
+	if tokens.Interpreter != "" {
+		params = append([]string{"interpreter=" + tokens.Interpreter}, params...)
+	}
+	return core.FunctionUriQuery(handler, params)
+}
+
 var shellFunctionDockerfileTemplate = `
-FROM projectriff/shell-function-invoker:{{.RiffVersion}}
-ARG FUNCTION_URI="/{{.ArtifactBase}}"
-ADD {{.Artifact}} /
+FROM {{if .BaseImage}}{{.BaseImage}}{{else}}projectriff/shell-function-invoker:{{.RiffVersion}}{{.Variant}}{{end}}
+ARG FUNCTION_URI="/{{.ArtifactBase}}{{with .UriQuery ""}}?{{.}}{{end}}"
+{{- if .Inline }}
+RUN printf '%s\n' \
+    {{.Inline}} \
+    > /{{.ArtifactBase}} && chmod 755 /{{.ArtifactBase}}
+{{- else }}
+{{.AddInstruction}} {{.Artifact}} /{{.ArtifactBase}}
+{{- end }}
 ENV FUNCTION_URI $FUNCTION_URI
+{{- if .CloudEvents }}
+ENV FUNCTION_CONTENT_MODE structured
+{{- end }}
+{{- if .Port }}
+EXPOSE {{.Port}}
+{{- end }}
+{{- if .RunAsUser }}
+USER {{.RunAsUser}}
+{{- end }}
+{{- if .Entrypoint }}
+ENTRYPOINT {{.Entrypoint}}
+{{- end }}
+{{- if .Cmd }}
+CMD {{.Cmd}}
+{{- end }}
 `
 
+// What the build ARG of the shell FUNCTION_URI is.
+var shellFunctionUriArgs = map[string]string{
+	"FUNCTION_URI": "the path of the script in the image, and the interpreter given with --shell, if any",
+}
+
 func generateShellFunctionDockerFile(opts options.InitOptions) (string, error) {
-	dockerFileTokens := core.DockerFileTokens{
-		Artifact:     opts.Artifact,
-		ArtifactBase: filepath.Base(opts.Artifact),
-		RiffVersion:  opts.RiffVersion,
+	resolver, err := core.ResolveArtifact(opts.Artifact)
+	if err != nil {
+		return "", err
+	}
+	dockerFileTokens := ShellDockerFileTokens{DockerFileTokens: core.NewDockerFileTokens(opts, resolver)}
+	dockerFileTokens.Interpreter = opts.Shell
+	if opts.Inline {
+		dockerFileTokens.Inline, err = core.InlineSource(opts)
+		if err != nil {
+			return "", err
+		}
 	}
-	return core.GenerateFunctionDockerFileContents(shellFunctionDockerfileTemplate, "docker-shell", dockerFileTokens)
+	return core.GenerateFunctionDockerFileContents(shellFunctionDockerfileTemplate, "docker-shell", opts.TemplateDir, dockerFileTokens)
 }
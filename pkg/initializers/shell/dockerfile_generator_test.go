@@ -17,6 +17,9 @@
 package shell
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"github.com/projectriff/riff-cli/pkg/options"
 	"fmt"
 	"testing"
@@ -38,3 +41,68 @@ func TestShellDockerfile(t *testing.T) {
 	as.Contains(docker, fmt.Sprintf("ARG FUNCTION_URI=\"/%s\"", opts.Artifact))
 	as.Contains(docker, fmt.Sprintf("ADD %s /", opts.Artifact))
 }
+
+func TestShellDockerfileWithUriParams(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "echo.sh",
+		RiffVersion: "0.0.2",
+		UriParams:   []string{"timeout=5s"},
+	}
+
+	docker, err := generateShellFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ARG FUNCTION_URI=\"/echo.sh?timeout=5s\"")
+}
+
+func TestShellDockerfileWithBaseImage(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "echo.sh",
+		RiffVersion: "0.0.2",
+		BaseImage:   "acme/shell-invoker:1.0@sha256:4a1c4b21597c1b4415bdbecb28a3296c6b5e23ca4f9feeb599860a1dac6a0108",
+	}
+
+	docker, err := generateShellFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "FROM acme/shell-invoker:1.0@sha256:4a1c4b21597c1b4415bdbecb28a3296c6b5e23ca4f9feeb599860a1dac6a0108\n")
+}
+
+func TestShellDockerfileInline(t *testing.T) {
+	as := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "riff-inline")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "echo.sh"), []byte("#!/bin/sh\n\necho 'hello' $1\n"), 0755))
+
+	opts := options.InitOptions{
+		FunctionPath: dir,
+		Artifact:     "echo.sh",
+		ArtifactBase: "echo.sh",
+		RiffVersion:  "0.0.2",
+		Inline:       true,
+	}
+
+	docker, err := generateShellFunctionDockerFile(opts)
+	as.NoError(err)
+	as.NotContains(docker, "ADD ")
+	as.Contains(docker, "RUN printf '%s\\n' \\\n    '#!/bin/sh' \\\n    '' \\\n    'echo '\"'\"'hello'\"'\"' $1' \\\n    > /echo.sh && chmod 755 /echo.sh\n")
+}
+
+func TestShellDockerfileWithBash(t *testing.T) {
+	as := assert.New(t)
+
+	opts := options.InitOptions{
+		Artifact:    "echo.sh",
+		RiffVersion: "0.0.2",
+		UriParams:   []string{"timeout=5s"},
+		Shell:       "bash",
+	}
+
+	docker, err := generateShellFunctionDockerFile(opts)
+	as.NoError(err)
+	as.Contains(docker, "ARG FUNCTION_URI=\"/echo.sh?interpreter=bash&timeout=5s\"")
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *  
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *  
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package shell
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/projectriff/riff-cli/pkg/openapi"
+)
+
+var openApiStubTemplate = `#!/bin/sh
+#
+# {{.Id}}: {{.Method}} {{.Path}}
+{{- if .Summary}}
+# {{.Summary}}
+{{- end}}
+#
+{{- if .RequestType}}
+# Reads the request body, a {{.RequestType}}, from stdin
+{{- else}}
+# Reads the parameters from stdin:
+{{- range .Parameters}}
+#   {{.Name}} ({{.Type}}, {{.In}}{{if .Required}}, required{{end}})
+{{- end}}
+{{- end}}
+{{- if .ResponseType}}
+# and writes the response, a {{.ResponseType}}, to stdout.
+{{- else}}
+# and writes the response to stdout.
+{{- end}}
+
+# TODO: implement {{.Id}}
+cat
+`
+
+// Generates a script stub for an OpenAPI operation, documenting its input and output in comments.
+func GenerateOpenApiStub(op openapi.Operation) (string, error) {
+	tmpl, err := template.New("openapi-shell").Parse(openApiStubTemplate)
+	if err != nil {
+		return "", err
+	}
+	var stub bytes.Buffer
+	if err = tmpl.Execute(&stub, op); err != nil {
+		return "", err
+	}
+	return stub.String(), nil
+}
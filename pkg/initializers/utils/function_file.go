@@ -24,13 +24,19 @@ import (
 	"errors"
 )
 
-var supportedExtensions = []string{"js", "java", "py", "sh"}
+var supportedExtensions = []string{"js", "java", "py", "sh", "ts"}
 
 var languageForFileExtensions = map[string]string{
 	"sh":   "shell",
 	"java": "java",
 	"js":   "node",
 	"py":   "python",
+	"ts":   "deno",
+}
+
+// Deno also runs JavaScript entrypoints, and a java function is usually given as its jar.
+func acceptsExtension(language string, ext string) bool {
+	return languageForFileExtensions[ext] == language || language == "deno" && ext == "js" || language == "java" && ext == "jar"
 }
 
 //Assumes given file paths have been sanity checked and are valid
@@ -57,6 +63,12 @@ func ResolveFunctionFile(opts options.InitOptions, language string, ext string)
 				}
 				resolvedFunctionPath = functionFile
 			}
+		} else if options.IsRemoteArtifact(opts.Artifact) {
+			// A remote artifact is only fetched when building the image, it stands for a file of the directory.
+			return filepath.Join(absFilePath, options.ArtifactName(opts.Artifact)), nil
+		} else if options.BuiltInImage(opts) {
+			// The jar is built by the build stage of the Dockerfile, a clean checkout does not have it.
+			return filepath.Join(absFilePath, opts.Artifact), nil
 		} else {
 			resolvedFunctionPath = filepath.Join(absFilePath, opts.Artifact)
 		}
@@ -67,7 +79,14 @@ func ResolveFunctionFile(opts options.InitOptions, language string, ext string)
 		return "", errors.New(fmt.Sprintf("function path %s does not exist", resolvedFunctionPath))
 	}
 
-	if opts.Artifact != "" && languageForFileExtensions[filepath.Ext(resolvedFunctionPath)[1:]] != language {
+	if osutils.IsDirectory(resolvedFunctionPath) {
+		if language != "python" {
+			return "", errors.New(fmt.Sprintf("artifact %s is a directory, which is only supported for python packages", opts.Artifact))
+		}
+		return resolvedFunctionPath, nil
+	}
+
+	if opts.Artifact != "" && !acceptsExtension(language, filepath.Ext(resolvedFunctionPath)[1:]) {
 		return "", errors.New(fmt.Sprintf("language %s conflicts with artifact file extension %s", language, opts.Artifact))
 	}
 
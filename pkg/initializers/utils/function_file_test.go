@@ -21,6 +21,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/projectriff/riff-cli/pkg/osutils"
 	"path/filepath"
+	"io/ioutil"
 	"os"
 	"fmt"
 	"github.com/projectriff/riff-cli/pkg/options"
@@ -127,3 +128,20 @@ func TestFunctionResourceWithNoExtensionGivenNotUnique(t *testing.T) {
 	as.Error(err)
 	as.Contains(err.Error(),"function file is not unique")
 }
+
+func TestArtifactCaseIsCorrected(t *testing.T) {
+	as := assert.New(t)
+	dir, err := ioutil.TempDir("", "riff-artifact-case")
+	as.NoError(err)
+	defer os.RemoveAll(dir)
+	as.NoError(os.Mkdir(filepath.Join(dir, "lib"), 0755))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, "lib", "square.js"), []byte("module.exports = x => x ** 2\n"), 0644))
+
+	opts := options.InitOptions{FunctionPath: dir, Artifact: "Lib/Square.JS"}
+	as.NoError(options.ValidateAndCleanInitOptions(&opts))
+	as.Equal(filepath.Join("lib", "square.js"), opts.Artifact)
+
+	opts = options.InitOptions{FunctionPath: dir, Artifact: "Lib/Square.JS", Strict: true}
+	err = options.ValidateAndCleanInitOptions(&opts)
+	as.EqualError(err, fmt.Sprintf("artifact %s does not match the case of %s on disk, using %s", filepath.Join("Lib", "Square.JS"), filepath.Join("lib", "square.js"), filepath.Join("lib", "square.js")))
+}
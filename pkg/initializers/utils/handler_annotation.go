@@ -0,0 +1,48 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package utils
+
+import (
+	"io/ioutil"
+	"regexp"
+)
+
+/*
+ * The handler annotations of the languages, a line comment such as '// riff:handler=process' in node sources
+ * or '# riff:handler=process' in python ones.
+ */
+var handlerAnnotationRegexps = map[string]*regexp.Regexp{
+	"node":   regexp.MustCompile(`(?m)^\s*//\s*riff:handler=([\w.$]+)\s*$`),
+	"python": regexp.MustCompile(`(?m)^\s*#\s*riff:handler=([\w.]+)\s*$`),
+}
+
+// Reads the handler from its annotation in the function source, returning false when there is none.
+func HandlerFromSource(functionFile string, language string) (string, bool) {
+	annotation, ok := handlerAnnotationRegexps[language]
+	if !ok {
+		return "", false
+	}
+	content, err := ioutil.ReadFile(functionFile)
+	if err != nil {
+		return "", false
+	}
+	match := annotation.FindSubmatch(content)
+	if match == nil {
+		return "", false
+	}
+	return string(match[1]), true
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func sourceFile(as *assert.Assertions, name string, content string) string {
+	dir, err := ioutil.TempDir("", "riff-handler")
+	as.NoError(err)
+	file := filepath.Join(dir, name)
+	as.NoError(ioutil.WriteFile(file, []byte(content), 0644))
+	return file
+}
+
+func TestHandlerFromNodeSource(t *testing.T) {
+	as := assert.New(t)
+	file := sourceFile(as, "square.js", "// riff:handler=process\nexports.process = x => x ** 2\n")
+	defer os.RemoveAll(filepath.Dir(file))
+
+	handler, ok := HandlerFromSource(file, "node")
+	as.True(ok)
+	as.Equal("process", handler)
+}
+
+func TestHandlerFromPythonSource(t *testing.T) {
+	as := assert.New(t)
+	file := sourceFile(as, "square.py", "import math\n\n  # riff:handler=square  \ndef square(x):\n    return x ** 2\n")
+	defer os.RemoveAll(filepath.Dir(file))
+
+	handler, ok := HandlerFromSource(file, "python")
+	as.True(ok)
+	as.Equal("square", handler)
+
+	// A python comment is not an annotation for node sources.
+	_, ok = HandlerFromSource(file, "node")
+	as.False(ok)
+}
+
+func TestSourceWithoutHandlerAnnotation(t *testing.T) {
+	as := assert.New(t)
+	file := sourceFile(as, "square.py", "def square(x):\n    return x ** 2  # riff:handler=square is only read from its own line\n")
+	defer os.RemoveAll(filepath.Dir(file))
+
+	_, ok := HandlerFromSource(file, "python")
+	as.False(ok)
+}
+
+func TestResolveOptionsReadsHandlerAnnotation(t *testing.T) {
+	as := assert.New(t)
+	file := sourceFile(as, "square.py", "# riff:handler=square\ndef square(x):\n    return x ** 2\n")
+	defer os.RemoveAll(filepath.Dir(file))
+
+	opts := options.InitOptions{FunctionPath: filepath.Dir(file)}
+	ResolveOptions(file, "python", &opts)
+	as.Equal("square", opts.Handler)
+
+	opts = options.InitOptions{FunctionPath: filepath.Dir(file), Handler: "cube"}
+	ResolveOptions(file, "python", &opts)
+	as.Equal("cube", opts.Handler)
+}
@@ -21,11 +21,23 @@ import (
 	"path/filepath"
 	"github.com/projectriff/riff-cli/pkg/functions"
 	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
 )
 
 func ResolveOptions(functionArtifact string, language string, opts *options.InitOptions) {
 
-	opts.FunctionName, _ = functions.FunctionNameFromPath(opts.FunctionPath)
+	// A name that is not given, or is just the directory default, is taken from the language's manifest.
+	pathName, _ := functions.FunctionNameFromPath(opts.FunctionPath)
+	if opts.FunctionName == "" || opts.FunctionName == pathName {
+		opts.FunctionName = pathName
+		manifestDir := opts.FunctionPath
+		if !osutils.IsDirectory(manifestDir) {
+			manifestDir = filepath.Dir(manifestDir)
+		}
+		if name, ok := functions.FunctionNameFromManifest(manifestDir, language); ok {
+			opts.FunctionName = name
+		}
+	}
 
 	if opts.Input == "" {
 		opts.Input = opts.FunctionName
@@ -35,11 +47,17 @@ func ResolveOptions(functionArtifact string, language string, opts *options.Init
 		opts.Artifact = filepath.Base(functionArtifact)
 	}
 
+	// The source may name its handler, so that it need not be given on each init.
+	if opts.Handler == "" {
+		opts.Handler, _ = HandlerFromSource(functionArtifact, language)
+	}
+
 	protocolForLanguage := map[string]string{
 		"shell": "stdio",
 		"java":  "http",
 		"js":    "http",
 		"node":  "http",
+		"deno":  "http",
 		"py":    "stdio",
 	}
 
@@ -0,0 +1,42 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/projectriff/riff-cli/pkg/options"
+	"github.com/projectriff/riff-cli/pkg/osutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveOptionsFallsBackToDirectoryName(t *testing.T) {
+	as := assert.New(t)
+	opts := options.InitOptions{FunctionPath: osutils.Path("../../../test_data/python/demo")}
+
+	ResolveOptions("demo.py", "python", &opts)
+	as.Equal("demo", opts.FunctionName)
+	as.Equal("demo", opts.Input)
+}
+
+func TestResolveOptionsKeepsGivenName(t *testing.T) {
+	as := assert.New(t)
+	opts := options.InitOptions{FunctionPath: osutils.Path("../../../test_data/python/demo"), FunctionName: "greeter"}
+
+	ResolveOptions("demo.py", "python", &opts)
+	as.Equal("greeter", opts.FunctionName)
+}
@@ -4,9 +4,9 @@
  *   Licensed under the Apache License, Version 2.0 (the "License");
  *   you may not use this file except in compliance with the License.
  *   You may obtain a copy of the License at
- *  
+ *
  *        http://www.apache.org/licenses/LICENSE-2.0
- *  
+ *
  *   Unless required by applicable law or agreed to in writing, software
  *   distributed under the License is distributed on an "AS IS" BASIS,
  *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
@@ -17,14 +17,117 @@
 package ioutils
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
+)
+
+type Level int
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
 )
 
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+var (
+	// Debug and info messages go to Stdout, warnings and errors to Stderr.
+	Stdout io.Writer = os.Stdout
+	Stderr io.Writer = os.Stderr
+
+	logLevel   = InfoLevel
+	jsonFormat = false
+)
+
+func ParseLevel(name string) (Level, error) {
+	for i, n := range levelNames {
+		if strings.ToLower(name) == n {
+			return Level(i), nil
+		}
+	}
+	return InfoLevel, errors.New(fmt.Sprintf("log level %s is unsupported, use one of %s", name, strings.Join(levelNames, ", ")))
+}
+
+func SetLogLevel(level Level) {
+	logLevel = level
+}
+
+func SetLogFormat(format string) error {
+	switch strings.ToLower(format) {
+	case "", "text":
+		jsonFormat = false
+	case "json":
+		jsonFormat = true
+	default:
+		return errors.New(fmt.Sprintf("log format %s is unsupported, use one of text, json", format))
+	}
+	return nil
+}
+
+func Debugf(format string, a ...interface{}) {
+	logf(DebugLevel, Stdout, format, a...)
+}
+
+func Debug(msg interface{}) {
+	Debugf("%s\n", msg)
+}
+
+func Infof(format string, a ...interface{}) {
+	logf(InfoLevel, Stdout, format, a...)
+}
+
+func Info(msg interface{}) {
+	Infof("%s\n", msg)
+}
+
+func Warnf(format string, a ...interface{}) {
+	logf(WarnLevel, Stderr, format, a...)
+}
+
+func Warn(msg interface{}) {
+	Warnf("%s\n", msg)
+}
+
 func Errorf(format string, a ...interface{}) {
-	fmt.Fprintf(os.Stderr, format, a...)
+	logf(ErrorLevel, Stderr, format, a...)
 }
 
 func Error(msg interface{}) {
 	Errorf("%s\n", msg)
 }
+
+/*
+ * Text output is the message as given, so existing formats keep their own line endings.
+ * JSON output is one object per line.
+ */
+func logf(level Level, w io.Writer, format string, a ...interface{}) {
+	if level < logLevel {
+		return
+	}
+	msg := fmt.Sprintf(format, a...)
+	if !jsonFormat {
+		fmt.Fprint(w, msg)
+		return
+	}
+	line, _ := json.Marshal(struct {
+		Time  string `json:"time"`
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}{
+		Time:  time.Now().Format(time.RFC3339),
+		Level: level.String(),
+		Msg:   strings.TrimRight(msg, "\n"),
+	})
+	fmt.Fprintln(w, string(line))
+}
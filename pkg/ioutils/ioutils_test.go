@@ -0,0 +1,100 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package ioutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func captureLogs(level Level, format string) (*bytes.Buffer, func()) {
+	var buffer bytes.Buffer
+	Stdout = &buffer
+	Stderr = &buffer
+	SetLogLevel(level)
+	SetLogFormat(format)
+	return &buffer, func() {
+		Stdout = os.Stdout
+		Stderr = os.Stderr
+		SetLogLevel(InfoLevel)
+		SetLogFormat("text")
+	}
+}
+
+func TestDebugSuppressedAtInfoLevel(t *testing.T) {
+	as := assert.New(t)
+	out, reset := captureLogs(InfoLevel, "text")
+	defer reset()
+
+	Debug("hidden")
+	Info("shown")
+
+	as.Equal("shown\n", out.String())
+}
+
+func TestDebugShownAtDebugLevel(t *testing.T) {
+	as := assert.New(t)
+	out, reset := captureLogs(DebugLevel, "text")
+	defer reset()
+
+	Debugf("resolved %s\n", "square.js")
+
+	as.Equal("resolved square.js\n", out.String())
+}
+
+func TestErrorsShownAtWarnLevel(t *testing.T) {
+	as := assert.New(t)
+	out, reset := captureLogs(WarnLevel, "text")
+	defer reset()
+
+	Info("hidden")
+	Warn("careful")
+	Errorf("failed %d times\n", 2)
+
+	as.Equal("careful\nfailed 2 times\n", out.String())
+}
+
+func TestJsonFormat(t *testing.T) {
+	as := assert.New(t)
+	out, reset := captureLogs(InfoLevel, "json")
+	defer reset()
+
+	Warnf("skipping %s\n", "Dockerfile")
+
+	entry := map[string]string{}
+	as.NoError(json.Unmarshal(out.Bytes(), &entry))
+	as.Equal("warn", entry["level"])
+	as.Equal("skipping Dockerfile", entry["msg"])
+	as.NotEmpty(entry["time"])
+}
+
+func TestParseLevel(t *testing.T) {
+	as := assert.New(t)
+	level, err := ParseLevel("DEBUG")
+	as.NoError(err)
+	as.Equal(DebugLevel, level)
+
+	_, err = ParseLevel("verbose")
+	as.Error(err)
+	as.Contains(err.Error(), "unsupported")
+
+	as.Error(SetLogFormat("xml"))
+}
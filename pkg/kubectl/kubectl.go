@@ -18,6 +18,9 @@ package kubectl
 
 import (
 	"github.com/projectriff/riff-cli/pkg/osutils"
+	"io"
+	"os/exec"
+	"syscall"
 	"time"
 )
 
@@ -29,3 +32,23 @@ func ExecForString(cmdArgs []string) (string, error) {
 func ExecForBytes(cmdArgs []string) ([]byte, error) {
 	return osutils.Exec("kubectl", cmdArgs, 20*time.Second)
 }
+
+/*
+ * Runs kubectl, streaming its output, and returns its exit code. An error is only returned when kubectl
+ * could not be run at all.
+ */
+func Stream(cmdArgs []string, stdout io.Writer, stderr io.Writer) (int, error) {
+	cmd := exec.Command("kubectl", cmdArgs...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus(), nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,249 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The parts of an OpenAPI 3 document a function is scaffolded from, in YAML or JSON.
+type spec struct {
+	Paths      map[string]pathItem `yaml:"paths"`
+	Components struct {
+		Schemas map[string]*schema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type pathItem struct {
+	Get        *operation  `yaml:"get"`
+	Put        *operation  `yaml:"put"`
+	Post       *operation  `yaml:"post"`
+	Delete     *operation  `yaml:"delete"`
+	Patch      *operation  `yaml:"patch"`
+	Parameters []parameter `yaml:"parameters"`
+}
+
+type operation struct {
+	OperationId string      `yaml:"operationId"`
+	Summary     string      `yaml:"summary"`
+	Parameters  []parameter `yaml:"parameters"`
+	RequestBody *struct {
+		Content map[string]mediaType `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]mediaType `yaml:"content"`
+	} `yaml:"responses"`
+}
+
+type parameter struct {
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Schema   *schema `yaml:"schema"`
+}
+
+type mediaType struct {
+	Schema *schema `yaml:"schema"`
+}
+
+type schema struct {
+	Ref        string             `yaml:"$ref"`
+	Title      string             `yaml:"title"`
+	Type       string             `yaml:"type"`
+	Items      *schema            `yaml:"items"`
+	Properties map[string]*schema `yaml:"properties"`
+	Required   []string           `yaml:"required"`
+}
+
+// An operation of an OpenAPI spec, with the types of its request and response.
+type Operation struct {
+	Id         string
+	Method     string
+	Path       string
+	Summary    string
+	Parameters []Parameter
+	// The type of the request body, empty without one.
+	RequestType string
+	// The type of the successful response, empty without content.
+	ResponseType string
+	// The object types of the request and response.
+	Types []Type
+}
+
+// A path, query or header parameter of an operation.
+type Parameter struct {
+	Name     string
+	In       string
+	Type     string
+	Required bool
+}
+
+// An object type, named after its schema in the components of the spec or its title.
+type Type struct {
+	Name       string
+	Properties []Property
+}
+
+type Property struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+/*
+ * Reads the operation with the given operationId from the OpenAPI spec. Types are OpenAPI types, such as string
+ * or integer, the names of object schemas, and type[] for arrays.
+ */
+func FindOperation(specFile string, operationId string) (Operation, error) {
+	content, err := ioutil.ReadFile(specFile)
+	if err != nil {
+		return Operation{}, err
+	}
+	doc := spec{}
+	if err = yaml.Unmarshal(content, &doc); err != nil {
+		return Operation{}, errors.New(fmt.Sprintf("invalid OpenAPI spec %s: %v", specFile, err))
+	}
+	for _, p := range sortedKeys(doc.Paths) {
+		item := doc.Paths[p]
+		for _, method := range item.operations() {
+			if method.op == nil || method.op.OperationId != operationId {
+				continue
+			}
+			return doc.operation(p, method.name, item.Parameters, *method.op), nil
+		}
+	}
+	return Operation{}, errors.New(fmt.Sprintf("operation %s not found in %s", operationId, specFile))
+}
+
+type namedOperation struct {
+	name string
+	op   *operation
+}
+
+func (item pathItem) operations() []namedOperation {
+	return []namedOperation{
+		{"GET", item.Get},
+		{"PUT", item.Put},
+		{"POST", item.Post},
+		{"DELETE", item.Delete},
+		{"PATCH", item.Patch},
+	}
+}
+
+func (doc spec) operation(p string, method string, pathParameters []parameter, op operation) Operation {
+	result := Operation{Id: op.OperationId, Method: method, Path: p, Summary: op.Summary}
+	for _, param := range append(pathParameters, op.Parameters...) {
+		result.Parameters = append(result.Parameters, Parameter{Name: param.Name, In: param.In, Type: typeName(param.Schema), Required: param.Required})
+	}
+	if op.RequestBody != nil {
+		request := contentSchema(op.RequestBody.Content)
+		result.RequestType = typeName(request)
+		result.Types = doc.addType(result.Types, request)
+	}
+	for _, code := range sortedKeys(op.Responses) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		if response := contentSchema(op.Responses[code].Content); response != nil {
+			result.ResponseType = typeName(response)
+			result.Types = doc.addType(result.Types, response)
+			break
+		}
+	}
+	return result
+}
+
+// The JSON schema of a content, if any, or of its first media type.
+func contentSchema(content map[string]mediaType) *schema {
+	if media, ok := content["application/json"]; ok {
+		return media.Schema
+	}
+	for _, mediaType := range sortedKeys(content) {
+		return content[mediaType].Schema
+	}
+	return nil
+}
+
+func typeName(s *schema) string {
+	switch {
+	case s == nil:
+		return "object"
+	case s.Ref != "":
+		return path.Base(s.Ref)
+	case s.Type == "array":
+		return typeName(s.Items) + "[]"
+	case s.Title != "":
+		return s.Title
+	case s.Type == "":
+		return "object"
+	}
+	return s.Type
+}
+
+// Adds the object type of a schema, or of the items of an array schema, unless already added.
+func (doc spec) addType(types []Type, s *schema) []Type {
+	for s != nil && s.Type == "array" {
+		s = s.Items
+	}
+	if s == nil {
+		return types
+	}
+	name := typeName(s)
+	if s.Ref != "" {
+		s = doc.Components.Schemas[name]
+	}
+	if s == nil || len(s.Properties) == 0 {
+		return types
+	}
+	for _, t := range types {
+		if t.Name == name {
+			return types
+		}
+	}
+	t := Type{Name: name}
+	for _, property := range sortedKeys(s.Properties) {
+		t.Properties = append(t.Properties, Property{Name: property, Type: typeName(s.Properties[property]), Required: contains(s.Required, property)})
+	}
+	return append(types, t)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// The string keys of a map, sorted for the operations and types to be found in a stable order.
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
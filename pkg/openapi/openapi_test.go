@@ -0,0 +1,66 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const usersSpec = "../../test_data/openapi/users.yaml"
+
+func TestFindOperation(t *testing.T) {
+	as := assert.New(t)
+
+	op, err := FindOperation(usersSpec, "getUser")
+	as.NoError(err)
+	as.Equal("GET", op.Method)
+	as.Equal("/users/{id}", op.Path)
+	as.Equal("Returns a user by id", op.Summary)
+	as.Equal([]Parameter{
+		{Name: "id", In: "path", Type: "integer", Required: true},
+		{Name: "fields", In: "query", Type: "string"},
+	}, op.Parameters)
+	as.Equal("", op.RequestType)
+	as.Equal("User", op.ResponseType)
+	as.Equal([]Type{{Name: "User", Properties: []Property{
+		{Name: "email", Type: "string"},
+		{Name: "id", Type: "integer", Required: true},
+		{Name: "name", Type: "string", Required: true},
+	}}}, op.Types)
+}
+
+func TestFindOperationWithRequestBody(t *testing.T) {
+	as := assert.New(t)
+
+	op, err := FindOperation(usersSpec, "createUser")
+	as.NoError(err)
+	as.Equal("POST", op.Method)
+	as.Equal("NewUser", op.RequestType)
+	as.Equal("User", op.ResponseType)
+	as.Len(op.Types, 2)
+	as.Equal("NewUser", op.Types[0].Name)
+	as.Equal([]Property{{Name: "name", Type: "string", Required: true}, {Name: "tags", Type: "string[]"}}, op.Types[0].Properties)
+}
+
+func TestFindMissingOperation(t *testing.T) {
+	as := assert.New(t)
+
+	_, err := FindOperation(usersSpec, "deleteUser")
+	as.EqualError(err, "operation deleteUser not found in "+usersSpec)
+}
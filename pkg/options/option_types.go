@@ -17,6 +17,68 @@ package options
 
 var SupportedProtocols = []string{"stdio", "http", "grpc"}
 
+// The riff resource apiVersions resources can be generated for, the first one being the default.
+var SupportedApiVersions = []string{"projectriff.io/v1", "projectriff.io/v1alpha1"}
+
+// The port invokers conventionally listen on for each network protocol.
+var DefaultPorts = map[string]int{"http": 8080, "grpc": 10382}
+
+// The interpreters the shell invoker can run scripts with.
+var SupportedShells = []string{"sh", "bash"}
+
+// How the image tag is chosen: the given version, or a hash of the function sources.
+const (
+	TagStrategyVersion     = "version"
+	TagStrategyContentHash = "content-hash"
+)
+
+// The kinds of artifacts --only can restrict generation to.
+const (
+	OnlyDockerfile = "dockerfile"
+	OnlyResources  = "resources"
+)
+
+var OnlyKinds = []string{OnlyDockerfile, OnlyResources}
+
+// The formats a dry run can show the generated artifacts in.
+const (
+	OutputFormatText = "text"
+	OutputFormatJson = "json"
+)
+
+var OutputFormats = []string{OutputFormatText, OutputFormatJson}
+
+/*
+ * The tools the function jar of a java function can be built with in the image. Unless given, the tool is
+ * detected from the build file of the function, none meaning the jar is built beforehand.
+ */
+const (
+	BuildToolMaven  = "maven"
+	BuildToolGradle = "gradle"
+	BuildToolNone   = "none"
+)
+
+var BuildTools = []string{BuildToolMaven, BuildToolGradle, BuildToolNone}
+
+// The build files the build tool is detected from, in order of precedence.
+var BuildFiles = []struct{ Name, Tool string }{
+	{"pom.xml", BuildToolMaven},
+	{"build.gradle", BuildToolGradle},
+	{"build.gradle.kts", BuildToolGradle},
+}
+
+// The mode of scaffolded executables, such as scripts and Makefiles, unless given.
+const DefaultExecutableMode = "0755"
+
+// The most replicas KEDA scales a function to, unless given.
+const DefaultMaxReplicas = 10
+
+// The directory of the image the invoker loads functions from, unless given.
+const DefaultImageFunctionDir = "/functions"
+
+// The user functions run as with --non-root, conventionally the unprivileged 'nobody'.
+const DefaultNonRootUser = "65534"
+
 type InitOptions struct {
 	FunctionName string
 	Version      string
@@ -25,12 +87,108 @@ type InitOptions struct {
 	Input        string
 	Output       string
 	Artifact     string
+	ArtifactBase string
 	RiffVersion  string
 	UserAccount  string
+	Registry     string
 	Initialized  bool
 	DryRun		 bool
 	Force		 bool
 	Handler 	string
+	Secrets      []string
+	ConfigMaps   []string
+	GitHubActions bool
+	Port          int
+	BaseImageAllowlist string
+	CpuRequest    string
+	CpuLimit      string
+	MemoryRequest string
+	MemoryLimit   string
+	UriParams     []string
+	Strict        bool
+	Helm          bool
+	ImageRefOut   string
+	TemplateDir   string
+	Timeout       string
+	Context       string
+	GitIgnore     bool
+	BaseImage     string
+	Offline       bool
+	RunAsUser     string
+	NonRoot       bool
+	TagStrategy   string
+	ApiVersion    string
+	PipIndexUrl   string
+	ResourceTemplate string
+	ExecutableMode   string
+	Inline           bool
+	ResourceName     string
+	DevContainer     bool
+	TopicProvider    string
+	Namespace        string
+	PreHook          string
+	PostHook         string
+	Minimal          bool
+	Only             string
+	InstallCmd       string
+	NoInstall        bool
+	CloudEvents      bool
+	ImageVariant     string
+	Keda             bool
+	MinReplicas      int
+	MaxReplicas      int
+	UseCopy          bool
+	Annotations      []string
+	Shell            string
+	QuietDeps        bool
+	ExplainDetection bool
+	ImageFunctionDir string
+	ReadinessPath    string
+	LivenessPath     string
+	ProbeInitialDelay int
+	ProbePeriod      int
+	ProbeTimeout     int
+	Kustomize        bool
+	OutputFormat     string
+	Detection        *DetectionReport
+	FromGit          string
+	Subdir           string
+	ManifestOut      string
+	Tekton           bool
+	Entrypoint       string
+	Cmd              string
+	ExplainUri       bool
+	ArgoCD           bool
+	RepoUrl          string
+	RepoPath         string
+	MinScale         int
+	MaxScale         int
+	BuildTool        string
+	TarOut           string
+	LintSource       bool
+	Keyed            bool
+	FromOpenApi      string
+	Operation        string
+	// A fixed replica count, nil unless given, 0 being a valid count.
+	Replicas         *int
+	EnvFile          bool
+	Env              []string
+	VerifyHandler    bool
+	// Whether RiffVersion was given explicitly, taking precedence over riff.lock.
+	RiffVersionPinned bool
+}
+
+// What language detection found, reported by a JSON dry run when the language is detected.
+type DetectionReport struct {
+	DetectedLanguage string            `json:"detectedLanguage"`
+	Scores           map[string]int    `json:"scores"`
+	Signals          []DetectionSignal `json:"signals"`
+}
+
+type DetectionSignal struct {
+	Language string `json:"language"`
+	Weight   int    `json:"weight"`
+	Reason   string `json:"reason"`
 }
 
 func (this InitOptions) GetFunctionName() string {
@@ -45,14 +203,24 @@ func (this InitOptions) GetUserAccount() string {
 	return this.UserAccount
 }
 
+func (this InitOptions) GetRegistry() string {
+	return this.Registry
+}
+
 type BuildOptions struct {
 	FunctionPath string
 	FunctionName string
 	Version      string
 	RiffVersion  string
 	UserAccount  string
+	Registry     string
 	Push         bool
+	NoCache      bool
+	CacheBust    string
 	DryRun		 bool
+	Context      string
+	Platform     string
+	PreviewBuild bool
 }
 
 func (this BuildOptions) GetFunctionName() string {
@@ -67,24 +235,41 @@ func (this BuildOptions) GetUserAccount() string {
 	return this.UserAccount
 }
 
+func (this BuildOptions) GetRegistry() string {
+	return this.Registry
+}
+
 type ApplyOptions struct {
 	FunctionPath string
 	DryRun		 bool
+	Diff         bool
+	ReplaceTopics bool
+	Wait          bool
+	ServerDryRun  bool
 }
 
 type CreateOptions struct {
 	InitOptions
 	Push        bool
+	NoCache     bool
+	CacheBust   string
+	Diff        bool
+	ReplaceTopics bool
+	Wait          bool
+	ServerDryRun  bool
+	Platform    string
+	PreviewBuild bool
 }
 
 type ImageOptions interface {
 	GetFunctionName() string
 	GetVersion()      string
 	GetUserAccount()  string
+	GetRegistry()     string
 }
 
 func GetApplyOptions(opts CreateOptions) ApplyOptions {
-	return ApplyOptions{FunctionPath:opts.FunctionPath, DryRun:opts.DryRun}
+	return ApplyOptions{FunctionPath:opts.FunctionPath, DryRun:opts.DryRun, Diff:opts.Diff, ReplaceTopics:opts.ReplaceTopics, Wait:opts.Wait, ServerDryRun:opts.ServerDryRun}
 }
 
 func GetBuildOptions(opts CreateOptions) BuildOptions {
@@ -94,8 +279,14 @@ func GetBuildOptions(opts CreateOptions) BuildOptions {
 		Version:opts.Version,
 		RiffVersion:opts.RiffVersion,
 		UserAccount:opts.UserAccount,
+		Registry:opts.Registry,
 		Push:opts.Push,
+		NoCache:opts.NoCache,
+		CacheBust:opts.CacheBust,
 		DryRun:opts.DryRun,
+		Context:opts.Context,
+		Platform:opts.Platform,
+		PreviewBuild:opts.PreviewBuild,
 	}
 }
 
@@ -17,29 +17,230 @@
 package options
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"net/url"
+	"path"
+	"strconv"
 	"path/filepath"
 	"github.com/projectriff/riff-cli/pkg/osutils"
 	"fmt"
 	"strings"
 	"errors"
+	"regexp"
+	"text/template"
+	"time"
 	"github.com/projectriff/riff-cli/pkg/functions"
+	"github.com/projectriff/riff-cli/pkg/ioutils"
 )
 
+var dns1123SubdomainRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+var dns1123LabelRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// A part of a Docker image tag, which as a whole must not be longer than 128 characters.
+var tagFragmentRegexp = regexp.MustCompile(`^[\w.-]+$`)
+
+// A Kubernetes annotation key: a name, optionally prefixed with a DNS-1123 subdomain and a slash.
+var annotationKeyRegexp = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+
+// A URL, starting with its scheme.
+var remoteArtifactRegexp = regexp.MustCompile(`^[a-z][a-z0-9+.-]*://`)
+
+// A Kubernetes resource quantity, e.g. 100m, 0.5 or 256Mi.
+var quantityRegexp = regexp.MustCompile(`^([0-9]+(\.[0-9]*)?|\.[0-9]+)(m|k|M|G|T|P|E|Ki|Mi|Gi|Ti|Pi|Ei|[eE][-+]?[0-9]+)?$`)
+
+/*
+ * The port the function container listens on: the given port, or the protocol's conventional port.
+ * Zero means the protocol does not listen on a port.
+ */
+func FunctionPort(opts InitOptions) int {
+	if opts.Port != 0 {
+		return opts.Port
+	}
+	return DefaultPorts[opts.Protocol]
+}
+
+// The most replicas KEDA scales the function to: the given maximum, or the default one.
+func MaxReplicas(opts InitOptions) int {
+	if opts.MaxReplicas != 0 {
+		return opts.MaxReplicas
+	}
+	return DefaultMaxReplicas
+}
+
+// The build tool given, or the one whose build file the function directory has, empty when there is none.
+func JarBuildTool(opts InitOptions) string {
+	switch opts.BuildTool {
+	case BuildToolNone:
+		return ""
+	case "":
+		for _, file := range BuildFiles {
+			if osutils.FileExists(filepath.Join(FunctionDir(opts), file.Name)) {
+				return file.Tool
+			}
+		}
+		return ""
+	}
+	return opts.BuildTool
+}
+
+// A jar built in the image by its build stage, which a clean checkout does not have yet.
+func BuiltInImage(opts InitOptions) bool {
+	return strings.HasSuffix(opts.Artifact, ".jar") && JarBuildTool(opts) != ""
+}
+
+// An HTTP path, as probed by Kubernetes.
+var probePathRegexp = regexp.MustCompile(`^/\S*$`)
+
+/*
+ * Probes are HTTP requests to the function port, so they need one. Their timings are in seconds, 0 leaving
+ * the Kubernetes default.
+ */
+func validateProbes(options InitOptions) error {
+	for _, probePath := range []string{options.ReadinessPath, options.LivenessPath} {
+		if probePath != "" && !probePathRegexp.MatchString(probePath) {
+			return errors.New(fmt.Sprintf("probe path %s must be an absolute HTTP path, such as /healthz", probePath))
+		}
+	}
+	probed := options.ReadinessPath != "" || options.LivenessPath != ""
+	if probed && FunctionPort(options) == 0 {
+		return errors.New(fmt.Sprintf("probes need the function to listen on a port, which %s functions do not unless --port is set", options.Protocol))
+	}
+	if options.ProbeInitialDelay < 0 || options.ProbePeriod < 0 || options.ProbeTimeout < 0 {
+		return errors.New("probe timings must be 0 or more seconds")
+	}
+	if !probed && (options.ProbeInitialDelay != 0 || options.ProbePeriod != 0 || options.ProbeTimeout != 0) {
+		return errors.New("probe timings need --readiness-path or --liveness-path")
+	}
+	return nil
+}
+
+// A git repository URL, over HTTP(S), SSH or git, or in the scp-like syntax of SSH, e.g. git@github.com:me/fns.git.
+var repoUrlRegexp = regexp.MustCompile(`^((https?|ssh|git)://[^\s/]+/\S+|[\w.-]+@[\w.-]+:\S+)$`)
+
+// The name of an environment variable of --env.
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// The name and value of an --env variable given as NAME=value.
+func SplitEnv(env string) (string, string) {
+	parts := strings.SplitN(env, "=", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// The ArgoCD Application syncs the function resources from a relative path of a git repository.
+func validateArgoCD(options InitOptions) error {
+	if !options.ArgoCD {
+		if options.RepoUrl != "" || options.RepoPath != "" {
+			return errors.New("--repo-url and --path are only supported with --argocd")
+		}
+		return nil
+	}
+	if options.RepoUrl == "" || options.RepoPath == "" {
+		return errors.New("--argocd requires --repo-url and --path")
+	}
+	if !repoUrlRegexp.MatchString(options.RepoUrl) {
+		return errors.New(fmt.Sprintf("repo URL %s is not a git repository URL, such as https://github.com/me/functions.git", options.RepoUrl))
+	}
+	if clean := path.Clean(options.RepoPath); path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return errors.New(fmt.Sprintf("path %s must be relative to the root of the repository", options.RepoPath))
+	}
+	return nil
+}
+
+// The directory of the image the function files are added to: the given one, or the invoker's default.
+func ImageFunctionDir(opts InitOptions) string {
+	if opts.ImageFunctionDir != "" {
+		return path.Clean(opts.ImageFunctionDir)
+	}
+	return DefaultImageFunctionDir
+}
+
+/*
+ * The words of an --entrypoint or --cmd in exec form, given either as a JSON array, e.g. '["node", "server.js"]',
+ * or as words separated by spaces.
+ */
+func ExecForm(value string) ([]string, error) {
+	if !strings.HasPrefix(strings.TrimSpace(value), "[") {
+		return strings.Fields(value), nil
+	}
+	var words []string
+	err := json.Unmarshal([]byte(value), &words)
+	return words, err
+}
+
+// The annotations of the generated resources by key, the last value given for a key winning.
+func Annotations(opts InitOptions) map[string]string {
+	if len(opts.Annotations) == 0 {
+		return nil
+	}
+	annotations := map[string]string{}
+	for _, annotation := range opts.Annotations {
+		kv := strings.SplitN(annotation, "=", 2)
+		annotations[kv[0]] = kv[1]
+	}
+	return annotations
+}
+
+// The user the function container runs as, or empty to keep the base image's user.
+func RunAsUser(opts InitOptions) string {
+	if opts.RunAsUser == "" && opts.NonRoot {
+		return DefaultNonRootUser
+	}
+	return opts.RunAsUser
+}
+
+// The apiVersion of the generated riff resources, the given one or the default.
+func ApiVersion(opts InitOptions) string {
+	if opts.ApiVersion != "" {
+		return opts.ApiVersion
+	}
+	return SupportedApiVersions[0]
+}
+
+// The name of the generated function resource, the given one or the function name.
+func ResourceName(opts InitOptions) string {
+	if opts.ResourceName != "" {
+		return opts.ResourceName
+	}
+	return opts.FunctionName
+}
+
+// The mode scaffolded executables are written with.
+func ExecutableMode(opts InitOptions) os.FileMode {
+	mode := opts.ExecutableMode
+	if mode == "" {
+		mode = DefaultExecutableMode
+	}
+	parsed, _ := strconv.ParseUint(mode, 8, 32)
+	return os.FileMode(parsed)
+}
+
 func ImageName(opts ImageOptions) string {
-	return fmt.Sprintf("%s/%s:%s",opts.GetUserAccount(),opts.GetFunctionName(),opts.GetVersion())
+	image := fmt.Sprintf("%s/%s:%s",opts.GetUserAccount(),opts.GetFunctionName(),opts.GetVersion())
+	if opts.GetRegistry() != "" {
+		return fmt.Sprintf("%s/%s", strings.TrimRight(opts.GetRegistry(), "/"), image)
+	}
+	return image
 }
 
 /*
  * Basic sanity check that given paths exist and valid protocol given.
- * Artifact must be a regular file.
+ * Artifact must be a regular file, or a python package directory.
  * If artifact is given, it must be relative to the function path.
  * If function path is given as a regular file, and artifact is also given, they must reference the same path (edge case).
+ * Symlinks are resolved, and the real artifact path must stay within the function directory.
+ * A remote artifact, given as a URL, is fetched when building the image and is not checked.
  * TODO: Format (regex) check on function name, input, output, version, riff_version
  */
 func ValidateAndCleanInitOptions(options *InitOptions) error {
 
 	options.FunctionPath = filepath.Clean(options.FunctionPath)
-	if options.Artifact != "" {
+	if options.Artifact != "" && !IsRemoteArtifact(options.Artifact) {
 		options.Artifact = filepath.Clean(options.Artifact)
 	}
 
@@ -56,7 +257,7 @@ func ValidateAndCleanInitOptions(options *InitOptions) error {
 		}
 	}
 
-	if options.Artifact != "" {
+	if options.Artifact != "" && !IsRemoteArtifact(options.Artifact) {
 
 		if filepath.IsAbs(options.Artifact) {
 			return errors.New(fmt.Sprintf("artifact %s must be relative to function path", options.Artifact))
@@ -67,16 +268,23 @@ func ValidateAndCleanInitOptions(options *InitOptions) error {
 			return err
 		}
 
-		var absArtifactPath string
+		artifactDir := absFilePath
+		if !osutils.IsDirectory(absFilePath) {
+			artifactDir = filepath.Dir(absFilePath)
+		}
 
-		if osutils.IsDirectory(absFilePath) {
-			absArtifactPath = filepath.Join(absFilePath, options.Artifact)
-		} else {
-			absArtifactPath = filepath.Join(filepath.Dir(absFilePath), options.Artifact)
+		// Case-insensitive filesystems find the artifact whatever its case, but the Dockerfile needs the real one.
+		if actual := actualCase(artifactDir, options.Artifact); actual != options.Artifact {
+			err = Warning(options.Strict, fmt.Sprintf("artifact %s does not match the case of %s on disk, using %s", options.Artifact, actual, actual))
+			if err != nil {
+				return err
+			}
+			options.Artifact = actual
 		}
+		absArtifactPath := filepath.Join(artifactDir, options.Artifact)
 
-		if osutils.IsDirectory(absArtifactPath) {
-			return errors.New(fmt.Sprintf("artifact %s must be a regular file", absArtifactPath))
+		if osutils.IsDirectory(absArtifactPath) && !osutils.FileExists(filepath.Join(absArtifactPath, "__init__.py")) {
+			return errors.New(fmt.Sprintf("artifact %s must be a regular file or a python package", absArtifactPath))
 		}
 
 		absFilePathDir := absFilePath
@@ -85,11 +293,27 @@ func ValidateAndCleanInitOptions(options *InitOptions) error {
 		}
 
 		if !strings.HasPrefix(filepath.Dir(absArtifactPath), absFilePathDir) {
-			return errors.New(fmt.Sprintf("artifact %s cannot be external to filepath %", absArtifactPath, absFilePath))
+			return errors.New(fmt.Sprintf("artifact %s cannot be external to filepath %s", absArtifactPath, absFilePath))
 		}
 
-		if !osutils.FileExists(absArtifactPath) {
-			return errors.New(fmt.Sprintf("artifact %s does not exist", absArtifactPath))
+		// A jar built by the build stage is only in the image, not on disk.
+		if !BuiltInImage(*options) {
+			if !osutils.FileExists(absArtifactPath) {
+				return errors.New(fmt.Sprintf("artifact %s does not exist", absArtifactPath))
+			}
+
+			// A symlinked artifact must still resolve inside the directory used as the docker build context.
+			realFilePathDir, err := filepath.EvalSymlinks(absFilePathDir)
+			if err != nil {
+				return err
+			}
+			realArtifactPath, err := filepath.EvalSymlinks(absArtifactPath)
+			if err != nil {
+				return err
+			}
+			if !isWithin(realFilePathDir, realArtifactPath) {
+				return errors.New(fmt.Sprintf("artifact %s resolves to %s, outside of the build context %s", absArtifactPath, realArtifactPath, realFilePathDir))
+			}
 		}
 
 		if !osutils.IsDirectory(absFilePath) && absFilePath != absArtifactPath {
@@ -97,6 +321,24 @@ func ValidateAndCleanInitOptions(options *InitOptions) error {
 		}
 	}
 
+	if options.ArtifactBase != "" && filepath.Base(options.ArtifactBase) != options.ArtifactBase {
+		return errors.New(fmt.Sprintf("artifact base %s must be a file name, not a path", options.ArtifactBase))
+	}
+
+	switch options.TagStrategy {
+	case "", TagStrategyVersion:
+	case TagStrategyContentHash:
+		if IsRemoteArtifact(options.Artifact) {
+			return errors.New(fmt.Sprintf("tag strategy %s needs a local artifact, not %s", TagStrategyContentHash, options.Artifact))
+		}
+		options.Version, err = functions.ContentHashTag(FunctionDir(*options))
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New(fmt.Sprintf("tag strategy %s is unsupported, use %s or %s", options.TagStrategy, TagStrategyVersion, TagStrategyContentHash))
+	}
+
 
 	if options.Protocol != "" {
 
@@ -112,5 +354,435 @@ func ValidateAndCleanInitOptions(options *InitOptions) error {
 		}
 	}
 
+	if options.ApiVersion != "" {
+		supported := false
+		for _, v := range SupportedApiVersions {
+			if options.ApiVersion == v {
+				supported = true
+			}
+		}
+		if !supported {
+			return errors.New(fmt.Sprintf("api version %s is unsupported, use one of %s", options.ApiVersion, strings.Join(SupportedApiVersions, ", ")))
+		}
+	}
+
+	if options.Context != "" {
+		options.Context = filepath.Clean(options.Context)
+		if !osutils.IsDirectory(options.Context) {
+			return errors.New(fmt.Sprintf("build context %s is not a directory", options.Context))
+		}
+		absContext, err := filepath.Abs(options.Context)
+		if err != nil {
+			return err
+		}
+		absFunctionDir, err := filepath.Abs(FunctionDir(*options))
+		if err != nil {
+			return err
+		}
+		if !isWithin(absContext, absFunctionDir) {
+			return errors.New(fmt.Sprintf("function path %s must be within the build context %s", options.FunctionPath, options.Context))
+		}
+	}
+
+	if strings.ContainsAny(options.BaseImage, " \t\n") {
+		return errors.New(fmt.Sprintf("base image %q is not a valid image reference", options.BaseImage))
+	}
+
+	if options.RunAsUser != "" {
+		if uid, err := strconv.Atoi(options.RunAsUser); err != nil || uid < 0 {
+			return errors.New(fmt.Sprintf("run as user %s must be a numeric user id", options.RunAsUser))
+		}
+		if options.NonRoot && options.RunAsUser == "0" {
+			return errors.New("run as user 0 is root, which --non-root forbids")
+		}
+	}
+
+	if options.PipIndexUrl != "" {
+		indexUrl, err := url.Parse(options.PipIndexUrl)
+		if err != nil || (indexUrl.Scheme != "http" && indexUrl.Scheme != "https") || indexUrl.Host == "" {
+			return errors.New(fmt.Sprintf("pip index url %s must be an http(s) URL", options.PipIndexUrl))
+		}
+		if indexUrl.User != nil {
+			return errors.New(fmt.Sprintf("pip index url for %s must not embed credentials, pass them as the PIP_INDEX_CREDENTIALS build arg", indexUrl.Host))
+		}
+	}
+
+	if options.ExecutableMode != "" {
+		if mode, err := strconv.ParseUint(options.ExecutableMode, 8, 32); err != nil || mode > 0777 {
+			return errors.New(fmt.Sprintf("executable mode %s must be an octal file mode, e.g. 0755", options.ExecutableMode))
+		}
+	}
+
+	if options.ResourceTemplate != "" {
+		if _, err := template.ParseFiles(options.ResourceTemplate); err != nil {
+			return errors.New(fmt.Sprintf("resource template %s is invalid: %v", options.ResourceTemplate, err))
+		}
+	}
+
+	if options.TemplateDir != "" && !osutils.IsDirectory(options.TemplateDir) {
+		return errors.New(fmt.Sprintf("template directory %s does not exist", options.TemplateDir))
+	}
+
+	if options.BaseImageAllowlist != "" && !IsURL(options.BaseImageAllowlist) && !osutils.FileExists(options.BaseImageAllowlist) {
+		return errors.New(fmt.Sprintf("base image allowlist %s does not exist", options.BaseImageAllowlist))
+	}
+
+	if options.Timeout != "" {
+		if _, err := time.ParseDuration(options.Timeout); err != nil {
+			return errors.New(fmt.Sprintf("timeout %s is not a valid duration, e.g. 30s or 5m", options.Timeout))
+		}
+	}
+
+	if options.Port < 0 || options.Port > 65535 {
+		return errors.New(fmt.Sprintf("port %d must be between 1 and 65535", options.Port))
+	}
+
+	quantities := []struct{ flag, value string }{
+		{"cpu-request", options.CpuRequest},
+		{"cpu-limit", options.CpuLimit},
+		{"memory-request", options.MemoryRequest},
+		{"memory-limit", options.MemoryLimit},
+	}
+	for _, q := range quantities {
+		if q.value != "" && !quantityRegexp.MatchString(q.value) {
+			return errors.New(fmt.Sprintf("%s %s is not a valid resource quantity", q.flag, q.value))
+		}
+	}
+
+	if options.ResourceName != "" && !isDNS1123Subdomain(options.ResourceName) {
+		return errors.New(fmt.Sprintf("resource name %s is not a valid DNS-1123 subdomain", options.ResourceName))
+	}
+
+	for _, param := range options.UriParams {
+		if strings.Index(param, "=") < 1 {
+			return errors.New(fmt.Sprintf("uri param %s must be of the form key=value", param))
+		}
+	}
+
+	for _, annotation := range options.Annotations {
+		kv := strings.SplitN(annotation, "=", 2)
+		if len(kv) < 2 || !annotationKeyRegexp.MatchString(kv[0]) {
+			return errors.New(fmt.Sprintf("annotation %s must be of the form key=value, with a valid annotation key", annotation))
+		}
+	}
+
+	for _, topic := range strings.Split(options.Input+","+options.Output, ",") {
+		if name, option := splitTopic(topic); option != "" && option != KeyedTopicOption {
+			return errors.New(fmt.Sprintf("topic %s has the unknown option %s, use %s:%s for a keyed topic", strings.TrimSpace(topic), option, name, KeyedTopicOption))
+		}
+	}
+	for _, topic := range append(Topics(options.Input), Topics(options.Output)...) {
+		if !isDNS1123Subdomain(topic) {
+			return errors.New(fmt.Sprintf("topic name %s is not a valid DNS-1123 subdomain", topic))
+		}
+	}
+
+	inputs := Topics(options.Input)
+	if len(inputs) == 0 {
+		inputs = []string{options.FunctionName}
+	}
+	for _, output := range Topics(options.Output) {
+		for _, input := range inputs {
+			if output == input {
+				err = Warning(options.Strict, fmt.Sprintf("input and output topic are both %s, the function will consume its own output", input))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := validateArgoCD(*options); err != nil {
+		return err
+	}
+
+	for _, env := range options.Env {
+		if name, _ := SplitEnv(env); !strings.Contains(env, "=") || !envNameRegexp.MatchString(name) {
+			return errors.New(fmt.Sprintf("env %s must be NAME=value, the name being letters, digits and underscores", env))
+		}
+	}
+
+	if options.FromOpenApi != "" && options.Operation == "" {
+		return errors.New("--from-openapi requires the --operation to scaffold the function from")
+	}
+	if options.Operation != "" && options.FromOpenApi == "" {
+		return errors.New("--operation is only supported with --from-openapi")
+	}
+
+	if options.Subdir != "" && options.FromGit == "" {
+		return errors.New("--subdir is only supported with --from-git")
+	}
+
+	if options.OutputFormat != "" {
+		supported := false
+		for _, format := range OutputFormats {
+			if options.OutputFormat == format {
+				supported = true
+			}
+		}
+		if !supported {
+			return errors.New(fmt.Sprintf("output format %s is unsupported, use one of %s", options.OutputFormat, strings.Join(OutputFormats, ", ")))
+		}
+		if options.OutputFormat == OutputFormatJson && !options.DryRun {
+			return errors.New("--output-format json is only supported with --dry-run")
+		}
+	}
+
+	if options.ImageFunctionDir != "" && !path.IsAbs(options.ImageFunctionDir) {
+		return errors.New(fmt.Sprintf("function dir %s must be an absolute path in the image", options.ImageFunctionDir))
+	}
+
+	for _, instruction := range []struct{ flag, value string }{{"entrypoint", options.Entrypoint}, {"cmd", options.Cmd}} {
+		if instruction.value == "" {
+			continue
+		}
+		words, err := ExecForm(instruction.value)
+		if err != nil {
+			return errors.New(fmt.Sprintf("--%s %s must be words separated by spaces or a JSON array of strings: %v", instruction.flag, instruction.value, err))
+		}
+		if len(words) == 0 || words[0] == "" {
+			return errors.New(fmt.Sprintf("--%s must not be empty", instruction.flag))
+		}
+	}
+
+	if options.Only != "" {
+		supported := false
+		for _, kind := range OnlyKinds {
+			if options.Only == kind {
+				supported = true
+			}
+		}
+		if !supported {
+			return errors.New(fmt.Sprintf("unknown kind of artifacts %s for --only, use one of %s", options.Only, strings.Join(OnlyKinds, ", ")))
+		}
+	}
+
+	if options.InstallCmd != "" && strings.TrimSpace(options.InstallCmd) == "" {
+		return errors.New("install command must not be empty")
+	}
+
+	if options.ImageVariant != "" && (!tagFragmentRegexp.MatchString(options.ImageVariant) || len(options.RiffVersion+options.ImageVariant) > 128) {
+		return errors.New(fmt.Sprintf("image variant %s cannot be part of an image tag, use letters, digits, '_', '.' and '-'", options.ImageVariant))
+	}
+
+	if options.Keda && (options.MinReplicas < 0 || MaxReplicas(*options) < options.MinReplicas) {
+		return errors.New(fmt.Sprintf("replicas must range from 0 or more to at least as many, not from %d to %d", options.MinReplicas, MaxReplicas(*options)))
+	}
+
+	if options.MinScale < 0 || options.MaxScale < 0 {
+		return errors.New("scale bounds must be 0 or more replicas")
+	}
+	if options.MaxScale != 0 && options.MinScale > options.MaxScale {
+		return errors.New(fmt.Sprintf("--min-scale %d must not be more than --max-scale %d", options.MinScale, options.MaxScale))
+	}
+	if options.Replicas != nil && *options.Replicas < 0 {
+		return errors.New(fmt.Sprintf("--replicas %d must be 0 or more replicas", *options.Replicas))
+	}
+
+	if err := validateProbes(*options); err != nil {
+		return err
+	}
+
+	if options.BuildTool != "" {
+		supported := false
+		for _, tool := range BuildTools {
+			if options.BuildTool == tool {
+				supported = true
+			}
+		}
+		if !supported {
+			return errors.New(fmt.Sprintf("build tool %s is unsupported, use one of %s", options.BuildTool, strings.Join(BuildTools, ", ")))
+		}
+	}
+
+	if options.Shell != "" {
+		supported := false
+		for _, shell := range SupportedShells {
+			if options.Shell == shell {
+				supported = true
+			}
+		}
+		if !supported {
+			return errors.New(fmt.Sprintf("shell %s is unsupported, use one of %s", options.Shell, strings.Join(SupportedShells, ", ")))
+		}
+	}
+
+	if options.Namespace != "" && !isDNS1123Label(options.Namespace) {
+		return errors.New(fmt.Sprintf("namespace %s is not a valid DNS-1123 label", options.Namespace))
+	}
+
+	if options.TopicProvider != "" && !isDNS1123Subdomain(options.TopicProvider) {
+		return errors.New(fmt.Sprintf("topic provider %s is not a valid DNS-1123 subdomain", options.TopicProvider))
+	}
+
+	for _, secret := range options.Secrets {
+		if !isDNS1123Subdomain(secret) {
+			return errors.New(fmt.Sprintf("secret name %s is not a valid DNS-1123 subdomain", secret))
+		}
+	}
+
+	for _, configMap := range options.ConfigMaps {
+		if !isDNS1123Subdomain(configMap) {
+			return errors.New(fmt.Sprintf("configmap name %s is not a valid DNS-1123 subdomain", configMap))
+		}
+	}
+
+	return nil
+}
+
+// The directory holding the function resources and the generated Dockerfile.
+func FunctionDir(opts InitOptions) string {
+	if osutils.IsDirectory(opts.FunctionPath) {
+		return opts.FunctionPath
+	}
+	return filepath.Dir(opts.FunctionPath)
+}
+
+/*
+ * The function directory relative to the build context, using forward slashes as in a Dockerfile.
+ * It is "." when no build context is given, the function directory then being the context.
+ */
+func FunctionDirInContext(opts InitOptions) string {
+	if opts.Context == "" {
+		return "."
+	}
+	absContext, err := filepath.Abs(opts.Context)
+	if err != nil {
+		return "."
+	}
+	absFunctionDir, err := filepath.Abs(FunctionDir(opts))
+	if err != nil {
+		return "."
+	}
+	rel, err := filepath.Rel(absContext, absFunctionDir)
+	if err != nil {
+		return "."
+	}
+	return filepath.ToSlash(rel)
+}
+
+// The option a topic of --input or --output can be suffixed with, e.g. orders:keyed.
+const KeyedTopicOption = "keyed"
+
+// The name of a topic of a comma separated list, and the option it is suffixed with, if any.
+func splitTopic(topic string) (string, string) {
+	parts := strings.SplitN(strings.TrimSpace(topic), ":", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// The distinct topic names in a comma separated list, in order of appearance, without their options.
+func Topics(list string) []string {
+	var topics []string
+	seen := map[string]bool{}
+	for _, topic := range strings.Split(list, ",") {
+		topic, _ = splitTopic(topic)
+		if topic != "" && !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+/*
+ * The topics of the function that are keyed, so that the messages of a key stay in order: all of them with
+ * --keyed, and those suffixed with :keyed otherwise.
+ */
+func KeyedTopics(opts InitOptions) map[string]bool {
+	keyed := map[string]bool{}
+	for _, topic := range strings.Split(opts.Input+","+opts.Output, ",") {
+		name, option := splitTopic(topic)
+		if name != "" && (opts.Keyed || option == KeyedTopicOption) {
+			keyed[name] = true
+		}
+	}
+	return keyed
+}
+
+// Whether the artifact is a URL, to be fetched when building the image rather than added from the function directory.
+func IsRemoteArtifact(artifact string) bool {
+	return remoteArtifactRegexp.MatchString(artifact)
+}
+
+// The file name of the artifact, for a URL the last segment of its path.
+func ArtifactName(artifact string) string {
+	if IsRemoteArtifact(artifact) {
+		if u, err := url.Parse(artifact); err == nil {
+			return path.Base(u.Path)
+		}
+	}
+	return filepath.Base(artifact)
+}
+
+// Whether a path is rather an http(s) URL, to be fetched over the network.
+func IsURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+func isWithin(dir string, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+/*
+ * The path relative to dir with the case of its entries on disk. An entry missing with the given case takes
+ * the case of the single entry differing only by case, if any.
+ */
+func actualCase(dir string, path string) string {
+	current := dir
+	var actual []string
+	for _, name := range strings.Split(filepath.ToSlash(path), "/") {
+		if entries, err := ioutil.ReadDir(current); err == nil {
+			var matches []string
+			for _, entry := range entries {
+				if entry.Name() == name {
+					matches = []string{name}
+					break
+				}
+				if strings.EqualFold(entry.Name(), name) {
+					matches = append(matches, entry.Name())
+				}
+			}
+			if len(matches) == 1 {
+				name = matches[0]
+			}
+		}
+		actual = append(actual, name)
+		current = filepath.Join(current, name)
+	}
+	return filepath.Join(actual...)
+}
+
+// Checks that a function name can name its image and resources.
+func ValidateFunctionName(name string) error {
+	if !isDNS1123Subdomain(name) {
+		return errors.New(fmt.Sprintf("function name %s is not a valid DNS-1123 subdomain", name))
+	}
+	return nil
+}
+
+func isDNS1123Subdomain(name string) bool {
+	return len(name) <= 253 && dns1123SubdomainRegexp.MatchString(name)
+}
+
+func isDNS1123Label(name string) bool {
+	return len(name) <= 63 && dns1123LabelRegexp.MatchString(name)
+}
+
+/*
+ * Reports a questionable but usable setting as a warning, or as an error under --strict.
+ * The affected checks are an artifact not matching the case of the file on disk, a topic both input and
+ * output of the function, a language other than the detected one, existing files skipped without --force,
+ * --verify-handler and --lint-source being skipped, a FUNCTION_URI that cannot be explained, the base image
+ * check skipped offline and a hook writing to stderr.
+ */
+func Warning(strict bool, msg string) error {
+	if strict {
+		return errors.New(msg)
+	}
+	ioutils.Warn(msg)
 	return nil
 }
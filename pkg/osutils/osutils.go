@@ -75,13 +75,31 @@ func Path(filename string) string {
 	return filepath.Join(strings.Split(path,"/")...)
 }
 
+/*
+ * The error of a failed command, carrying what the command wrote to stderr.
+ */
+type ExecError struct {
+	Err    error
+	Stderr string
+}
+
+func (e *ExecError) Error() string {
+	return e.Err.Error()
+}
+
 func Exec(cmdName string, cmdArgs [] string, timeout time.Duration) ([]byte, error) {
+	return ExecInDir("", cmdName, cmdArgs, timeout)
+}
+
+// Runs the command as Exec does, in dir, or in the current directory when dir is empty.
+func ExecInDir(dir string, cmdName string, cmdArgs [] string, timeout time.Duration) ([]byte, error) {
 	// Create a new context and add a timeout to it
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel() // The cancel should be deferred so resources are cleaned up
 
 	// Create the command with our context
 	cmd := exec.CommandContext(ctx, cmdName, cmdArgs...)
+	cmd.Dir = dir
 
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
@@ -99,5 +117,8 @@ func Exec(cmdName string, cmdArgs [] string, timeout time.Duration) ([]byte, err
 		return nil, ctx.Err()
 	}
 
-	return out, err
+	if err != nil {
+		return out, &ExecError{Err: err, Stderr: stderr.String()}
+	}
+	return out, nil
 }
\ No newline at end of file
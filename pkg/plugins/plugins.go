@@ -0,0 +1,100 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package plugins
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+const Prefix = "riff-"
+
+type Plugin struct {
+	Name string
+	Path string
+}
+
+/*
+ * Finds executables named riff-<name> in the directories of the given PATH list. When the same plugin
+ * appears more than once, the first directory wins, as it would for the shell.
+ */
+func Discover(pathList string) []Plugin {
+	found := map[string]Plugin{}
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		files, err := filepath.Glob(filepath.Join(dir, Prefix+"*"))
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if !isExecutable(file) {
+				continue
+			}
+			name := strings.TrimPrefix(filepath.Base(file), Prefix)
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if _, exists := found[name]; !exists && name != "" {
+				found[name] = Plugin{Name: name, Path: file}
+			}
+		}
+	}
+
+	plugins := make([]Plugin, 0, len(found))
+	for _, plugin := range found {
+		plugins = append(plugins, plugin)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+func Find(pathList string, name string) (Plugin, bool) {
+	for _, plugin := range Discover(pathList) {
+		if plugin.Name == name {
+			return plugin, true
+		}
+	}
+	return Plugin{}, false
+}
+
+/*
+ * Runs the plugin attached to the current process' standard streams.
+ */
+func (plugin Plugin) Exec(args []string) error {
+	cmd := exec.Command(plugin.Path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func isExecutable(path string) bool {
+	fi, err := os.Stat(path)
+	if err != nil || !fi.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return fi.Mode().Perm()&0111 != 0
+}
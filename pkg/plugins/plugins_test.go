@@ -0,0 +1,81 @@
+/*
+ * Copyright 2018 the original author or authors.
+ *
+ *   Licensed under the Apache License, Version 2.0 (the "License");
+ *   you may not use this file except in compliance with the License.
+ *   You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *   Unless required by applicable law or agreed to in writing, software
+ *   distributed under the License is distributed on an "AS IS" BASIS,
+ *   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *   See the License for the specific language governing permissions and
+ *   limitations under the License.
+ */
+
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakePath(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts require a POSIX shell")
+	}
+	dir, err := ioutil.TempDir("", "riff-plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\necho \"$@\" > \"$(dirname \"$0\")/foo.out\"\n"
+	ioutil.WriteFile(filepath.Join(dir, "riff-foo"), []byte(script), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "riff-notexecutable"), []byte(script), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "other-bar"), []byte(script), 0755)
+	return dir
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	as := assert.New(t)
+	dir := fakePath(t)
+	defer os.RemoveAll(dir)
+
+	plugins := Discover(dir)
+	if as.Len(plugins, 1) {
+		as.Equal("foo", plugins[0].Name)
+		as.Equal(filepath.Join(dir, "riff-foo"), plugins[0].Path)
+	}
+}
+
+func TestDiscoverPrefersFirstPathEntry(t *testing.T) {
+	as := assert.New(t)
+	first := fakePath(t)
+	defer os.RemoveAll(first)
+	second := fakePath(t)
+	defer os.RemoveAll(second)
+
+	plugin, found := Find(strings.Join([]string{first, second}, string(os.PathListSeparator)), "foo")
+	as.True(found)
+	as.Equal(filepath.Join(first, "riff-foo"), plugin.Path)
+}
+
+func TestExecPlugin(t *testing.T) {
+	as := assert.New(t)
+	dir := fakePath(t)
+	defer os.RemoveAll(dir)
+
+	plugin, found := Find(dir, "foo")
+	if as.True(found) {
+		as.NoError(plugin.Exec([]string{"hello", "world"}))
+		out, err := ioutil.ReadFile(filepath.Join(dir, "foo.out"))
+		as.NoError(err)
+		as.Equal("hello world\n", string(out))
+	}
+}
@@ -0,0 +1 @@
+module.exports = (x) => x ** 2